@@ -44,6 +44,8 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(utilsBootstrapCmd)
+	rootCmd.AddCommand(utilsWarmBlockCacheCmd)
+	rootCmd.AddCommand(utilsPruneBlockCacheCmd)
 }
 
 // handleSignals handles OS signals so we can ensure we close database
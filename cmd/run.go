@@ -16,17 +16,14 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"math/big"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/inphi/optimism-rosetta/configuration"
+	"github.com/inphi/optimism-rosetta/metrics"
 	"github.com/inphi/optimism-rosetta/optimism"
 	"github.com/inphi/optimism-rosetta/services"
 
@@ -61,6 +58,27 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: unable to load configuration", err)
 	}
 
+	// Start required services
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	go handleSignals([]context.CancelFunc{cancel})
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	if cfg.Mode == configuration.Online && !cfg.RemoteGeth {
+		g.Go(func() error {
+			return optimism.StartGeth(ctx, cfg.GethArguments, g)
+		})
+	}
+
+	// AUTO networks are only fully known once the node has been queried
+	if err := cfg.DiscoverNetwork(ctx); err != nil {
+		return fmt.Errorf("%w: unable to discover network", err)
+	}
+
+	// The native currency is shared by the operation parsers and must be set before they are used
+	optimism.Currency = cfg.Currency
+
 	// The asserter automatically rejects incorrectly formatted
 	// requests.
 	asserter, err := asserter.NewServer(
@@ -75,56 +93,58 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: could not initialize server asserter", err)
 	}
 
-	// Start required services
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	go handleSignals([]context.CancelFunc{cancel})
-
-	g, ctx := errgroup.WithContext(ctx)
-
 	var client *optimism.Client
 	if cfg.Mode == configuration.Online {
-		if !cfg.RemoteGeth {
-			g.Go(func() error {
-				return optimism.StartGeth(ctx, cfg.GethArguments, g)
-			})
-		}
-
-		opts := optimism.ClientOptions{
-			HTTPTimeout:               cfg.L2GethHTTPTimeout,
-			MaxTraceConcurrency:       cfg.MaxConcurrentTraces,
-			EnableTraceCache:          cfg.EnableTraceCache,
-			EnableGethTracer:          cfg.EnableGethTracer,
-			FilterTokens:              cfg.TokenFilter,
-			SupportedTokens:           getSupportedTokens(cfg.Network.Network),
-			SuportsSyncing:            cfg.SupportsSyncing,
-			SkipAdminCalls:            false,
-			SupportsPeering:           false,
-			EnableCustomBedrockTracer: cfg.EnableCustomBedrockTracer,
-			BedrockBlock:              getBedrockBlock(cfg.Network.Network),
-			TraceCacheSize:            cfg.TraceCacheSize,
-			TraceByBlock:              cfg.TraceByBlock,
-		}
 		var err error
-		client, err = optimism.NewClient(cfg.GethURL, cfg.Params, opts)
+		client, err = optimism.NewClient(cfg.GethURL, cfg.Params, clientOptions(cfg))
 		if err != nil {
 			return fmt.Errorf("%w: cannot initialize ethereum client", err)
 		}
 		defer client.Close()
 	}
 
-	router := services.NewBlockchainRouter(cfg, client, asserter)
+	controllers := services.NewBlockchainControllers(cfg, client, asserter)
+	router := server.NewRouter(controllers...)
 
 	loggedRouter := server.LoggerMiddleware(router)
 	corsRouter := server.CorsMiddleware(loggedRouter)
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      corsRouter,
+		Handler:      metrics.Middleware(corsRouter, controllers...),
 		ReadTimeout:  readTimeout,
 		WriteTimeout: cfg.L2GethHTTPTimeout,
 		IdleTimeout:  idleTimeout,
 	}
 
+	if cfg.MetricsPort > 0 {
+		if client != nil {
+			if err := metrics.RegisterHeadLag(client.HeadLag); err != nil {
+				return fmt.Errorf("%w: unable to register head lag metric", err)
+			}
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsServer := &http.Server{
+			Addr:         fmt.Sprintf(":%d", cfg.MetricsPort),
+			Handler:      mux,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: readTimeout,
+			IdleTimeout:  idleTimeout,
+		}
+
+		g.Go(func() error {
+			log.Printf("metrics listening on port %d", cfg.MetricsPort)
+			return metricsServer.ListenAndServe()
+		})
+
+		g.Go(func() error {
+			<-ctx.Done()
+
+			return metricsServer.Shutdown(ctx)
+		})
+	}
+
 	g.Go(func() error {
 		log.Printf("server listening on port %d", cfg.Port)
 		return server.ListenAndServe()
@@ -147,40 +167,45 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 	return err
 }
 
-func getSupportedTokens(network string) map[string]bool {
-	content, err := os.ReadFile("tokenList.json")
-	if err != nil {
-		log.Fatal("Error when opening file: ", err)
-	}
-
-	var payload map[string]map[string]bool
-	err = json.Unmarshal(content, &payload)
-	if err != nil {
-		log.Fatal("Error during Unmarshal(): ", err)
-	}
-
-	if val, ok := payload[network]; ok {
-		lowerCased := make(map[string]bool, len(val))
-		for k, v := range val {
-			lowerCased[strings.ToLower(k)] = v
-		}
-		return lowerCased
-	}
-
-	return map[string]bool{
-		"0x4200000000000000000000000000000000000042": true, // OP
+// clientOptions returns the options of the client of an online configuration
+func clientOptions(cfg *configuration.Configuration) optimism.ClientOptions {
+	var genesisBlockHash string
+	if cfg.GenesisBlockIdentifier != nil {
+		genesisBlockHash = cfg.GenesisBlockIdentifier.Hash
 	}
-}
-
-func getBedrockBlock(network string) *big.Int {
-	switch network {
-	case optimism.TestnetNetwork:
-		return big.NewInt(4061224)
-	case optimism.MainnetNetwork:
-		return big.NewInt(105235063)
-	case optimism.SepoliaNetwork:
-		return big.NewInt(0)
-	default:
-		return big.NewInt(0)
+	return optimism.ClientOptions{
+		HTTPTimeout:                cfg.L2GethHTTPTimeout,
+		MaxTraceConcurrency:        cfg.MaxConcurrentTraces,
+		EnableTraceCache:           cfg.EnableTraceCache,
+		EnableGethTracer:           cfg.EnableGethTracer,
+		FilterTokens:               cfg.TokenFilter,
+		SupportedTokens:            cfg.SupportedTokens,
+		SuportsSyncing:             cfg.SupportsSyncing,
+		SkipAdminCalls:             false,
+		SupportsPeering:            false,
+		EnableCustomBedrockTracer:  cfg.EnableCustomBedrockTracer,
+		ChainID:                    cfg.Params.ChainID,
+		GenesisBlockHash:           genesisBlockHash,
+		BedrockBlock:               cfg.BedrockBlock,
+		TraceCacheSize:             cfg.TraceCacheSize,
+		TraceCacheBackend:          cfg.TraceCacheBackend,
+		TraceCachePath:             cfg.TraceCachePath,
+		TraceCacheRedisURL:         cfg.TraceCacheRedisURL,
+		TraceCacheTTL:              cfg.TraceCacheTTL,
+		TraceByBlock:               cfg.TraceByBlock,
+		OtherTransactionsThreshold: cfg.OtherTransactionsThreshold,
+		GasPriceOracleOwner:        cfg.GasPriceOracleOwner,
+		EnableMintOps:              cfg.EnableMintOps,
+		EnableNFTOps:               cfg.EnableNFTOps,
+		NFTContracts:               cfg.NFTContracts,
+		EnableBridgeOps:            cfg.EnableBridgeOps,
+		SubmitURLs:                 cfg.SubmitURLs,
+		SubmitFanout:               cfg.SubmitFanout,
+		BalanceBatchSize:           cfg.BalanceBatchSize,
+		Multicall3Address:          cfg.Multicall3Address,
+		TokenDiscovery:             cfg.TokenDiscovery,
+		TokenDiscoveryBlockRange:   cfg.TokenDiscoveryBlockRange,
+		BlockCachePath:             cfg.BlockCachePath,
+		BlockCacheSize:             cfg.BlockCacheSize,
 	}
 }
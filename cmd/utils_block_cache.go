@@ -0,0 +1,158 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsWarmBlockCacheCmd = &cobra.Command{
+		Use:   "utils:warm-block-cache",
+		Short: "Fetch a range of blocks into the block cache",
+		Long: `Fetches every block of a range from the node, caching the
+finalized ones in the block cache configured by BLOCK_CACHE_DIR.
+Blocks above the finalized head are fetched but not cached.
+
+The block cache cannot be shared with a running server, so this
+command must be run while the server is stopped.
+
+When calling this command, you must provide 2 arguments:
+[1] the index of the first block of the range
+[2] the index of the last block of the range`,
+		RunE: runUtilsWarmBlockCacheCmd,
+		Args: cobra.ExactArgs(2), //nolint:gomnd
+	}
+
+	utilsPruneBlockCacheCmd = &cobra.Command{
+		Use:   "utils:prune-block-cache",
+		Short: "Remove a range of blocks from the block cache",
+		Long: `Removes every cached block of a range from the block cache
+configured by BLOCK_CACHE_DIR.
+
+The block cache cannot be shared with a running server, so this
+command must be run while the server is stopped.
+
+When calling this command, you must provide 2 arguments:
+[1] the index of the first block of the range
+[2] the index of the last block of the range`,
+		RunE: runUtilsPruneBlockCacheCmd,
+		Args: cobra.ExactArgs(2), //nolint:gomnd
+	}
+)
+
+func runUtilsWarmBlockCacheCmd(cmd *cobra.Command, args []string) error {
+	start, end, err := parseBlockRange(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handleSignals([]context.CancelFunc{cancel})
+
+	cfg, err := loadBlockCacheConfiguration()
+	if err != nil {
+		return err
+	}
+	if err := cfg.DiscoverNetwork(ctx); err != nil {
+		return fmt.Errorf("%w: unable to discover network", err)
+	}
+	optimism.Currency = cfg.Currency
+
+	client, err := optimism.NewClient(cfg.GethURL, cfg.Params, clientOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize ethereum client", err)
+	}
+	defer client.Close()
+
+	for index := start; index <= end; index++ {
+		index := index
+		if _, err := client.Block(ctx, &types.PartialBlockIdentifier{Index: &index}); err != nil {
+			return fmt.Errorf("%w: unable to fetch block %d", err, index)
+		}
+	}
+	log.Printf("warmed block cache from block %d to %d", start, end)
+	return nil
+}
+
+func runUtilsPruneBlockCacheCmd(cmd *cobra.Command, args []string) error {
+	start, end, err := parseBlockRange(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadBlockCacheConfiguration()
+	if err != nil {
+		return err
+	}
+	// The network is part of the version of the cache, which is dropped if it does not match
+	if err := cfg.DiscoverNetwork(context.Background()); err != nil {
+		return fmt.Errorf("%w: unable to discover network", err)
+	}
+	client, err := optimism.NewClient(cfg.GethURL, cfg.Params, clientOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize ethereum client", err)
+	}
+	defer client.Close()
+
+	freed, err := client.PruneBlockCache(start, end)
+	if err != nil {
+		return fmt.Errorf("%w: unable to prune block cache", err)
+	}
+	log.Printf("pruned %d bytes of blocks from %d to %d from the block cache", freed, start, end)
+	return nil
+}
+
+// loadBlockCacheConfiguration loads the configuration of an online server with a block cache
+func loadBlockCacheConfiguration() (*configuration.Configuration, error) {
+	cfg, err := configuration.LoadConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to load configuration", err)
+	}
+	if cfg.Mode != configuration.Online {
+		return nil, errors.New("the block cache is only available in ONLINE mode")
+	}
+	if len(cfg.BlockCachePath) == 0 {
+		return nil, fmt.Errorf("%s must be set", configuration.BlockCacheDirEnv)
+	}
+	return cfg, nil
+}
+
+// parseBlockRange parses the first and last block of an inclusive range of blocks
+func parseBlockRange(args []string) (int64, int64, error) {
+	start, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("%s is not a valid block index", args[0])
+	}
+	end, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || end < 0 {
+		return 0, 0, fmt.Errorf("%s is not a valid block index", args[1])
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("block range %d to %d is empty", start, end)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ABIRegistry holds the contract ABIs used to encode and decode the calldata of contract calls.
+// A nil registry knows no methods.
+type ABIRegistry struct {
+	contracts map[common.Address]*abi.ABI
+	selectors map[[4]byte]*abi.Method
+}
+
+// LoadABIRegistry loads the JSON ABI files of a directory. A file named after a contract
+// address (0x<address>.json) holds the ABI of that contract. The methods of any other file
+// are used for every contract, keyed by their selector.
+func LoadABIRegistry(dir string) (*ABIRegistry, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &ABIRegistry{
+		contracts: make(map[common.Address]*abi.ABI),
+		selectors: make(map[[4]byte]*abi.Method),
+	}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := abi.JSON(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse ABI %s", err, path)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if common.IsHexAddress(name) {
+			registry.contracts[common.HexToAddress(name)] = &parsed
+			continue
+		}
+		for _, method := range parsed.Methods {
+			method := method
+			var selector [4]byte
+			copy(selector[:], method.ID)
+			if existing, ok := registry.selectors[selector]; ok && existing.Sig != method.Sig {
+				return nil, fmt.Errorf("selector of %s in %s collides with %s", method.Sig, path, existing.Sig)
+			}
+			registry.selectors[selector] = &method
+		}
+	}
+
+	return registry, nil
+}
+
+// Method returns the method of a contract with the given selector. The ABI registered
+// for the contract takes precedence over the methods registered by selector.
+func (r *ABIRegistry) Method(contract string, selector []byte) (*abi.Method, bool) {
+	if r == nil || len(selector) < 4 {
+		return nil, false
+	}
+
+	if contractABI, ok := r.contracts[common.HexToAddress(contract)]; ok {
+		if method, err := contractABI.MethodById(selector[:4]); err == nil {
+			return method, true
+		}
+	}
+
+	var key [4]byte
+	copy(key[:], selector[:4])
+	method, ok := r.selectors[key]
+	return method, ok
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	vaultABI = `[{"type":"function","name":"deposit","inputs":[{"name":"assets","type":"uint256"},{"name":"receiver","type":"address"}],"outputs":[]}]`
+	otherABI = `[{"type":"function","name":"deposit","inputs":[{"name":"amount","type":"uint256"},{"name":"to","type":"address"}],"outputs":[]},` +
+		`{"type":"function","name":"ping","inputs":[],"outputs":[]}]`
+	vault = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+)
+
+func writeABIs(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestLoadABIRegistry(t *testing.T) {
+	dir := writeABIs(t, map[string]string{
+		vault + ".json": vaultABI,
+		"common.json":   otherABI,
+		"README.md":     "not an ABI",
+	})
+
+	registry, err := LoadABIRegistry(dir)
+	assert.NoError(t, err)
+
+	deposit := crypto.Keccak256([]byte("deposit(uint256,address)"))[:4]
+	method, ok := registry.Method(vault, deposit)
+	assert.True(t, ok)
+	assert.Equal(t, "assets", method.Inputs[0].Name)
+
+	// Methods registered by selector are used for any contract
+	method, ok = registry.Method("0x4200000000000000000000000000000000000042", deposit)
+	assert.True(t, ok)
+	assert.Equal(t, "amount", method.Inputs[0].Name)
+
+	ping := crypto.Keccak256([]byte("ping()"))[:4]
+	method, ok = registry.Method(vault, ping)
+	assert.True(t, ok)
+	assert.Equal(t, "ping", method.Name)
+
+	_, ok = registry.Method(vault, crypto.Keccak256([]byte("withdraw(uint256)"))[:4])
+	assert.False(t, ok)
+
+	_, ok = (*ABIRegistry)(nil).Method(vault, deposit)
+	assert.False(t, ok)
+}
+
+func TestLoadABIRegistry_Errors(t *testing.T) {
+	_, err := LoadABIRegistry(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	_, err = LoadABIRegistry(writeABIs(t, map[string]string{"bad.json": "{"}))
+	assert.ErrorContains(t, err, "unable to parse ABI")
+
+	file := filepath.Join(writeABIs(t, map[string]string{"common.json": otherABI}), "common.json")
+	_, err = LoadABIRegistry(file)
+	assert.EqualError(t, err, file+" is not a directory")
+}
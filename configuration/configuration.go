@@ -17,15 +17,19 @@ package configuration
 import (
 	"errors"
 	"fmt"
+	"log"
 	"math/big"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/inphi/optimism-rosetta/optimism"
-
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/common"
 	"github.com/ethereum-optimism/optimism/l2geth/params"
+	"github.com/inphi/optimism-rosetta/optimism"
 )
 
 // Mode is the setting that determines if
@@ -53,18 +57,31 @@ const (
 	// Testnet defaults to `Ropsten` for backwards compatibility (even though we don't have a ropsten network on Optimism).
 	Testnet string = "TESTNET"
 
+	// Auto discovers the chain ID, genesis block and bedrock block from the connected node at startup.
+	// It is only supported in ONLINE mode.
+	Auto string = "AUTO"
+
 	// DataDirectory is the default location for all
 	// persistent data.
 	DataDirectory = "/data"
 
+	// defaultTraceCacheDir is the directory of the DISK trace cache
+	// when TraceCacheDirEnv is not populated.
+	defaultTraceCacheDir = "traces"
+
 	// ModeEnv is the environment variable read
 	// to determine mode.
 	ModeEnv = "MODE"
 
 	// NetworkEnv is the environment variable
 	// read to determine network.
+	// When a config file is provided, it names the preset the file's network is merged onto.
 	NetworkEnv = "NETWORK"
 
+	// NetworkConfigEnv is the path of an optional YAML or TOML config file
+	// describing the network and providing defaults for the other environment variables.
+	NetworkConfigEnv = "NETWORK_CONFIG"
+
 	// PortEnv is the environment variable
 	// read to determine the port for the Rosetta
 	// implementation.
@@ -80,6 +97,10 @@ const (
 	// when GethEnv is not populated.
 	DefaultGethURL = "http://localhost:8545"
 
+	// OpNodeEnv is an optional environment variable pointing to the op-node RPC.
+	// When the network is AUTO, its rollup config is used to discover the bedrock block.
+	OpNodeEnv = "OP_NODE"
+
 	// Tiemout of L2 Geth HTTP Client in seconds
 	L2GethHTTPTimeoutEnv = "L2_GETH_HTTP_TIMEOUT"
 
@@ -97,6 +118,28 @@ const (
 	// Configures the size of the trace cache
 	TraceCacheSizeEnv = "TRACE_CACHE_SIZE"
 
+	// TraceCacheBackendEnv is where the trace cache also keeps the traces it holds in memory.
+	// DISK keeps them across restarts in TRACE_CACHE_DIR, REDIS shares them between replicas
+	// through the server at TRACE_CACHE_REDIS_URL.
+	// DEFAULT: `MEMORY`
+	TraceCacheBackendEnv = "TRACE_CACHE_BACKEND"
+
+	// TraceCacheDirEnv is the directory, relative to the DataDirectory, of the DISK trace cache.
+	// DEFAULT: `traces`
+	TraceCacheDirEnv = "TRACE_CACHE_DIR"
+
+	// TraceCacheRedisURLEnv is the redis:// url of the server of the REDIS trace cache.
+	TraceCacheRedisURLEnv = "TRACE_CACHE_REDIS_URL"
+
+	// TraceCacheTTLEnv is the number of seconds after which the REDIS trace cache expires traces.
+	// DEFAULT: `0` (left to the eviction policy of the server)
+	TraceCacheTTLEnv = "TRACE_CACHE_TTL"
+
+	// MetricsPortEnv is the port of the Prometheus /metrics endpoint, which is
+	// served separately from the Rosetta API.
+	// DEFAULT: empty (no metrics endpoint)
+	MetricsPortEnv = "METRICS_PORT"
+
 	// Experimental: Use newly added built-in geth tracer
 	EnableGethTracer = "ENABLE_GETH_TRACER"
 
@@ -113,31 +156,162 @@ const (
 	// By default, optimism-rosetta batches calls to debug_traceTransaction to lighten the load on downstream geth clients
 	// DEFAULT: `false`
 	TraceByBlockEnv = "TRACE_BY_BLOCK"
+
+	// EnableMempoolEnv enables the /mempool and /mempool/transaction endpoints.
+	// The node must expose the txpool namespace or support pending transaction filters.
+	// DEFAULT: `false`
+	EnableMempoolEnv = "ENABLE_MEMPOOL"
+
+	// OtherTransactionsThresholdEnv is the number of transactions above which /block
+	// returns other_transactions identifiers instead of populated transactions.
+	// DEFAULT: `0` (disabled)
+	OtherTransactionsThresholdEnv = "OTHER_TRANSACTIONS_THRESHOLD"
+
+	// EnableMintOpsEnv emits MINT operations for the minted amount of deposit transactions,
+	// instead of the CALL crediting the deposit value that is emitted for backwards compatibility.
+	// DEFAULT: `false`
+	EnableMintOpsEnv = "ENABLE_MINT_OPS"
+
+	// EnableNFTOpsEnv emits ERC721_TRANSFER and ERC1155_TRANSFER operations.
+	// DEFAULT: `false`
+	EnableNFTOpsEnv = "ENABLE_NFT_OPS"
+
+	// NFTContractsEnv is a comma-separated list of the NFT contracts to emit operations for.
+	// DEFAULT: empty (all contracts)
+	NFTContractsEnv = "NFT_CONTRACTS"
+
+	// EnableBridgeOpsEnv emits ERC20_BRIDGE_MINT and ERC20_BRIDGE_BURN operations for the tokens minted
+	// and burned by the L2StandardBridge, instead of ERC20_MINT and ERC20_BURN.
+	// DEFAULT: `false`
+	EnableBridgeOpsEnv = "ENABLE_BRIDGE_OPS"
+
+	// SubmitTimeoutEnv is the maximum number of seconds /construction/submit waits for a
+	// transaction to be included when the caller asks to wait for inclusion.
+	// DEFAULT: `60`
+	SubmitTimeoutEnv = "SUBMIT_TIMEOUT"
+
+	// SubmitURLsEnv is a comma-separated list of the RPC endpoints that transactions are sent to,
+	// such as the sequencer, instead of GETH.
+	// DEFAULT: empty (GETH)
+	SubmitURLsEnv = "SUBMIT_URLS"
+
+	// SubmitFanoutEnv determines how transactions are sent to SUBMIT_URLS. FIRST_SUCCESS tries each
+	// endpoint in order until one accepts the transaction, ALL sends it to every endpoint at once.
+	// DEFAULT: `FIRST_SUCCESS`
+	SubmitFanoutEnv = "SUBMIT_FANOUT"
+
+	// ABIRegistryEnv is a directory of JSON contract ABIs used to encode the arguments
+	// of contract calls and to decode them in /construction/parse.
+	// DEFAULT: empty (no ABIs)
+	ABIRegistryEnv = "ABI_REGISTRY"
+
+	// BalanceBatchSizeEnv is the maximum number of calls sent in a single JSON-RPC batch
+	// when fetching the balances of an account.
+	// DEFAULT: `100`
+	BalanceBatchSizeEnv = "BALANCE_BATCH_SIZE"
+
+	// Multicall3AddressEnv is the address of a Multicall3 contract that aggregates the ERC20
+	// balanceOf calls of an account into one eth_call. Blocks that predate its deployment
+	// fall back to batched eth_calls.
+	// DEFAULT: empty (batched eth_calls)
+	Multicall3AddressEnv = "MULTICALL3_ADDRESS"
+
+	// TokenDiscoveryEnv returns the balances of discovered tokens from /account/balance when
+	// no currencies are requested. SUPPORTED_TOKENS returns every supported token that the account
+	// holds, TRANSFER_LOGS also returns the tokens found in its recent Transfer logs.
+	// DEFAULT: empty (ETH and the OP token)
+	TokenDiscoveryEnv = "TOKEN_DISCOVERY"
+
+	// TokenDiscoveryBlockRangeEnv is the number of blocks, ending at the requested block, whose
+	// Transfer logs are scanned by TRANSFER_LOGS token discovery.
+	// DEFAULT: `10000`
+	TokenDiscoveryBlockRangeEnv = "TOKEN_DISCOVERY_BLOCK_RANGE"
+
+	// BlockCacheDirEnv is the directory, relative to the DataDirectory, of an on-disk cache
+	// of finalized blocks served by /block.
+	// DEFAULT: empty (no block cache)
+	BlockCacheDirEnv = "BLOCK_CACHE_DIR"
+
+	// BlockCacheSizeMBEnv is the size of the block cache in megabytes above which the
+	// lowest cached blocks are evicted.
+	// DEFAULT: `1024`
+	BlockCacheSizeMBEnv = "BLOCK_CACHE_SIZE_MB"
 )
 
 // Configuration determines how
 type Configuration struct {
-	Mode                      Mode
-	Network                   *types.NetworkIdentifier
-	GenesisBlockIdentifier    *types.BlockIdentifier
-	GethURL                   string
-	RemoteGeth                bool
-	Port                      int
-	GethArguments             string
-	L2GethHTTPTimeout         time.Duration
-	MaxConcurrentTraces       int64
-	EnableTraceCache          bool
-	TraceCacheSize            int
-	EnableGethTracer          bool
-	TokenFilter               bool
-	SupportsSyncing           bool
-	EnableCustomBedrockTracer bool
-	TraceByBlock              bool
+	Mode                       Mode
+	Network                    *types.NetworkIdentifier
+	GenesisBlockIdentifier     *types.BlockIdentifier
+	GethURL                    string
+	OpNodeURL                  string
+	RemoteGeth                 bool
+	Port                       int
+	GethArguments              string
+	L2GethHTTPTimeout          time.Duration
+	MaxConcurrentTraces        int64
+	EnableTraceCache           bool
+	TraceCacheSize             int
+	TraceCacheBackend          string
+	TraceCachePath             string
+	TraceCacheRedisURL         string
+	TraceCacheTTL              time.Duration
+	MetricsPort                int
+	EnableGethTracer           bool
+	TokenFilter                bool
+	SupportsSyncing            bool
+	EnableCustomBedrockTracer  bool
+	TraceByBlock               bool
+	EnableMempool              bool
+	OtherTransactionsThreshold int
+	EnableMintOps              bool
+	EnableNFTOps               bool
+	NFTContracts               map[string]bool
+	EnableBridgeOps            bool
+	SubmitTimeout              time.Duration
+	SubmitURLs                 []string
+	SubmitFanout               string
+	ABIRegistry                *ABIRegistry
+	BalanceBatchSize           int
+	Multicall3Address          string
+	TokenDiscovery             string
+	TokenDiscoveryBlockRange   uint64
+	BlockCachePath             string
+	BlockCacheSize             int64
+
+	// Network Data
+	// AutoDiscover is set for AUTO networks. Fields that were not explicitly configured
+	// are left unset until [Configuration.DiscoverNetwork] is called.
+	AutoDiscover        bool
+	BedrockBlock        *big.Int
+	Currency            *types.Currency
+	SupportedTokens     map[string]bool
+	GasPriceOracleOwner string
 
 	// Block Reward Data
 	Params *params.ChainConfig
 }
 
+// legacyTokenListPath is the token list read by releases that predate NetworkConfigEnv.
+// Its tokens now belong in the tokens list of the network config.
+var legacyTokenListPath = "tokenList.json"
+
+// checkLegacyTokenList refuses to start with a token list that is no longer read, unless a
+// network config file is set, in which case the token list is assumed to be migrated.
+func checkLegacyTokenList(networkConfigPath string) error {
+	if _, err := os.Stat(legacyTokenListPath); err != nil {
+		return nil
+	}
+	if len(networkConfigPath) == 0 {
+		return fmt.Errorf(
+			"%s is no longer read: move its tokens to the tokens list of a %s file",
+			legacyTokenListPath, NetworkConfigEnv,
+		)
+	}
+	log.Printf("ignoring %s, the supported tokens are read from %s", legacyTokenListPath, networkConfigPath)
+	return nil
+}
+
 // LoadConfiguration attempts to create a new Configuration
 // using the ENVs in the environment.
 //
@@ -145,7 +319,28 @@ type Configuration struct {
 func LoadConfiguration() (*Configuration, error) {
 	config := &Configuration{}
 
-	modeValue := Mode(os.Getenv(ModeEnv))
+	// Values from the config file are only used for environment variables that are not set
+	var fileNetwork *NetworkConfig
+	var fileSettings map[string]string
+	if err := checkLegacyTokenList(os.Getenv(NetworkConfigEnv)); err != nil {
+		return nil, err
+	}
+	if path := os.Getenv(NetworkConfigEnv); len(path) > 0 {
+		fileConfig, err := LoadFileConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		fileNetwork = fileConfig.Network
+		fileSettings = fileConfig.Settings
+	}
+	getenv := func(key string) string {
+		if val := os.Getenv(key); len(val) > 0 {
+			return val
+		}
+		return fileSettings[key]
+	}
+
+	modeValue := Mode(getenv(ModeEnv))
 	switch modeValue {
 	case Online:
 		config.Mode = Online
@@ -157,58 +352,27 @@ func LoadConfiguration() (*Configuration, error) {
 		return nil, fmt.Errorf("%s is not a valid mode", modeValue)
 	}
 
-	networkValue := os.Getenv(NetworkEnv)
-	switch networkValue {
-	case Mainnet:
-		config.Network = &types.NetworkIdentifier{
-			Blockchain: optimism.Blockchain,
-			Network:    optimism.MainnetNetwork,
-		}
-		config.GenesisBlockIdentifier = optimism.MainnetGenesisBlockIdentifier
-		config.Params = params.MainnetChainConfig
-		config.Params.ChainID = big.NewInt(10) // TODO: temporary fix without param update
-		config.GethArguments = optimism.MainnetGethArguments
-	case Testnet: // goerli
-		config.Network = &types.NetworkIdentifier{
-			Blockchain: optimism.Blockchain,
-			Network:    optimism.TestnetNetwork,
-		}
-		config.GenesisBlockIdentifier = optimism.TestnetGenesisBlockIdentifier
-		config.Params = params.TestnetChainConfig
-		config.Params.ChainID = big.NewInt(420) // TODO: temporary fix without param update
-		config.GethArguments = optimism.TestnetGethArguments
-	case Goerli:
-		config.Network = &types.NetworkIdentifier{
-			Blockchain: optimism.Blockchain,
-			Network:    optimism.GoerliNetwork,
-		}
-		config.GenesisBlockIdentifier = optimism.GoerliGenesisBlockIdentifier
-		config.Params = params.GoerliChainConfig
-		config.GethArguments = optimism.GoerliGethArguments
-	case Sepolia:
-		// TODO: Testnet should eventually be using Sepolia configs
-		config.Network = &types.NetworkIdentifier{
-			Blockchain: optimism.Blockchain,
-			Network:    optimism.SepoliaNetwork,
-		}
-		config.GenesisBlockIdentifier = optimism.SepoliaGenesisBlockIdentifier
-		config.Params = params.TestnetChainConfig
-		config.Params.ChainID = big.NewInt(11155420) // TODO: temporary fix without param update
-		config.GethArguments = optimism.TestnetGethArguments
-	case "":
-		return nil, errors.New("NETWORK must be populated")
-	default:
-		return nil, fmt.Errorf("%s is not a valid network", networkValue)
+	network, err := resolveNetworkConfig(getenv(NetworkEnv), fileNetwork)
+	if err != nil {
+		return nil, err
+	}
+	if err := network.apply(config); err != nil {
+		return nil, err
+	}
+	if config.AutoDiscover && config.Mode != Online {
+		return nil, fmt.Errorf("%s network is only supported in %s mode", Auto, Online)
 	}
 
 	config.GethURL = DefaultGethURL
-	envGethURL := os.Getenv(GethEnv)
+	envGethURL := getenv(GethEnv)
 	if len(envGethURL) > 0 {
 		config.RemoteGeth = true
 		config.GethURL = envGethURL
 	}
 
-	envL2GethHTTPTimeout := os.Getenv(L2GethHTTPTimeoutEnv)
+	config.OpNodeURL = getenv(OpNodeEnv)
+
+	envL2GethHTTPTimeout := getenv(L2GethHTTPTimeoutEnv)
 	if len(envL2GethHTTPTimeout) > 0 {
 		val, err := strconv.Atoi(envL2GethHTTPTimeout)
 		if err != nil {
@@ -217,7 +381,7 @@ func LoadConfiguration() (*Configuration, error) {
 		config.L2GethHTTPTimeout = time.Second * time.Duration(val)
 	}
 
-	envMaxConcurrentTraces := os.Getenv(MaxConcurrentTracesEnv)
+	envMaxConcurrentTraces := getenv(MaxConcurrentTracesEnv)
 	if len(envMaxConcurrentTraces) > 0 {
 		val, err := strconv.Atoi(envMaxConcurrentTraces)
 		if err != nil {
@@ -226,7 +390,7 @@ func LoadConfiguration() (*Configuration, error) {
 		config.MaxConcurrentTraces = int64(val)
 	}
 
-	portValue := os.Getenv(PortEnv)
+	portValue := getenv(PortEnv)
 	if len(portValue) == 0 {
 		return nil, errors.New("PORT must be populated")
 	}
@@ -237,7 +401,7 @@ func LoadConfiguration() (*Configuration, error) {
 	}
 	config.Port = port
 
-	envEnableTraceCache := os.Getenv(EnableTraceCacheEnv)
+	envEnableTraceCache := getenv(EnableTraceCacheEnv)
 	if len(envEnableTraceCache) > 0 {
 		val, err := strconv.ParseBool(envEnableTraceCache)
 		if err != nil {
@@ -246,7 +410,7 @@ func LoadConfiguration() (*Configuration, error) {
 		config.EnableTraceCache = val
 	}
 
-	envTraceCacheSize := os.Getenv(TraceCacheSizeEnv)
+	envTraceCacheSize := getenv(TraceCacheSizeEnv)
 	if len(envTraceCacheSize) > 0 {
 		val, err := strconv.Atoi(envTraceCacheSize)
 		if err != nil {
@@ -255,7 +419,7 @@ func LoadConfiguration() (*Configuration, error) {
 		config.TraceCacheSize = val
 	}
 
-	envEnableGethTracer := os.Getenv(EnableGethTracer)
+	envEnableGethTracer := getenv(EnableGethTracer)
 	if len(envEnableGethTracer) > 0 {
 		val, err := strconv.ParseBool(envEnableGethTracer)
 		if err != nil {
@@ -266,7 +430,7 @@ func LoadConfiguration() (*Configuration, error) {
 
 	// Custom bedrock tracing is disabled by default.
 	// Since op-geth does not have builtin tracing like l2geth, we use the `callTracer` by default.
-	envCustomBedrockTracer := os.Getenv(EnableCustomBedrockTracerEnv)
+	envCustomBedrockTracer := getenv(EnableCustomBedrockTracerEnv)
 	if len(envCustomBedrockTracer) > 0 {
 		val, err := strconv.ParseBool(envCustomBedrockTracer)
 		if err != nil {
@@ -276,7 +440,7 @@ func LoadConfiguration() (*Configuration, error) {
 	}
 
 	config.TokenFilter = true
-	envTokenFilter := os.Getenv(TokenFilterEnv)
+	envTokenFilter := getenv(TokenFilterEnv)
 	if len(envTokenFilter) > 0 {
 		val, err := strconv.ParseBool(envTokenFilter)
 		if err != nil {
@@ -286,7 +450,7 @@ func LoadConfiguration() (*Configuration, error) {
 	}
 
 	config.TraceByBlock = false
-	envTraceByBlock := os.Getenv(TraceByBlockEnv)
+	envTraceByBlock := getenv(TraceByBlockEnv)
 	if len(envTraceByBlock) > 0 {
 		val, err := strconv.ParseBool(envTraceByBlock)
 		if err != nil {
@@ -295,5 +459,235 @@ func LoadConfiguration() (*Configuration, error) {
 		config.TraceByBlock = val
 	}
 
+	envEnableMempool := getenv(EnableMempoolEnv)
+	if len(envEnableMempool) > 0 {
+		val, err := strconv.ParseBool(envEnableMempool)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, EnableMempoolEnv, envEnableMempool)
+		}
+		config.EnableMempool = val
+	}
+
+	envOtherTransactionsThreshold := getenv(OtherTransactionsThresholdEnv)
+	if len(envOtherTransactionsThreshold) > 0 {
+		val, err := strconv.Atoi(envOtherTransactionsThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, OtherTransactionsThresholdEnv, envOtherTransactionsThreshold)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative", OtherTransactionsThresholdEnv)
+		}
+		config.OtherTransactionsThreshold = val
+	}
+
+	envEnableMintOps := getenv(EnableMintOpsEnv)
+	if len(envEnableMintOps) > 0 {
+		val, err := strconv.ParseBool(envEnableMintOps)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, EnableMintOpsEnv, envEnableMintOps)
+		}
+		config.EnableMintOps = val
+	}
+
+	envEnableNFTOps := getenv(EnableNFTOpsEnv)
+	if len(envEnableNFTOps) > 0 {
+		val, err := strconv.ParseBool(envEnableNFTOps)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, EnableNFTOpsEnv, envEnableNFTOps)
+		}
+		config.EnableNFTOps = val
+	}
+
+	envNFTContracts := getenv(NFTContractsEnv)
+	if len(envNFTContracts) > 0 {
+		config.NFTContracts = make(map[string]bool)
+		for _, contract := range strings.Split(envNFTContracts, ",") {
+			contract = strings.TrimSpace(contract)
+			if !common.IsHexAddress(contract) {
+				return nil, fmt.Errorf("%s is not a valid address in %s", contract, NFTContractsEnv)
+			}
+			config.NFTContracts[strings.ToLower(contract)] = true
+		}
+	}
+
+	envEnableBridgeOps := getenv(EnableBridgeOpsEnv)
+	if len(envEnableBridgeOps) > 0 {
+		val, err := strconv.ParseBool(envEnableBridgeOps)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, EnableBridgeOpsEnv, envEnableBridgeOps)
+		}
+		config.EnableBridgeOps = val
+	}
+
+	envSubmitTimeout := getenv(SubmitTimeoutEnv)
+	if len(envSubmitTimeout) > 0 {
+		val, err := strconv.Atoi(envSubmitTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, SubmitTimeoutEnv, envSubmitTimeout)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("%s must be positive", SubmitTimeoutEnv)
+		}
+		config.SubmitTimeout = time.Second * time.Duration(val)
+	}
+
+	envSubmitURLs := getenv(SubmitURLsEnv)
+	if len(envSubmitURLs) > 0 {
+		for _, submitURL := range strings.Split(envSubmitURLs, ",") {
+			submitURL = strings.TrimSpace(submitURL)
+			if u, err := url.Parse(submitURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return nil, fmt.Errorf("%s is not a valid http endpoint in %s", submitURL, SubmitURLsEnv)
+			}
+			config.SubmitURLs = append(config.SubmitURLs, submitURL)
+		}
+	}
+
+	if envABIRegistry := getenv(ABIRegistryEnv); len(envABIRegistry) > 0 {
+		registry, err := LoadABIRegistry(envABIRegistry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to load %s %s", err, ABIRegistryEnv, envABIRegistry)
+		}
+		config.ABIRegistry = registry
+	}
+
+	envBalanceBatchSize := getenv(BalanceBatchSizeEnv)
+	if len(envBalanceBatchSize) > 0 {
+		val, err := strconv.Atoi(envBalanceBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, BalanceBatchSizeEnv, envBalanceBatchSize)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("%s must be positive", BalanceBatchSizeEnv)
+		}
+		config.BalanceBatchSize = val
+	}
+
+	if envMulticall3Address := getenv(Multicall3AddressEnv); len(envMulticall3Address) > 0 {
+		if !common.IsHexAddress(envMulticall3Address) {
+			return nil, fmt.Errorf("%s is not a valid address in %s", envMulticall3Address, Multicall3AddressEnv)
+		}
+		config.Multicall3Address = envMulticall3Address
+	}
+
+	switch envTokenDiscovery := getenv(TokenDiscoveryEnv); envTokenDiscovery {
+	case "":
+	case optimism.SupportedTokensDiscovery, optimism.TransferLogsDiscovery:
+		config.TokenDiscovery = envTokenDiscovery
+	default:
+		return nil, fmt.Errorf("%s is not a valid %s", envTokenDiscovery, TokenDiscoveryEnv)
+	}
+
+	envTokenDiscoveryBlockRange := getenv(TokenDiscoveryBlockRangeEnv)
+	if len(envTokenDiscoveryBlockRange) > 0 {
+		val, err := strconv.ParseUint(envTokenDiscoveryBlockRange, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, TokenDiscoveryBlockRangeEnv, envTokenDiscoveryBlockRange)
+		}
+		if val == 0 {
+			return nil, fmt.Errorf("%s must be positive", TokenDiscoveryBlockRangeEnv)
+		}
+		config.TokenDiscoveryBlockRange = val
+	}
+
+	envBlockCacheDir := getenv(BlockCacheDirEnv)
+	if len(envBlockCacheDir) > 0 {
+		path, err := dataDirectoryPath(BlockCacheDirEnv, envBlockCacheDir)
+		if err != nil {
+			return nil, err
+		}
+		config.BlockCachePath = path
+	}
+
+	envBlockCacheSizeMB := getenv(BlockCacheSizeMBEnv)
+	if len(envBlockCacheSizeMB) > 0 {
+		val, err := strconv.ParseInt(envBlockCacheSizeMB, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, BlockCacheSizeMBEnv, envBlockCacheSizeMB)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("%s must be positive", BlockCacheSizeMBEnv)
+		}
+		config.BlockCacheSize = val << 20
+	}
+
+	switch envTraceCacheBackend := getenv(TraceCacheBackendEnv); envTraceCacheBackend {
+	case "":
+	case optimism.MemoryTraceCacheBackend, optimism.DiskTraceCacheBackend, optimism.RedisTraceCacheBackend:
+		if !config.EnableTraceCache {
+			return nil, fmt.Errorf("%s requires %s", TraceCacheBackendEnv, EnableTraceCacheEnv)
+		}
+		config.TraceCacheBackend = envTraceCacheBackend
+	default:
+		return nil, fmt.Errorf("%s is not a valid %s", envTraceCacheBackend, TraceCacheBackendEnv)
+	}
+
+	if config.TraceCacheBackend == optimism.DiskTraceCacheBackend {
+		envTraceCacheDir := getenv(TraceCacheDirEnv)
+		if len(envTraceCacheDir) == 0 {
+			envTraceCacheDir = defaultTraceCacheDir
+		}
+		path, err := dataDirectoryPath(TraceCacheDirEnv, envTraceCacheDir)
+		if err != nil {
+			return nil, err
+		}
+		config.TraceCachePath = path
+	}
+
+	if config.TraceCacheBackend == optimism.RedisTraceCacheBackend {
+		envTraceCacheRedisURL := getenv(TraceCacheRedisURLEnv)
+		if u, err := url.Parse(envTraceCacheRedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			return nil, fmt.Errorf("%s must be a redis:// or rediss:// url", TraceCacheRedisURLEnv)
+		}
+		config.TraceCacheRedisURL = envTraceCacheRedisURL
+	}
+
+	envTraceCacheTTL := getenv(TraceCacheTTLEnv)
+	if len(envTraceCacheTTL) > 0 {
+		val, err := strconv.Atoi(envTraceCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, TraceCacheTTLEnv, envTraceCacheTTL)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("%s must not be negative", TraceCacheTTLEnv)
+		}
+		config.TraceCacheTTL = time.Second * time.Duration(val)
+	}
+
+	envMetricsPort := getenv(MetricsPortEnv)
+	if len(envMetricsPort) > 0 {
+		val, err := strconv.Atoi(envMetricsPort)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s %s", err, MetricsPortEnv, envMetricsPort)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("%s must be positive", MetricsPortEnv)
+		}
+		if val == config.Port {
+			return nil, fmt.Errorf("%s must differ from %s", MetricsPortEnv, PortEnv)
+		}
+		config.MetricsPort = val
+	}
+
+	switch envSubmitFanout := getenv(SubmitFanoutEnv); envSubmitFanout {
+	case "":
+	case optimism.FirstSuccessFanout, optimism.AllFanout:
+		config.SubmitFanout = envSubmitFanout
+	default:
+		return nil, fmt.Errorf("%s is not a valid %s", envSubmitFanout, SubmitFanoutEnv)
+	}
+
 	return config, nil
 }
+
+// dataDirectoryPath returns the path of dir, which is relative to the DataDirectory and
+// must not escape it
+func dataDirectoryPath(env string, dir string) (string, error) {
+	if filepath.IsAbs(dir) {
+		return "", fmt.Errorf("%s must be relative to %s", env, DataDirectory)
+	}
+	path := filepath.Join(DataDirectory, dir)
+	if path == DataDirectory || !strings.HasPrefix(path, DataDirectory+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s %s must be a directory inside %s", env, dir, DataDirectory)
+	}
+	return path, nil
+}
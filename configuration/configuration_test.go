@@ -16,7 +16,9 @@ package configuration
 
 import (
 	"errors"
+	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -27,6 +29,40 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// withChainID returns a copy of the chain config with the given chain ID.
+func withChainID(base *params.ChainConfig, chainID int64) *params.ChainConfig {
+	cfg := *base
+	cfg.ChainID = big.NewInt(chainID)
+	return &cfg
+}
+
+// withoutChainID returns a copy of the chain config without a chain ID.
+func withoutChainID(base *params.ChainConfig) *params.ChainConfig {
+	cfg := *base
+	cfg.ChainID = nil
+	return &cfg
+}
+
+var (
+	mainnetTokens = map[string]bool{
+		"0x4200000000000000000000000000000000000042": true,
+		"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": true,
+		"0x94b008aa00579c1307b0ef2c499ad98a8ce58e58": true,
+		"0x68f180fcce6836688e9084f035309e29bf0a2095": true,
+		"0x7f5c764cbc14f9669b88837ca1490cca17c31607": true,
+	}
+	testnetTokens = map[string]bool{
+		"0x4200000000000000000000000000000000000042": true,
+		"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": true,
+		"0x853eb4ba5d0ba2b77a0a5329fd2110d5ce149ece": true,
+		"0xe0a592353e81a94db6e3226fd4a99f881751776a": true,
+		"0x7e07e15d2a87a24492740d16f5bdf58c16db0c4e": true,
+	}
+	opTokenOnly = map[string]bool{
+		"0x4200000000000000000000000000000000000042": true,
+	}
+)
+
 func TestLoadConfiguration(t *testing.T) {
 	tests := map[string]struct {
 		Mode              string
@@ -35,6 +71,26 @@ func TestLoadConfiguration(t *testing.T) {
 		Geth              string
 		L2GethHTTPTimeout string
 		TokenFilter       string
+		EnableMempool     string
+		EnableMintOps     string
+		EnableNFTOps      string
+		NFTContracts      string
+		EnableBridgeOps   string
+		SubmitTimeout     string
+		SubmitURLs        string
+		SubmitFanout      string
+		BalanceBatchSize  string
+		Multicall3Address string
+		TokenDiscovery    string
+		DiscoveryRange    string
+		BlockCacheDir     string
+		BlockCacheSizeMB  string
+		TraceCache        string
+		TraceBackend      string
+		TraceCacheDir     string
+		TraceRedisURL     string
+		TraceCacheTTL     string
+		MetricsPort       string
 		// TraceByBlock      bool
 
 		cfg *Configuration
@@ -69,7 +125,11 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    optimism.MainnetNetwork,
 					Blockchain: optimism.Blockchain,
 				},
-				Params:                 params.MainnetChainConfig,
+				Params:                 withChainID(params.MainnetChainConfig, 10),
+				BedrockBlock:           big.NewInt(105235063),
+				Currency:               optimism.Currency,
+				SupportedTokens:        mainnetTokens,
+				GasPriceOracleOwner:    "0x7107142636C85c549690b1Aca12Bdb8052d26Ae6",
 				GenesisBlockIdentifier: optimism.MainnetGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
@@ -91,7 +151,11 @@ func TestLoadConfiguration(t *testing.T) {
 					Network:    optimism.MainnetNetwork,
 					Blockchain: optimism.Blockchain,
 				},
-				Params:                 params.MainnetChainConfig,
+				Params:                 withChainID(params.MainnetChainConfig, 10),
+				BedrockBlock:           big.NewInt(105235063),
+				Currency:               optimism.Currency,
+				SupportedTokens:        mainnetTokens,
+				GasPriceOracleOwner:    "0x7107142636C85c549690b1Aca12Bdb8052d26Ae6",
 				GenesisBlockIdentifier: optimism.MainnetGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                "http://blah",
@@ -103,9 +167,27 @@ func TestLoadConfiguration(t *testing.T) {
 			},
 		},
 		"all set (goerli)": {
-			Mode:    string(Online),
-			Network: Goerli,
-			Port:    "1000",
+			Mode:          string(Online),
+			Network:       Goerli,
+			Port:          "1000",
+			SubmitTimeout: "30",
+			SubmitURLs:    "https://sequencer.example.com/key, http://localhost:9545",
+			SubmitFanout:  optimism.AllFanout,
+
+			BalanceBatchSize:  "20",
+			Multicall3Address: "0xcA11bde05977b3631167028862bE2a173976CA11",
+
+			TokenDiscovery: optimism.TransferLogsDiscovery,
+			DiscoveryRange: "5000",
+
+			BlockCacheDir:    "blocks",
+			BlockCacheSizeMB: "16",
+
+			TraceCache:    "true",
+			TraceBackend:  optimism.RedisTraceCacheBackend,
+			TraceRedisURL: "redis://localhost:6379/0",
+			TraceCacheTTL: "3600",
+			MetricsPort:   "9090",
 			cfg: &Configuration{
 				Mode: Online,
 				Network: &types.NetworkIdentifier{
@@ -113,31 +195,163 @@ func TestLoadConfiguration(t *testing.T) {
 					Blockchain: optimism.Blockchain,
 				},
 				Params:                 params.GoerliChainConfig,
+				BedrockBlock:           big.NewInt(0),
+				Currency:               optimism.Currency,
+				SupportedTokens:        opTokenOnly,
+				GasPriceOracleOwner:    "0xa693B8f8207FF043F6bbC2E2120bbE4C2251Efe9",
 				GenesisBlockIdentifier: optimism.GoerliGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          optimism.GoerliGethArguments,
+				SubmitTimeout:          time.Second * 30,
+				SubmitURLs:             []string{"https://sequencer.example.com/key", "http://localhost:9545"},
+				SubmitFanout:           optimism.AllFanout,
+				BalanceBatchSize:       20,
+				Multicall3Address:      "0xcA11bde05977b3631167028862bE2a173976CA11",
 				TokenFilter:            true,
 				TraceByBlock:           false,
+
+				TokenDiscovery:           optimism.TransferLogsDiscovery,
+				TokenDiscoveryBlockRange: 5000,
+
+				BlockCachePath: "/data/blocks",
+				BlockCacheSize: 16 << 20,
+
+				EnableTraceCache:   true,
+				TraceCacheBackend:  optimism.RedisTraceCacheBackend,
+				TraceCacheRedisURL: "redis://localhost:6379/0",
+				TraceCacheTTL:      time.Hour,
+				MetricsPort:        9090,
 			},
 		},
 		"all set (testnet)": {
-			Mode:    string(Online),
-			Network: Testnet,
-			Port:    "1000",
+			Mode:         string(Online),
+			Network:      Testnet,
+			Port:         "1000",
+			TraceCache:   "true",
+			TraceBackend: optimism.DiskTraceCacheBackend,
 			cfg: &Configuration{
 				Mode: Online,
 				Network: &types.NetworkIdentifier{
 					Network:    optimism.TestnetNetwork,
 					Blockchain: optimism.Blockchain,
 				},
-				Params:                 params.TestnetChainConfig,
+				Params:                 withChainID(params.TestnetChainConfig, 420),
+				BedrockBlock:           big.NewInt(4061224),
+				Currency:               optimism.Currency,
+				SupportedTokens:        testnetTokens,
+				GasPriceOracleOwner:    "0xa693B8f8207FF043F6bbC2E2120bbE4C2251Efe9",
 				GenesisBlockIdentifier: optimism.TestnetGenesisBlockIdentifier,
 				Port:                   1000,
 				GethURL:                DefaultGethURL,
 				GethArguments:          optimism.TestnetGethArguments,
 				TokenFilter:            true,
 				TraceByBlock:           false,
+
+				EnableTraceCache:  true,
+				TraceCacheBackend: optimism.DiskTraceCacheBackend,
+				TraceCachePath:    "/data/traces",
+			},
+		},
+		"all set (mainnet) + mempool": {
+			Mode:          string(Online),
+			Network:       Mainnet,
+			Port:          "1000",
+			EnableMempool: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    optimism.MainnetNetwork,
+					Blockchain: optimism.Blockchain,
+				},
+				Params:                 withChainID(params.MainnetChainConfig, 10),
+				BedrockBlock:           big.NewInt(105235063),
+				Currency:               optimism.Currency,
+				SupportedTokens:        mainnetTokens,
+				GasPriceOracleOwner:    "0x7107142636C85c549690b1Aca12Bdb8052d26Ae6",
+				GenesisBlockIdentifier: optimism.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          optimism.MainnetGethArguments,
+				TokenFilter:            true,
+				EnableMempool:          true,
+			},
+		},
+		"all set (goerli) + mint ops": {
+			Mode:          string(Online),
+			Network:       Goerli,
+			Port:          "1000",
+			EnableMintOps: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    optimism.GoerliNetwork,
+					Blockchain: optimism.Blockchain,
+				},
+				Params:                 params.GoerliChainConfig,
+				BedrockBlock:           big.NewInt(0),
+				Currency:               optimism.Currency,
+				SupportedTokens:        opTokenOnly,
+				GasPriceOracleOwner:    "0xa693B8f8207FF043F6bbC2E2120bbE4C2251Efe9",
+				GenesisBlockIdentifier: optimism.GoerliGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          optimism.GoerliGethArguments,
+				TokenFilter:            true,
+				EnableMintOps:          true,
+			},
+		},
+		"all set (goerli) + nft ops": {
+			Mode:         string(Online),
+			Network:      Goerli,
+			Port:         "1000",
+			EnableNFTOps: "true",
+			NFTContracts: "0x5FbDB2315678afecb367f032d93F642f64180aa3, 0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    optimism.GoerliNetwork,
+					Blockchain: optimism.Blockchain,
+				},
+				Params:                 params.GoerliChainConfig,
+				BedrockBlock:           big.NewInt(0),
+				Currency:               optimism.Currency,
+				SupportedTokens:        opTokenOnly,
+				GasPriceOracleOwner:    "0xa693B8f8207FF043F6bbC2E2120bbE4C2251Efe9",
+				GenesisBlockIdentifier: optimism.GoerliGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          optimism.GoerliGethArguments,
+				TokenFilter:            true,
+				EnableNFTOps:           true,
+				NFTContracts: map[string]bool{
+					"0x5fbdb2315678afecb367f032d93f642f64180aa3": true,
+					"0xe7f1725e7734ce288f8367e1bb143e90bb3f0512": true,
+				},
+			},
+		},
+		"all set (goerli) + bridge ops": {
+			Mode:            string(Online),
+			Network:         Goerli,
+			Port:            "1000",
+			EnableBridgeOps: "true",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    optimism.GoerliNetwork,
+					Blockchain: optimism.Blockchain,
+				},
+				Params:                 params.GoerliChainConfig,
+				BedrockBlock:           big.NewInt(0),
+				Currency:               optimism.Currency,
+				SupportedTokens:        opTokenOnly,
+				GasPriceOracleOwner:    "0xa693B8f8207FF043F6bbC2E2120bbE4C2251Efe9",
+				GenesisBlockIdentifier: optimism.GoerliGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          optimism.GoerliGethArguments,
+				TokenFilter:            true,
+				EnableBridgeOps:        true,
 			},
 		},
 		"invalid mode": {
@@ -164,6 +378,190 @@ func TestLoadConfiguration(t *testing.T) {
 			L2GethHTTPTimeout: "bad val",
 			err:               errors.New("unable to parse L2_GETH_HTTP_TIMEOUT"),
 		},
+		"invalid enable mempool": {
+			Mode:          string(Offline),
+			Network:       Goerli,
+			Port:          "1000",
+			EnableMempool: "bad val",
+			err:           errors.New("unable to parse ENABLE_MEMPOOL"),
+		},
+		"invalid enable mint ops": {
+			Mode:          string(Offline),
+			Network:       Goerli,
+			Port:          "1000",
+			EnableMintOps: "bad val",
+			err:           errors.New("unable to parse ENABLE_MINT_OPS"),
+		},
+		"invalid nft contracts": {
+			Mode:         string(Offline),
+			Network:      Goerli,
+			Port:         "1000",
+			NFTContracts: "0x5FbDB2315678afecb367f032d93F642f64180aa3,bad",
+			err:          errors.New("bad is not a valid address in NFT_CONTRACTS"),
+		},
+		"invalid bridge ops": {
+			Mode:            string(Offline),
+			Network:         Goerli,
+			Port:            "1000",
+			EnableBridgeOps: "bad val",
+			err:             errors.New("unable to parse ENABLE_BRIDGE_OPS"),
+		},
+		"invalid submit timeout": {
+			Mode:          string(Offline),
+			Network:       Goerli,
+			Port:          "1000",
+			SubmitTimeout: "bad val",
+			err:           errors.New("unable to parse SUBMIT_TIMEOUT"),
+		},
+		"negative submit timeout": {
+			Mode:          string(Offline),
+			Network:       Goerli,
+			Port:          "1000",
+			SubmitTimeout: "-1",
+			err:           errors.New("SUBMIT_TIMEOUT must be positive"),
+		},
+		"invalid submit urls": {
+			Mode:       string(Online),
+			Network:    Goerli,
+			Port:       "1000",
+			SubmitURLs: "http://sequencer:8545,sequencer:8545",
+			err:        errors.New("sequencer:8545 is not a valid http endpoint in SUBMIT_URLS"),
+		},
+		"invalid submit fanout": {
+			Mode:         string(Online),
+			Network:      Goerli,
+			Port:         "1000",
+			SubmitFanout: "SOME",
+			err:          errors.New("SOME is not a valid SUBMIT_FANOUT"),
+		},
+		"invalid balance batch size": {
+			Mode:             string(Online),
+			Network:          Goerli,
+			Port:             "1000",
+			BalanceBatchSize: "0",
+			err:              errors.New("BALANCE_BATCH_SIZE must be positive"),
+		},
+		"invalid multicall3 address": {
+			Mode:              string(Online),
+			Network:           Goerli,
+			Port:              "1000",
+			Multicall3Address: "multicall",
+			err:               errors.New("multicall is not a valid address in MULTICALL3_ADDRESS"),
+		},
+		"invalid token discovery": {
+			Mode:           string(Online),
+			Network:        Goerli,
+			Port:           "1000",
+			TokenDiscovery: "ALL",
+			err:            errors.New("ALL is not a valid TOKEN_DISCOVERY"),
+		},
+		"invalid token discovery block range": {
+			Mode:           string(Online),
+			Network:        Goerli,
+			Port:           "1000",
+			DiscoveryRange: "-1",
+			err:            errors.New("unable to parse TOKEN_DISCOVERY_BLOCK_RANGE"),
+		},
+		"absolute block cache dir": {
+			Mode:          string(Online),
+			Network:       Goerli,
+			Port:          "1000",
+			BlockCacheDir: "/tmp/blocks",
+			err:           errors.New("BLOCK_CACHE_DIR must be relative to /data"),
+		},
+		"block cache dir outside data directory": {
+			Mode:          string(Online),
+			Network:       Goerli,
+			Port:          "1000",
+			BlockCacheDir: "../blocks",
+			err:           errors.New("BLOCK_CACHE_DIR ../blocks must be a directory inside /data"),
+		},
+		"trace cache backend without trace cache": {
+			Mode:         string(Online),
+			Network:      Goerli,
+			Port:         "1000",
+			TraceBackend: optimism.DiskTraceCacheBackend,
+			err:          errors.New("TRACE_CACHE_BACKEND requires ENABLE_TRACE_CACHE"),
+		},
+		"invalid trace cache backend": {
+			Mode:         string(Online),
+			Network:      Goerli,
+			Port:         "1000",
+			TraceCache:   "true",
+			TraceBackend: "MEMCACHED",
+			err:          errors.New("MEMCACHED is not a valid TRACE_CACHE_BACKEND"),
+		},
+		"trace cache dir outside data directory": {
+			Mode:          string(Online),
+			Network:       Goerli,
+			Port:          "1000",
+			TraceCache:    "true",
+			TraceBackend:  optimism.DiskTraceCacheBackend,
+			TraceCacheDir: "..",
+			err:           errors.New("TRACE_CACHE_DIR .. must be a directory inside /data"),
+		},
+		"missing trace cache redis url": {
+			Mode:         string(Online),
+			Network:      Goerli,
+			Port:         "1000",
+			TraceCache:   "true",
+			TraceBackend: optimism.RedisTraceCacheBackend,
+			err:          errors.New("TRACE_CACHE_REDIS_URL must be a redis:// or rediss:// url"),
+		},
+		"invalid trace cache ttl": {
+			Mode:          string(Online),
+			Network:       Goerli,
+			Port:          "1000",
+			TraceCacheTTL: "-1",
+			err:           errors.New("TRACE_CACHE_TTL must not be negative"),
+		},
+		"invalid metrics port": {
+			Mode:        string(Online),
+			Network:     Goerli,
+			Port:        "1000",
+			MetricsPort: "-1",
+			err:         errors.New("METRICS_PORT must be positive"),
+		},
+		"metrics port same as port": {
+			Mode:        string(Online),
+			Network:     Goerli,
+			Port:        "1000",
+			MetricsPort: "1000",
+			err:         errors.New("METRICS_PORT must differ from PORT"),
+		},
+		"invalid block cache size": {
+			Mode:             string(Online),
+			Network:          Goerli,
+			Port:             "1000",
+			BlockCacheSizeMB: "0",
+			err:              errors.New("BLOCK_CACHE_SIZE_MB must be positive"),
+		},
+		"auto network": {
+			Mode:    string(Online),
+			Network: Auto,
+			Port:    "1000",
+			Geth:    "http://blah",
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Blockchain: optimism.Blockchain,
+				},
+				Params:          withoutChainID(params.MainnetChainConfig),
+				AutoDiscover:    true,
+				Currency:        optimism.Currency,
+				SupportedTokens: opTokenOnly,
+				Port:            1000,
+				GethURL:         "http://blah",
+				RemoteGeth:      true,
+				TokenFilter:     true,
+			},
+		},
+		"auto network offline": {
+			Mode:    string(Offline),
+			Network: Auto,
+			Port:    "1000",
+			err:     errors.New("AUTO network is only supported in ONLINE mode"),
+		},
 	}
 
 	for name, test := range tests {
@@ -173,6 +571,26 @@ func TestLoadConfiguration(t *testing.T) {
 			os.Setenv(PortEnv, test.Port)
 			os.Setenv(GethEnv, test.Geth)
 			os.Setenv(L2GethHTTPTimeoutEnv, test.L2GethHTTPTimeout)
+			os.Setenv(EnableMempoolEnv, test.EnableMempool)
+			os.Setenv(EnableMintOpsEnv, test.EnableMintOps)
+			os.Setenv(EnableNFTOpsEnv, test.EnableNFTOps)
+			os.Setenv(NFTContractsEnv, test.NFTContracts)
+			os.Setenv(EnableBridgeOpsEnv, test.EnableBridgeOps)
+			os.Setenv(SubmitTimeoutEnv, test.SubmitTimeout)
+			os.Setenv(SubmitURLsEnv, test.SubmitURLs)
+			os.Setenv(SubmitFanoutEnv, test.SubmitFanout)
+			os.Setenv(BalanceBatchSizeEnv, test.BalanceBatchSize)
+			os.Setenv(Multicall3AddressEnv, test.Multicall3Address)
+			os.Setenv(TokenDiscoveryEnv, test.TokenDiscovery)
+			os.Setenv(TokenDiscoveryBlockRangeEnv, test.DiscoveryRange)
+			os.Setenv(BlockCacheDirEnv, test.BlockCacheDir)
+			os.Setenv(BlockCacheSizeMBEnv, test.BlockCacheSizeMB)
+			os.Setenv(EnableTraceCacheEnv, test.TraceCache)
+			os.Setenv(TraceCacheBackendEnv, test.TraceBackend)
+			os.Setenv(TraceCacheDirEnv, test.TraceCacheDir)
+			os.Setenv(TraceCacheRedisURLEnv, test.TraceRedisURL)
+			os.Setenv(TraceCacheTTLEnv, test.TraceCacheTTL)
+			os.Setenv(MetricsPortEnv, test.MetricsPort)
 
 			cfg, err := LoadConfiguration()
 			if test.err != nil {
@@ -185,3 +603,255 @@ func TestLoadConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfigurationFromFile(t *testing.T) {
+	devnet := &Configuration{
+		Mode: Online,
+		Network: &types.NetworkIdentifier{
+			Network:    "Devnet",
+			Blockchain: "Base",
+		},
+		Params: withChainID(params.MainnetChainConfig, 901),
+		GenesisBlockIdentifier: &types.BlockIdentifier{
+			Hash:  "0x0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b",
+			Index: 0,
+		},
+		Port:                1000,
+		GethURL:             "http://devnet:8545",
+		RemoteGeth:          true,
+		GethArguments:       "--devnet",
+		TokenFilter:         true,
+		BedrockBlock:        big.NewInt(0),
+		Currency:            &types.Currency{Symbol: "DEV", Decimals: 9},
+		SupportedTokens:     map[string]bool{"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": true},
+		GasPriceOracleOwner: "",
+	}
+
+	tests := map[string]struct {
+		filename string
+		content  string
+		env      map[string]string
+
+		cfg *Configuration
+		err error
+	}{
+		"yaml network": {
+			filename: "network.yaml",
+			content: `
+network:
+  blockchain: Base
+  network: Devnet
+  chain_id: 901
+  genesis_block_identifier:
+    hash: "0x0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b"
+    index: 0
+  currency:
+    symbol: DEV
+    decimals: 9
+  tokens:
+    - "0xDa10009cbd5d07dd0cecc66161fc93d7c9000da1"
+  geth_arguments: "--devnet"
+settings:
+  MODE: ONLINE
+  PORT: "1000"
+  GETH: "http://devnet:8545"
+`,
+			cfg: devnet,
+		},
+		"toml network": {
+			filename: "network.toml",
+			content: `
+[network]
+blockchain = "Base"
+network = "Devnet"
+chain_id = 901
+tokens = ["0xDa10009cbd5d07dd0cecc66161fc93d7c9000da1"]
+geth_arguments = "--devnet"
+
+[network.genesis_block_identifier]
+hash = "0x0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b"
+index = 0
+
+[network.currency]
+symbol = "DEV"
+decimals = 9
+
+[settings]
+MODE = "ONLINE"
+PORT = "1000"
+GETH = "http://devnet:8545"
+`,
+			cfg: devnet,
+		},
+		"env overrides settings": {
+			filename: "network.yaml",
+			content: `
+network:
+  preset: MAINNET
+settings:
+  MODE: OFFLINE
+  PORT: "2000"
+`,
+			env: map[string]string{
+				ModeEnv: string(Online),
+				PortEnv: "1000",
+			},
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    optimism.MainnetNetwork,
+					Blockchain: optimism.Blockchain,
+				},
+				Params:                 withChainID(params.MainnetChainConfig, 10),
+				GenesisBlockIdentifier: optimism.MainnetGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          optimism.MainnetGethArguments,
+				TokenFilter:            true,
+				BedrockBlock:           big.NewInt(105235063),
+				Currency:               optimism.Currency,
+				SupportedTokens:        mainnetTokens,
+				GasPriceOracleOwner:    "0x7107142636C85c549690b1Aca12Bdb8052d26Ae6",
+			},
+		},
+		"network env overrides preset": {
+			filename: "network.yaml",
+			content: `
+network:
+  preset: MAINNET
+  bedrock_block: 7
+`,
+			env: map[string]string{
+				ModeEnv:    string(Online),
+				PortEnv:    "1000",
+				NetworkEnv: Sepolia,
+			},
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    optimism.SepoliaNetwork,
+					Blockchain: optimism.Blockchain,
+				},
+				Params:                 withChainID(params.TestnetChainConfig, 11155420),
+				GenesisBlockIdentifier: optimism.SepoliaGenesisBlockIdentifier,
+				Port:                   1000,
+				GethURL:                DefaultGethURL,
+				GethArguments:          optimism.TestnetGethArguments,
+				TokenFilter:            true,
+				BedrockBlock:           big.NewInt(7),
+				Currency:               optimism.Currency,
+				SupportedTokens:        opTokenOnly,
+			},
+		},
+		"auto network with explicit values": {
+			filename: "network.yaml",
+			content: `
+network:
+  preset: AUTO
+  network: Devnet
+  chain_id: 901
+  bedrock_block: 0
+settings:
+  MODE: ONLINE
+  PORT: "1000"
+  GETH: "http://devnet:8545"
+  OP_NODE: "http://devnet:9545"
+`,
+			cfg: &Configuration{
+				Mode: Online,
+				Network: &types.NetworkIdentifier{
+					Network:    "Devnet",
+					Blockchain: optimism.Blockchain,
+				},
+				Params:          withChainID(params.MainnetChainConfig, 901),
+				AutoDiscover:    true,
+				BedrockBlock:    big.NewInt(0),
+				Currency:        optimism.Currency,
+				SupportedTokens: opTokenOnly,
+				Port:            1000,
+				GethURL:         "http://devnet:8545",
+				OpNodeURL:       "http://devnet:9545",
+				RemoteGeth:      true,
+				TokenFilter:     true,
+			},
+		},
+		"missing chain id": {
+			filename: "network.yaml",
+			content: `
+network:
+  blockchain: Base
+  network: Devnet
+  genesis_block_identifier:
+    hash: "0x0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b"
+  currency:
+    symbol: DEV
+    decimals: 9
+settings:
+  MODE: ONLINE
+  PORT: "1000"
+`,
+			err: errors.New("network config for Devnet must specify a chain ID"),
+		},
+		"invalid preset": {
+			filename: "network.yaml",
+			content: `
+network:
+  preset: KOVAN
+settings:
+  MODE: ONLINE
+`,
+			err: errors.New("KOVAN is not a valid network"),
+		},
+		"unsupported extension": {
+			filename: "network.json",
+			content:  `{}`,
+			err:      errors.New("unsupported config file extension"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.filename)
+			assert.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			for _, key := range []string{ModeEnv, NetworkEnv, PortEnv, GethEnv, OpNodeEnv, L2GethHTTPTimeoutEnv, EnableMempoolEnv, EnableMintOpsEnv, EnableNFTOpsEnv, NFTContractsEnv, EnableBridgeOpsEnv, SubmitTimeoutEnv, SubmitURLsEnv, SubmitFanoutEnv, BalanceBatchSizeEnv, Multicall3AddressEnv, TokenDiscoveryEnv, TokenDiscoveryBlockRangeEnv, BlockCacheDirEnv, BlockCacheSizeMBEnv, EnableTraceCacheEnv, TraceCacheBackendEnv, TraceCacheDirEnv, TraceCacheRedisURLEnv, TraceCacheTTLEnv, MetricsPortEnv} {
+				t.Setenv(key, test.env[key])
+			}
+			t.Setenv(NetworkConfigEnv, path)
+
+			cfg, err := LoadConfiguration()
+			if test.err != nil {
+				assert.Nil(t, cfg)
+				assert.Contains(t, err.Error(), test.err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.cfg, cfg)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationLegacyTokenList(t *testing.T) {
+	defer func(path string) { legacyTokenListPath = path }(legacyTokenListPath)
+	dir := t.TempDir()
+	legacyTokenListPath = filepath.Join(dir, "tokenList.json")
+	assert.NoError(t, os.WriteFile(legacyTokenListPath, []byte(`{"Mainnet": {}}`), 0o600))
+
+	os.Clearenv()
+	os.Setenv(ModeEnv, string(Online))
+	os.Setenv(NetworkEnv, Mainnet)
+	os.Setenv(PortEnv, "1000")
+
+	// A token list that has not been migrated stops the server from starting
+	cfg, err := LoadConfiguration()
+	assert.Nil(t, cfg)
+	assert.EqualError(t, err, legacyTokenListPath+" is no longer read: move its tokens to the tokens list of a NETWORK_CONFIG file")
+
+	// It is ignored once a network config file is set
+	configPath := filepath.Join(dir, "network.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("network:\n  preset: MAINNET\n"), 0o600))
+	os.Setenv(NetworkConfigEnv, configPath)
+	cfg, err = LoadConfiguration()
+	assert.NoError(t, err)
+	assert.Equal(t, "Mainnet", cfg.Network.Network)
+}
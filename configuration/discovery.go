@@ -0,0 +1,212 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/common"
+	"github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
+	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+)
+
+const (
+	// discoveryTimeout bounds each discovery RPC.
+	discoveryTimeout = 30 * time.Second
+
+	// The node may still be starting up when discovery begins (e.g. when geth is launched by us),
+	// so the first call is retried for up to discoveryAttempts * discoveryRetryInterval.
+	discoveryAttempts      = 30
+	discoveryRetryInterval = 2 * time.Second
+)
+
+// ErrNetworkMismatch is returned by [Configuration.DiscoverNetwork] when a discovered network
+// parameter disagrees with the configured one.
+var ErrNetworkMismatch = errors.New("network mismatch")
+
+// rpcBlockHeader is the subset of an eth_getBlockByNumber response used for discovery.
+type rpcBlockHeader struct {
+	Hash   common.Hash    `json:"hash"`
+	Number hexutil.Uint64 `json:"number"`
+}
+
+// rollupConfig is the subset of the op-node optimism_rollupConfig response used for discovery.
+type rollupConfig struct {
+	Genesis struct {
+		L2 struct {
+			Hash   common.Hash `json:"hash"`
+			Number uint64      `json:"number"`
+		} `json:"l2"`
+	} `json:"genesis"`
+	L2ChainID *big.Int `json:"l2_chain_id"`
+}
+
+// DiscoverNetwork populates the parameters of an AUTO network from the connected node:
+// the chain ID from eth_chainId, the genesis block from block 0 and, when an op-node is configured,
+// the bedrock block from its rollup config. Parameters that were explicitly configured are
+// cross-checked against the node instead, and an [ErrNetworkMismatch] is returned if they differ.
+//
+// Networks that are not AUTO are left untouched.
+func (c *Configuration) DiscoverNetwork(ctx context.Context) error {
+	if !c.AutoDiscover {
+		return nil
+	}
+
+	client, err := rpc.DialHTTPWithClient(c.GethURL, &http.Client{Timeout: discoveryTimeout})
+	if err != nil {
+		return fmt.Errorf("%w: unable to dial node", err)
+	}
+	defer client.Close()
+
+	chainID, err := discoverChainID(ctx, client)
+	if err != nil {
+		return err
+	}
+	if c.Params.ChainID != nil && c.Params.ChainID.Cmp(chainID) != 0 {
+		return fmt.Errorf("%w: configured chain ID %s but node reports %s", ErrNetworkMismatch, c.Params.ChainID, chainID)
+	}
+	c.Params.ChainID = chainID
+
+	// The genesis block is block 0 unless a different index was configured
+	var genesisIndex int64
+	if c.GenesisBlockIdentifier != nil {
+		genesisIndex = c.GenesisBlockIdentifier.Index
+	}
+	genesis, err := blockHeaderByNumber(ctx, client, uint64(genesisIndex))
+	if err != nil {
+		return fmt.Errorf("%w: unable to fetch genesis block", err)
+	}
+	if c.GenesisBlockIdentifier != nil && common.HexToHash(c.GenesisBlockIdentifier.Hash) != genesis.Hash {
+		return fmt.Errorf(
+			"%w: configured genesis block %s but node reports %s",
+			ErrNetworkMismatch,
+			c.GenesisBlockIdentifier.Hash,
+			genesis.Hash.Hex(),
+		)
+	}
+	c.GenesisBlockIdentifier = &types.BlockIdentifier{
+		Hash:  genesis.Hash.Hex(),
+		Index: int64(genesis.Number),
+	}
+
+	if len(c.OpNodeURL) > 0 {
+		bedrockBlock, err := discoverBedrockBlock(ctx, c.OpNodeURL, client, chainID)
+		if err != nil {
+			return err
+		}
+		if c.BedrockBlock != nil && c.BedrockBlock.Cmp(bedrockBlock) != 0 {
+			return fmt.Errorf("%w: configured bedrock block %s but op-node reports %s", ErrNetworkMismatch, c.BedrockBlock, bedrockBlock)
+		}
+		c.BedrockBlock = bedrockBlock
+	}
+	if c.BedrockBlock == nil {
+		log.Printf("no %s or bedrock block configured, assuming the network started on bedrock", OpNodeEnv)
+		c.BedrockBlock = big.NewInt(0)
+	}
+
+	if len(c.Network.Network) == 0 {
+		c.Network.Network = chainID.String()
+	}
+
+	log.Printf(
+		"discovered network %s: chain_id=%s genesis=%s bedrock_block=%s",
+		c.Network.Network,
+		chainID,
+		c.GenesisBlockIdentifier.Hash,
+		c.BedrockBlock,
+	)
+
+	return nil
+}
+
+// discoverChainID fetches the chain ID, retrying while the node is unreachable.
+func discoverChainID(ctx context.Context, client *rpc.Client) (*big.Int, error) {
+	var (
+		chainID hexutil.Big
+		err     error
+	)
+	for attempt := 1; attempt <= discoveryAttempts; attempt++ {
+		if err = client.CallContext(ctx, &chainID, "eth_chainId"); err == nil {
+			return (*big.Int)(&chainID), nil
+		}
+		log.Printf("unable to fetch chain ID (attempt %d/%d): %v", attempt, discoveryAttempts, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(discoveryRetryInterval):
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unable to fetch chain ID", err)
+}
+
+// discoverBedrockBlock returns the first bedrock block from the op-node rollup config.
+// The rollup config must describe the same chain as the node.
+func discoverBedrockBlock(
+	ctx context.Context,
+	opNodeURL string,
+	client *rpc.Client,
+	chainID *big.Int,
+) (*big.Int, error) {
+	opNode, err := rpc.DialHTTPWithClient(opNodeURL, &http.Client{Timeout: discoveryTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to dial op-node", err)
+	}
+	defer opNode.Close()
+
+	var cfg rollupConfig
+	if err := opNode.CallContext(ctx, &cfg, "optimism_rollupConfig"); err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch rollup config", err)
+	}
+	if cfg.L2ChainID == nil || cfg.L2ChainID.Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("%w: op-node reports chain ID %s but node reports %s", ErrNetworkMismatch, cfg.L2ChainID, chainID)
+	}
+
+	bedrockGenesis, err := blockHeaderByNumber(ctx, client, cfg.Genesis.L2.Number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch bedrock genesis block", err)
+	}
+	if bedrockGenesis.Hash != cfg.Genesis.L2.Hash {
+		return nil, fmt.Errorf(
+			"%w: op-node reports bedrock genesis %s at block %d but node reports %s",
+			ErrNetworkMismatch,
+			cfg.Genesis.L2.Hash.Hex(),
+			cfg.Genesis.L2.Number,
+			bedrockGenesis.Hash.Hex(),
+		)
+	}
+
+	return new(big.Int).SetUint64(cfg.Genesis.L2.Number), nil
+}
+
+func blockHeaderByNumber(ctx context.Context, client *rpc.Client, number uint64) (*rpcBlockHeader, error) {
+	var header *rpcBlockHeader
+	if err := client.CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+
+	return header, nil
+}
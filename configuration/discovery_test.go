@@ -0,0 +1,221 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	devnetGenesisHash = "0x0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b0c0d0e0f0a0b"
+	devnetBedrockHash = "0x1a1b1c1d1e1f1a1b1c1d1e1f1a1b1c1d1e1f1a1b1c1d1e1f1a1b1c1d1e1f1a1b"
+)
+
+// newRPCServer serves canned JSON-RPC results. Results for eth_getBlockByNumber are keyed by block number.
+func newRPCServer(t *testing.T, results map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("unable to decode request: %v", err)
+		}
+
+		key := req.Method
+		if req.Method == "eth_getBlockByNumber" {
+			var number string
+			assert.NoError(t, json.Unmarshal(req.Params[0], &number))
+			key += "/" + number
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  results[key],
+		}))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func devnetNode(t *testing.T) *httptest.Server {
+	return newRPCServer(t, map[string]interface{}{
+		"eth_chainId":              "0x385",
+		"eth_getBlockByNumber/0x0": map[string]string{"hash": devnetGenesisHash, "number": "0x0"},
+		"eth_getBlockByNumber/0x5": map[string]string{"hash": devnetBedrockHash, "number": "0x5"},
+	})
+}
+
+func devnetOpNode(t *testing.T, chainID int64, bedrockHash string) *httptest.Server {
+	return newRPCServer(t, map[string]interface{}{
+		"optimism_rollupConfig": map[string]interface{}{
+			"genesis": map[string]interface{}{
+				"l2": map[string]interface{}{"hash": bedrockHash, "number": 5},
+			},
+			"l2_chain_id": chainID,
+		},
+	})
+}
+
+func autoConfiguration(gethURL string) *Configuration {
+	return &Configuration{
+		Mode:         Online,
+		Network:      &types.NetworkIdentifier{Blockchain: defaultBlockchain},
+		Params:       withoutChainID(params.MainnetChainConfig),
+		GethURL:      gethURL,
+		AutoDiscover: true,
+	}
+}
+
+func TestDiscoverNetwork(t *testing.T) {
+	node := devnetNode(t)
+	opNode := devnetOpNode(t, 901, devnetBedrockHash)
+
+	tests := map[string]struct {
+		cfg func() *Configuration
+
+		expected *Configuration
+		err      error
+	}{
+		"discovers everything": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.OpNodeURL = opNode.URL
+				return cfg
+			},
+			expected: &Configuration{
+				Mode:                   Online,
+				Network:                &types.NetworkIdentifier{Blockchain: defaultBlockchain, Network: "901"},
+				GenesisBlockIdentifier: &types.BlockIdentifier{Hash: devnetGenesisHash, Index: 0},
+				Params:                 withChainID(params.MainnetChainConfig, 901),
+				BedrockBlock:           big.NewInt(5),
+				GethURL:                node.URL,
+				OpNodeURL:              opNode.URL,
+				AutoDiscover:           true,
+			},
+		},
+		"no op-node": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.Network.Network = "Devnet"
+				return cfg
+			},
+			expected: &Configuration{
+				Mode:                   Online,
+				Network:                &types.NetworkIdentifier{Blockchain: defaultBlockchain, Network: "Devnet"},
+				GenesisBlockIdentifier: &types.BlockIdentifier{Hash: devnetGenesisHash, Index: 0},
+				Params:                 withChainID(params.MainnetChainConfig, 901),
+				BedrockBlock:           big.NewInt(0),
+				GethURL:                node.URL,
+				AutoDiscover:           true,
+			},
+		},
+		"matching explicit values": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.OpNodeURL = opNode.URL
+				cfg.Params.ChainID = big.NewInt(901)
+				cfg.GenesisBlockIdentifier = &types.BlockIdentifier{Hash: devnetGenesisHash}
+				cfg.BedrockBlock = big.NewInt(5)
+				return cfg
+			},
+			expected: &Configuration{
+				Mode:                   Online,
+				Network:                &types.NetworkIdentifier{Blockchain: defaultBlockchain, Network: "901"},
+				GenesisBlockIdentifier: &types.BlockIdentifier{Hash: devnetGenesisHash, Index: 0},
+				Params:                 withChainID(params.MainnetChainConfig, 901),
+				BedrockBlock:           big.NewInt(5),
+				GethURL:                node.URL,
+				OpNodeURL:              opNode.URL,
+				AutoDiscover:           true,
+			},
+		},
+		"chain id mismatch": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.Params.ChainID = big.NewInt(10)
+				return cfg
+			},
+			err: errors.New("configured chain ID 10 but node reports 901"),
+		},
+		"genesis mismatch": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.GenesisBlockIdentifier = &types.BlockIdentifier{Hash: devnetBedrockHash}
+				return cfg
+			},
+			err: errors.New("configured genesis block " + devnetBedrockHash),
+		},
+		"bedrock block mismatch": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.OpNodeURL = opNode.URL
+				cfg.BedrockBlock = big.NewInt(0)
+				return cfg
+			},
+			err: errors.New("configured bedrock block 0 but op-node reports 5"),
+		},
+		"op-node chain id mismatch": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.OpNodeURL = devnetOpNode(t, 10, devnetBedrockHash).URL
+				return cfg
+			},
+			err: errors.New("op-node reports chain ID 10 but node reports 901"),
+		},
+		"op-node bedrock genesis mismatch": {
+			cfg: func() *Configuration {
+				cfg := autoConfiguration(node.URL)
+				cfg.OpNodeURL = devnetOpNode(t, 901, devnetGenesisHash).URL
+				return cfg
+			},
+			err: errors.New("op-node reports bedrock genesis " + devnetGenesisHash),
+		},
+		"not auto": {
+			cfg: func() *Configuration {
+				return &Configuration{Mode: Online, GethURL: node.URL}
+			},
+			expected: &Configuration{Mode: Online, GethURL: node.URL},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := test.cfg()
+			err := cfg.DiscoverNetwork(context.Background())
+			if test.err != nil {
+				assert.True(t, errors.Is(err, ErrNetworkMismatch))
+				assert.Contains(t, err.Error(), test.err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, cfg)
+			}
+		})
+	}
+}
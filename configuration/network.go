@@ -0,0 +1,322 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/common"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	"github.com/naoina/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultSupportedToken is the OP token predeploy.
+	defaultSupportedToken = "0x4200000000000000000000000000000000000042"
+
+	// defaultBlockchain is the blockchain of networks that do not name one.
+	defaultBlockchain = "Optimism"
+)
+
+// defaultCurrency is the native currency of networks that do not specify one.
+var defaultCurrency = CurrencyConfig{Symbol: "ETH", Decimals: 18} // nolint:gomnd
+
+//go:embed presets/*.yaml
+var presetFiles embed.FS
+
+// presetChainConfigs are the l2geth chain configs the built-in networks are derived from.
+// Networks that are not derived from a preset use the mainnet chain config.
+var presetChainConfigs = map[string]*params.ChainConfig{
+	Mainnet: params.MainnetChainConfig,
+	Testnet: params.TestnetChainConfig,
+	Goerli:  params.GoerliChainConfig,
+	Sepolia: params.TestnetChainConfig,
+}
+
+// FileConfig is the layout of the file referenced by NetworkConfigEnv.
+type FileConfig struct {
+	// Network describes the network to serve.
+	Network *NetworkConfig `yaml:"network" toml:"network"`
+
+	// Settings provides values for any of the environment variables read
+	// by LoadConfiguration, keyed by the environment variable name.
+	// Environment variables that are set take precedence over these values.
+	Settings map[string]string `yaml:"settings" toml:"settings"`
+}
+
+// NetworkConfig describes an OP Stack network.
+// Fields that are left unset are inherited from the preset, if any.
+type NetworkConfig struct {
+	// Preset is the name of a built-in network (e.g. MAINNET) to start from,
+	// or AUTO to discover the unset parameters from the connected node.
+	Preset string `yaml:"preset" toml:"preset"`
+
+	Blockchain             string                 `yaml:"blockchain" toml:"blockchain"`
+	Network                string                 `yaml:"network" toml:"network"`
+	ChainID                uint64                 `yaml:"chain_id" toml:"chain_id"`
+	GenesisBlockIdentifier *BlockIdentifierConfig `yaml:"genesis_block_identifier" toml:"genesis_block_identifier"`
+	BedrockBlock           *uint64                `yaml:"bedrock_block" toml:"bedrock_block"`
+	Currency               *CurrencyConfig        `yaml:"currency" toml:"currency"`
+	Tokens                 []string               `yaml:"tokens" toml:"tokens"`
+	GasPriceOracleOwner    string                 `yaml:"gas_price_oracle_owner" toml:"gas_price_oracle_owner"`
+	GethArguments          string                 `yaml:"geth_arguments" toml:"geth_arguments"`
+}
+
+// BlockIdentifierConfig is the file representation of a [types.BlockIdentifier].
+type BlockIdentifierConfig struct {
+	Hash  string `yaml:"hash" toml:"hash"`
+	Index int64  `yaml:"index" toml:"index"`
+}
+
+// CurrencyConfig is the file representation of a [types.Currency].
+type CurrencyConfig struct {
+	Symbol   string `yaml:"symbol" toml:"symbol"`
+	Decimals int32  `yaml:"decimals" toml:"decimals"`
+}
+
+// LoadFileConfig reads a YAML or TOML configuration file.
+// The format is selected by the file extension.
+func LoadFileConfig(path string) (*FileConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read config file %s", err, path)
+	}
+
+	var cfg FileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &cfg)
+	case ".toml":
+		err = toml.Unmarshal(content, &cfg)
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse config file %s", err, path)
+	}
+
+	return &cfg, nil
+}
+
+// PresetNetworkConfig returns the built-in network with the given name (e.g. MAINNET).
+func PresetNetworkConfig(name string) (*NetworkConfig, error) {
+	if _, ok := presetChainConfigs[name]; !ok {
+		return nil, fmt.Errorf("%s is not a valid network", name)
+	}
+
+	content, err := presetFiles.ReadFile(fmt.Sprintf("presets/%s.yaml", strings.ToLower(name)))
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg NetworkConfig
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse preset %s", err, name)
+	}
+	cfg.Preset = name
+
+	return &cfg, nil
+}
+
+// resolveNetworkConfig merges the network of the config file, if any, onto its preset.
+// A non-empty preset overrides the preset named in the file.
+func resolveNetworkConfig(preset string, file *NetworkConfig) (*NetworkConfig, error) {
+	if file != nil && len(preset) == 0 {
+		preset = file.Preset
+	}
+	if file == nil && len(preset) == 0 {
+		return nil, errors.New("NETWORK must be populated")
+	}
+
+	resolved := &NetworkConfig{}
+	switch preset {
+	case "":
+	case Auto:
+		resolved.Preset = Auto
+	default:
+		var err error
+		if resolved, err = PresetNetworkConfig(preset); err != nil {
+			return nil, err
+		}
+	}
+	if file == nil {
+		return resolved, nil
+	}
+
+	if len(file.Blockchain) > 0 {
+		resolved.Blockchain = file.Blockchain
+	}
+	if len(file.Network) > 0 {
+		resolved.Network = file.Network
+	}
+	if file.ChainID != 0 {
+		resolved.ChainID = file.ChainID
+	}
+	if file.GenesisBlockIdentifier != nil {
+		resolved.GenesisBlockIdentifier = file.GenesisBlockIdentifier
+	}
+	if file.BedrockBlock != nil {
+		resolved.BedrockBlock = file.BedrockBlock
+	}
+	if file.Currency != nil {
+		resolved.Currency = file.Currency
+	}
+	if file.Tokens != nil {
+		resolved.Tokens = file.Tokens
+	}
+	if len(file.GasPriceOracleOwner) > 0 {
+		resolved.GasPriceOracleOwner = file.GasPriceOracleOwner
+	}
+	if len(file.GethArguments) > 0 {
+		resolved.GethArguments = file.GethArguments
+	}
+
+	return resolved, nil
+}
+
+// apply validates the network and populates the network-specific fields of the Configuration.
+func (n *NetworkConfig) apply(config *Configuration) error {
+	if len(n.GasPriceOracleOwner) > 0 && !common.IsHexAddress(n.GasPriceOracleOwner) {
+		return fmt.Errorf("%s is not a valid gas price oracle owner", n.GasPriceOracleOwner)
+	}
+
+	var err error
+	if n.Preset == Auto {
+		err = n.applyDiscoverable(config)
+	} else {
+		err = n.applyStatic(config)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Networks without a token list only support the OP token
+	tokens := n.Tokens
+	if tokens == nil {
+		tokens = []string{defaultSupportedToken}
+	}
+	config.SupportedTokens = make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return fmt.Errorf("%s is not a valid token address", token)
+		}
+		config.SupportedTokens[strings.ToLower(token)] = true
+	}
+
+	config.GasPriceOracleOwner = n.GasPriceOracleOwner
+	config.GethArguments = n.GethArguments
+
+	return nil
+}
+
+// applyStatic populates a network whose parameters are fully described by its config.
+func (n *NetworkConfig) applyStatic(config *Configuration) error {
+	if len(n.Blockchain) == 0 || len(n.Network) == 0 {
+		return errors.New("network config must specify blockchain and network")
+	}
+	if n.GenesisBlockIdentifier == nil || len(n.GenesisBlockIdentifier.Hash) == 0 {
+		return fmt.Errorf("network config for %s must specify a genesis block identifier", n.Network)
+	}
+	if n.Currency == nil || len(n.Currency.Symbol) == 0 {
+		return fmt.Errorf("network config for %s must specify a currency", n.Network)
+	}
+
+	config.Network = &types.NetworkIdentifier{
+		Blockchain: n.Blockchain,
+		Network:    n.Network,
+	}
+	config.GenesisBlockIdentifier = &types.BlockIdentifier{
+		Hash:  n.GenesisBlockIdentifier.Hash,
+		Index: n.GenesisBlockIdentifier.Index,
+	}
+
+	// Copy the chain config so that the l2geth defaults are never modified
+	base, ok := presetChainConfigs[n.Preset]
+	if !ok {
+		if n.ChainID == 0 {
+			return fmt.Errorf("network config for %s must specify a chain ID", n.Network)
+		}
+		base = params.MainnetChainConfig
+	}
+	chainConfig := *base
+	if n.ChainID != 0 {
+		chainConfig.ChainID = new(big.Int).SetUint64(n.ChainID)
+	}
+	config.Params = &chainConfig
+
+	config.BedrockBlock = big.NewInt(0)
+	if n.BedrockBlock != nil {
+		config.BedrockBlock = new(big.Int).SetUint64(*n.BedrockBlock)
+	}
+
+	config.Currency = &types.Currency{
+		Symbol:   n.Currency.Symbol,
+		Decimals: n.Currency.Decimals,
+	}
+
+	return nil
+}
+
+// applyDiscoverable populates the explicitly configured fields of an AUTO network.
+// The chain ID, genesis block and bedrock block are left unset unless configured,
+// so that [Configuration.DiscoverNetwork] can tell them apart from discovered values.
+func (n *NetworkConfig) applyDiscoverable(config *Configuration) error {
+	config.AutoDiscover = true
+
+	config.Network = &types.NetworkIdentifier{
+		Blockchain: n.Blockchain,
+		Network:    n.Network,
+	}
+	if len(config.Network.Blockchain) == 0 {
+		config.Network.Blockchain = defaultBlockchain
+	}
+
+	if n.GenesisBlockIdentifier != nil && len(n.GenesisBlockIdentifier.Hash) > 0 {
+		config.GenesisBlockIdentifier = &types.BlockIdentifier{
+			Hash:  n.GenesisBlockIdentifier.Hash,
+			Index: n.GenesisBlockIdentifier.Index,
+		}
+	}
+
+	chainConfig := *params.MainnetChainConfig
+	chainConfig.ChainID = nil
+	if n.ChainID != 0 {
+		chainConfig.ChainID = new(big.Int).SetUint64(n.ChainID)
+	}
+	config.Params = &chainConfig
+
+	if n.BedrockBlock != nil {
+		config.BedrockBlock = new(big.Int).SetUint64(*n.BedrockBlock)
+	}
+
+	currency := defaultCurrency
+	if n.Currency != nil && len(n.Currency.Symbol) > 0 {
+		currency = *n.Currency
+	}
+	config.Currency = &types.Currency{
+		Symbol:   currency.Symbol,
+		Decimals: currency.Decimals,
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics defines the Prometheus metrics of rosetta-ethereum and serves them.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "rosetta"
+
+	// TraceCache labels the requests of the in-memory trace cache.
+	TraceCache = "trace"
+	// TraceCacheBackend labels the requests of the backend of the trace cache.
+	TraceCacheBackend = "trace_backend"
+	// CurrencyCache labels the requests of the ERC20 currency cache.
+	CurrencyCache = "currency"
+	// BlockCache labels the requests of the on-disk block cache.
+	BlockCache = "block"
+
+	// unknownEndpoint labels the requests of paths that are not Rosetta routes,
+	// so that they do not create a series per path
+	unknownEndpoint = "unknown"
+
+	// headLagTimeout bounds the node calls made for each scrape of the head lag
+	headLagTimeout = 5 * time.Second
+)
+
+var (
+	// Registry holds the metrics served by [Handler].
+	Registry = prometheus.NewRegistry()
+
+	factory = promauto.With(Registry)
+
+	// EndpointDuration is the latency of the Rosetta endpoints.
+	EndpointDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "endpoint_duration_seconds",
+		Help:      "Latency of the Rosetta endpoints.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14), // nolint:gomnd
+	}, []string{"endpoint"})
+
+	// EndpointErrors counts the errors returned by the Rosetta endpoints by [types.Error] code.
+	EndpointErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "endpoint_errors_total",
+		Help:      "Errors returned by the Rosetta endpoints, by error code.",
+	}, []string{"endpoint", "code"})
+
+	// RPCDuration is the latency of the JSON-RPC calls to the node. Batches are labelled by
+	// the method of their calls, or "batch" when they mix methods.
+	RPCDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of the JSON-RPC calls to the node, by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16), // nolint:gomnd
+	}, []string{"method"})
+
+	// RPCFailures counts the failed JSON-RPC calls to the node.
+	RPCFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_failures_total",
+		Help:      "Failed JSON-RPC calls to the node, by method.",
+	}, []string{"method"})
+
+	// TraceSemaphoreWait is how long tracing waits for the trace semaphore.
+	TraceSemaphoreWait = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "trace_semaphore_wait_seconds",
+		Help:      "Time spent waiting for the trace semaphore.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16), // nolint:gomnd
+	})
+
+	// TraceSemaphoreQueue is the number of callers waiting for the trace semaphore.
+	TraceSemaphoreQueue = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "trace_semaphore_queue_depth",
+		Help:      "Number of callers waiting for the trace semaphore.",
+	})
+
+	// CacheRequests counts the hits and misses of the caches.
+	CacheRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	// ReceiptBatchSize is the number of receipts fetched by each JSON-RPC batch.
+	ReceiptBatchSize = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "receipt_batch_size",
+		Help:      "Number of receipts fetched by each JSON-RPC batch.",
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500}, // nolint:gomnd
+	})
+
+	headLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "head_lag_blocks"),
+		"Number of blocks between the latest and the safe head of the node.",
+		nil, nil,
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics of the [Registry].
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveCache counts a lookup of cache.
+func ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// headLagCollector queries the head lag from the node when the metrics are scraped
+type headLagCollector struct {
+	headLag func(ctx context.Context) (int64, error)
+}
+
+func (c *headLagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- headLagDesc
+}
+
+func (c *headLagCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), headLagTimeout)
+	defer cancel()
+	lag, err := c.headLag(ctx)
+	if err != nil {
+		log.Printf("unable to get head lag: %v", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(headLagDesc, prometheus.GaugeValue, float64(lag))
+}
+
+// RegisterHeadLag registers the head lag metric, which calls headLag on each scrape.
+func RegisterHeadLag(headLag func(ctx context.Context) (int64, error)) error {
+	return Registry.Register(&headLagCollector{headLag: headLag})
+}
+
+// statusRecorder records the status and error body of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status >= http.StatusBadRequest {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Middleware records the latency of the Rosetta endpoints and the codes of the errors they return.
+// Only the routes of routers are labelled by their path, any other path is labelled as unknown.
+func Middleware(next http.Handler, routers ...server.Router) http.Handler {
+	endpoints := make(map[string]bool)
+	for _, router := range routers {
+		for _, route := range router.Routes() {
+			endpoints[route.Pattern] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		endpoint := unknownEndpoint
+		if endpoints[r.URL.Path] {
+			endpoint = r.URL.Path
+		}
+		EndpointDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+		if recorder.status >= http.StatusBadRequest {
+			code := "unknown"
+			var rosettaErr types.Error
+			if err := json.Unmarshal(recorder.body.Bytes(), &rosettaErr); err == nil {
+				code = strconv.Itoa(int(rosettaErr.Code))
+			}
+			EndpointErrors.WithLabelValues(endpoint, code).Inc()
+		}
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRouter serves the given routes
+type testRouter server.Routes
+
+func (r testRouter) Routes() server.Routes {
+	return server.Routes(r)
+}
+
+func TestMiddleware(t *testing.T) {
+	router := testRouter{
+		{
+			Name:    "Block",
+			Method:  http.MethodPost,
+			Pattern: "/block",
+			HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				server.EncodeJSONResponse(&types.Block{}, http.StatusOK, w)
+			},
+		},
+		{
+			Name:    "AccountBalance",
+			Method:  http.MethodPost,
+			Pattern: "/account/balance",
+			HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				server.EncodeJSONResponse(&types.Error{Code: 38, Message: "state unavailable"}, http.StatusInternalServerError, w)
+			},
+		},
+	}
+	handler := Middleware(server.CorsMiddleware(server.NewRouter(router)), router)
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/block"},
+		{http.MethodPost, "/block"},
+		{http.MethodPost, "/account/balance"},
+		{http.MethodPost, "/nope"},
+		// Preflight requests of any path succeed
+		{http.MethodOptions, "/preflight/1"},
+		{http.MethodOptions, "/preflight/2"},
+		// Paths with a trailing slash are redirected
+		{http.MethodPost, "/block/"},
+	}
+	for _, request := range requests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(request.method, request.path, nil))
+	}
+
+	assert.Equal(t, 3, testutil.CollectAndCount(EndpointDuration))
+	for endpoint, count := range map[string]uint64{"/block": 2, "/account/balance": 1, unknownEndpoint: 4} {
+		metric := &dto.Metric{}
+		assert.NoError(t, EndpointDuration.WithLabelValues(endpoint).(prometheus.Histogram).Write(metric))
+		assert.Equal(t, count, metric.GetHistogram().GetSampleCount(), endpoint)
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(EndpointErrors.WithLabelValues("/account/balance", "38")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(EndpointErrors.WithLabelValues(unknownEndpoint, "unknown")))
+	assert.Equal(t, 2, testutil.CollectAndCount(EndpointErrors))
+}
+
+func TestObserveCache(t *testing.T) {
+	ObserveCache("test", true)
+	ObserveCache("test", true)
+	ObserveCache("test", false)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(CacheRequests.WithLabelValues("test", "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(CacheRequests.WithLabelValues("test", "miss")))
+}
+
+func TestHeadLag(t *testing.T) {
+	var err error
+	collector := &headLagCollector{headLag: func(ctx context.Context) (int64, error) {
+		return 12, err
+	}}
+
+	expected := `
+# HELP rosetta_head_lag_blocks Number of blocks between the latest and the safe head of the node.
+# TYPE rosetta_head_lag_blocks gauge
+rosetta_head_lag_blocks 12
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	// The lag is left out when the node cannot be reached
+	err = errors.New("connection refused")
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
+
+func TestHandler(t *testing.T) {
+	TraceSemaphoreQueue.Set(0)
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(TraceSemaphoreQueue))
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "rosetta_trace_semaphore_queue_depth 0")
+	assert.Contains(t, recorder.Body.String(), "go_goroutines")
+}
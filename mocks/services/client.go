@@ -14,6 +14,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	optimism "github.com/inphi/optimism-rosetta/optimism"
+
 	types "github.com/coinbase/rosetta-sdk-go/types"
 )
 
@@ -100,6 +102,67 @@ func (_m *Client) Block(_a0 context.Context, _a1 *types.PartialBlockIdentifier)
 	return r0, r1
 }
 
+// BlockTransaction provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Client) BlockTransaction(_a0 context.Context, _a1 *types.BlockIdentifier, _a2 *types.TransactionIdentifier) (*types.Transaction, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *types.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BlockIdentifier, *types.TransactionIdentifier) (*types.Transaction, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.BlockIdentifier, *types.TransactionIdentifier) *types.Transaction); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.BlockIdentifier, *types.TransactionIdentifier) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BlockWithOtherTransactions provides a mock function with given fields: _a0, _a1
+func (_m *Client) BlockWithOtherTransactions(_a0 context.Context, _a1 *types.PartialBlockIdentifier) (*types.Block, []*types.TransactionIdentifier, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *types.Block
+	var r1 []*types.TransactionIdentifier
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *types.PartialBlockIdentifier) (*types.Block, []*types.TransactionIdentifier, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *types.PartialBlockIdentifier) *types.Block); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *types.PartialBlockIdentifier) []*types.TransactionIdentifier); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*types.TransactionIdentifier)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *types.PartialBlockIdentifier) error); ok {
+		r2 = rf(_a0, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Call provides a mock function with given fields: ctx, request
 func (_m *Client) Call(ctx context.Context, request *types.CallRequest) (*types.CallResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -178,6 +241,39 @@ func (_m *Client) CodeAt(ctx context.Context, contract common.Address, blockNumb
 	return r0, r1
 }
 
+// CreateAccessList provides a mock function with given fields: ctx, msg
+func (_m *Client) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (*coretypes.AccessList, uint64, error) {
+	ret := _m.Called(ctx, msg)
+
+	var r0 *coretypes.AccessList
+	var r1 uint64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ethereum.CallMsg) (*coretypes.AccessList, uint64, error)); ok {
+		return rf(ctx, msg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ethereum.CallMsg) *coretypes.AccessList); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.AccessList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ethereum.CallMsg) uint64); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ethereum.CallMsg) error); ok {
+		r2 = rf(ctx, msg)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // EstimateGas provides a mock function with given fields: ctx, msg
 func (_m *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
 	ret := _m.Called(ctx, msg)
@@ -254,6 +350,58 @@ func (_m *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*coretyp
 	return r0, r1
 }
 
+// Mempool provides a mock function with given fields: ctx
+func (_m *Client) Mempool(ctx context.Context) ([]*types.TransactionIdentifier, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*types.TransactionIdentifier
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*types.TransactionIdentifier, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*types.TransactionIdentifier); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.TransactionIdentifier)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MempoolTransaction provides a mock function with given fields: ctx, hash
+func (_m *Client) MempoolTransaction(ctx context.Context, hash string) (*types.Transaction, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *types.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.Transaction, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Transaction); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PendingCodeAt provides a mock function with given fields: ctx, account
 func (_m *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
 	ret := _m.Called(ctx, account)
@@ -304,6 +452,41 @@ func (_m *Client) PendingNonceAt(_a0 context.Context, _a1 common.Address) (uint6
 	return r0, r1
 }
 
+// PendingTransaction provides a mock function with given fields: ctx, hash
+func (_m *Client) PendingTransaction(ctx context.Context, hash string) (*coretypes.Transaction, common.Address, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *coretypes.Transaction
+	var r1 common.Address
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*coretypes.Transaction, common.Address, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *coretypes.Transaction); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) common.Address); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(common.Address)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, hash)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // SendTransaction provides a mock function with given fields: ctx, tx
 func (_m *Client) SendTransaction(ctx context.Context, tx *coretypes.Transaction) error {
 	ret := _m.Called(ctx, tx)
@@ -318,6 +501,32 @@ func (_m *Client) SendTransaction(ctx context.Context, tx *coretypes.Transaction
 	return r0
 }
 
+// SimulateTransaction provides a mock function with given fields: ctx, msg
+func (_m *Client) SimulateTransaction(ctx context.Context, msg ethereum.CallMsg) (*optimism.SimulationResult, error) {
+	ret := _m.Called(ctx, msg)
+
+	var r0 *optimism.SimulationResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ethereum.CallMsg) (*optimism.SimulationResult, error)); ok {
+		return rf(ctx, msg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ethereum.CallMsg) *optimism.SimulationResult); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*optimism.SimulationResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ethereum.CallMsg) error); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Status provides a mock function with given fields: _a0
 func (_m *Client) Status(_a0 context.Context) (*types.BlockIdentifier, int64, *types.SyncStatus, []*types.Peer, error) {
 	ret := _m.Called(_a0)
@@ -369,6 +578,32 @@ func (_m *Client) Status(_a0 context.Context) (*types.BlockIdentifier, int64, *t
 	return r0, r1, r2, r3, r4
 }
 
+// SubmitTransaction provides a mock function with given fields: ctx, tx, conditional
+func (_m *Client) SubmitTransaction(ctx context.Context, tx *coretypes.Transaction, conditional *optimism.TransactionConditional) ([]*optimism.SubmitResult, error) {
+	ret := _m.Called(ctx, tx, conditional)
+
+	var r0 []*optimism.SubmitResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *coretypes.Transaction, *optimism.TransactionConditional) ([]*optimism.SubmitResult, error)); ok {
+		return rf(ctx, tx, conditional)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *coretypes.Transaction, *optimism.TransactionConditional) []*optimism.SubmitResult); ok {
+		r0 = rf(ctx, tx, conditional)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*optimism.SubmitResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *coretypes.Transaction, *optimism.TransactionConditional) error); ok {
+		r1 = rf(ctx, tx, conditional)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SubscribeFilterLogs provides a mock function with given fields: ctx, query, ch
 func (_m *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- coretypes.Log) (ethereum.Subscription, error) {
 	ret := _m.Called(ctx, query, ch)
@@ -447,6 +682,58 @@ func (_m *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return r0, r1
 }
 
+// TokenCurrency provides a mock function with given fields: ctx, contractAddress
+func (_m *Client) TokenCurrency(ctx context.Context, contractAddress string) (*types.Currency, error) {
+	ret := _m.Called(ctx, contractAddress)
+
+	var r0 *types.Currency
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*types.Currency, error)); ok {
+		return rf(ctx, contractAddress)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *types.Currency); ok {
+		r0 = rf(ctx, contractAddress)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Currency)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, contractAddress)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TransactionInclusion provides a mock function with given fields: ctx, hash, level
+func (_m *Client) TransactionInclusion(ctx context.Context, hash common.Hash, level string) (*optimism.Inclusion, error) {
+	ret := _m.Called(ctx, hash, level)
+
+	var r0 *optimism.Inclusion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, string) (*optimism.Inclusion, error)); ok {
+		return rf(ctx, hash, level)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, common.Hash, string) *optimism.Inclusion); ok {
+		r0 = rf(ctx, hash, level)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*optimism.Inclusion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, common.Hash, string) error); ok {
+		r1 = rf(ctx, hash, level)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewClient creates a new instance of Client. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewClient(t interface {
@@ -0,0 +1,317 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// blockCacheVersion is bumped whenever the blocks returned by [Client.Block] change,
+// so that blocks parsed by earlier releases are dropped from existing caches
+const blockCacheVersion = 1
+
+// finalizedRefreshInterval is how often the finalized head is fetched to decide whether blocks can be cached
+const finalizedRefreshInterval = 5 * time.Second
+
+var (
+	blockCacheVersionKey = []byte("version")
+	blockCacheSizeKey    = []byte("size")
+	blockCacheBlockKey   = []byte("b/")
+	blockCacheHashKey    = []byte("h/")
+)
+
+// BlockCache is an on-disk cache of the parsed blocks returned by [Client.Block] and [Client.BlockWithOtherTransactions].
+// Only finalized blocks are cached, since they can no longer be reorged out. The
+// lowest blocks are evicted once the cached blocks exceed the size of the cache.
+type BlockCache struct {
+	db      *leveldb.DB
+	maxSize int64
+
+	// mu serializes writes, keeping the size of the cache consistent
+	mu   sync.Mutex
+	size int64
+}
+
+// OpenBlockCache opens the block cache at path, creating it if needed. Blocks cached
+// with another version are dropped, as they may have been parsed differently.
+func OpenBlockCache(path string, maxSize int64, version string) (*BlockCache, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open block cache %s", err, path)
+	}
+	c := &BlockCache{db: db, maxSize: maxSize}
+
+	storedVersion, err := db.Get(blockCacheVersionKey, nil)
+	switch {
+	case errors.Is(err, leveldb.ErrNotFound) || (err == nil && string(storedVersion) != version):
+		if err := c.reset(version); err != nil {
+			db.Close()
+			return nil, err
+		}
+	case err != nil:
+		db.Close()
+		return nil, err
+	default:
+		size, err := db.Get(blockCacheSizeKey, nil)
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			db.Close()
+			return nil, err
+		}
+		if len(size) == 8 { // nolint:gomnd
+			c.size = int64(binary.BigEndian.Uint64(size))
+		}
+	}
+
+	return c, nil
+}
+
+// reset drops every cached block and stores the version of the cache
+func (c *BlockCache) reset(version string) error {
+	batch := new(leveldb.Batch)
+	iter := c.db.NewIterator(nil, nil)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	batch.Put(blockCacheVersionKey, []byte(version))
+	batch.Put(blockCacheSizeKey, encodeUint64(0))
+	c.size = 0
+	return c.db.Write(batch, nil)
+}
+
+// Close closes the block cache.
+func (c *BlockCache) Close() error {
+	return c.db.Close()
+}
+
+// Size returns the size of the cached blocks in bytes.
+func (c *BlockCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Get returns the cached block at the partial block identifier, or nil if it is not cached.
+// The latest block, which is requested without an index or hash, is never cached.
+func (c *BlockCache) Get(blockIdentifier *RosettaTypes.PartialBlockIdentifier) (*RosettaTypes.Block, error) {
+	if blockIdentifier == nil || (blockIdentifier.Index == nil && blockIdentifier.Hash == nil) {
+		return nil, nil
+	}
+
+	var key []byte
+	if blockIdentifier.Index != nil {
+		key = blockKey(*blockIdentifier.Index)
+	} else {
+		index, err := c.db.Get(hashKey(*blockIdentifier.Hash), nil)
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = append(append([]byte{}, blockCacheBlockKey...), index...)
+	}
+
+	data, err := c.db.Get(key, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var block RosettaTypes.Block
+	if err := json.Unmarshal(data, &block); err != nil {
+		return nil, err
+	}
+	// A block requested by both index and hash is only returned if both match
+	if blockIdentifier.Hash != nil && *blockIdentifier.Hash != block.BlockIdentifier.Hash {
+		return nil, nil
+	}
+	return &block, nil
+}
+
+// Put caches a block, evicting the lowest blocks if the cache grows beyond its size.
+func (c *BlockCache) Put(block *RosettaTypes.Block) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+	key := blockKey(block.BlockIdentifier.Index)
+	hKey := hashKey(block.BlockIdentifier.Hash)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok, err := c.db.Has(key, nil); err != nil || ok {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(key, data)
+	batch.Put(hKey, key[len(blockCacheBlockKey):])
+	size := c.size + entrySize(key, data, hKey)
+	if size > c.maxSize {
+		freed, err := c.deleteRange(batch, util.BytesPrefix(blockCacheBlockKey), size-c.maxSize)
+		if err != nil {
+			return err
+		}
+		log.Printf("evicted %d bytes of blocks from the block cache", freed)
+		size -= freed
+	}
+	batch.Put(blockCacheSizeKey, encodeUint64(uint64(size)))
+	if err := c.db.Write(batch, nil); err != nil {
+		return err
+	}
+	c.size = size
+	return nil
+}
+
+// Prune removes the cached blocks from start to end (inclusive).
+func (c *BlockCache) Prune(start int64, end int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	batch := new(leveldb.Batch)
+	freed, err := c.deleteRange(batch, &util.Range{Start: blockKey(start), Limit: blockKey(end + 1)}, -1)
+	if err != nil {
+		return 0, err
+	}
+	batch.Put(blockCacheSizeKey, encodeUint64(uint64(c.size-freed)))
+	if err := c.db.Write(batch, nil); err != nil {
+		return 0, err
+	}
+	c.size -= freed
+	return freed, nil
+}
+
+// deleteRange adds the deletion of the cached blocks in r to batch, in ascending order,
+// until at least limit bytes are freed. A negative limit deletes every block in r.
+func (c *BlockCache) deleteRange(batch *leveldb.Batch, r *util.Range, limit int64) (int64, error) {
+	var freed int64
+	iter := c.db.NewIterator(r, nil)
+	defer iter.Release()
+	for (limit < 0 || freed < limit) && iter.Next() {
+		var cached struct {
+			BlockIdentifier *RosettaTypes.BlockIdentifier `json:"block_identifier"`
+		}
+		if err := json.Unmarshal(iter.Value(), &cached); err != nil {
+			return 0, err
+		}
+		key := append([]byte{}, iter.Key()...)
+		hKey := hashKey(cached.BlockIdentifier.Hash)
+		batch.Delete(key)
+		batch.Delete(hKey)
+		freed += entrySize(key, iter.Value(), hKey)
+	}
+	return freed, iter.Error()
+}
+
+// blockKey returns the key of the block at index, which sorts blocks by height
+func blockKey(index int64) []byte {
+	return append(append([]byte{}, blockCacheBlockKey...), encodeUint64(uint64(index))...)
+}
+
+func hashKey(hash string) []byte {
+	return append(append([]byte{}, blockCacheHashKey...), hash...)
+}
+
+// entrySize is the number of bytes a cached block accounts for
+func entrySize(key []byte, data []byte, hKey []byte) int64 {
+	return int64(len(key) + len(data) + len(hKey) + len(key) - len(blockCacheBlockKey))
+}
+
+func encodeUint64(v uint64) []byte {
+	b := make([]byte, 8) // nolint:gomnd
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// blockCacheConfigVersion returns the version of the block cache of a client, which
+// also changes with the options that affect how blocks are parsed
+func blockCacheConfigVersion(opts ClientOptions) string {
+	tokens := make([]string, 0, len(opts.SupportedTokens)+len(opts.NFTContracts))
+	for token, ok := range opts.SupportedTokens {
+		if ok {
+			tokens = append(tokens, "erc20:"+strings.ToLower(token))
+		}
+	}
+	for contract, ok := range opts.NFTContracts {
+		if ok {
+			tokens = append(tokens, "nft:"+strings.ToLower(contract))
+		}
+	}
+	sort.Strings(tokens)
+	tokensHash := sha256.Sum256([]byte(strings.Join(tokens, ",")))
+
+	return fmt.Sprintf(
+		"%d/chain=%s/genesis=%s/bedrock=%s/filter=%t/mint=%t/nft=%t/bridge=%t/tokens=%x/gpo=%s",
+		blockCacheVersion, opts.ChainID, strings.ToLower(opts.GenesisBlockHash), opts.BedrockBlock,
+		opts.FilterTokens, opts.EnableMintOps, opts.EnableNFTOps, opts.EnableBridgeOps, tokensHash[:8],
+		strings.ToLower(opts.GasPriceOracleOwner),
+	)
+}
+
+// cacheFinalizedBlock caches block if it is finalized. The finalized head is only
+// fetched again for blocks above the last known finalized head, at most once every
+// finalizedRefreshInterval so that blocks at the tip do not each cost an extra call.
+func (ec *Client) cacheFinalizedBlock(ctx context.Context, block *RosettaTypes.Block) {
+	index := block.BlockIdentifier.Index
+	if index > ec.finalizedIndex.Load() {
+		refreshed := ec.finalizedRefreshed.Load()
+		now := time.Now().UnixNano()
+		if now-refreshed < int64(finalizedRefreshInterval) || !ec.finalizedRefreshed.CompareAndSwap(refreshed, now) {
+			return
+		}
+		head, err := ec.blockHeaderByTag(ctx, FinalizedInclusion)
+		if err != nil {
+			// Legacy nodes do not know about the finalized block
+			return
+		}
+		ec.finalizedIndex.Store(head.Number.Int64())
+		if index > head.Number.Int64() {
+			return
+		}
+	}
+	if err := ec.blockCache.Put(block); err != nil {
+		log.Printf("unable to cache block %d: %v", index, err)
+	}
+}
+
+// PruneBlockCache removes the cached blocks from start to end (inclusive) and returns the
+// number of bytes freed.
+func (ec *Client) PruneBlockCache(start int64, end int64) (int64, error) {
+	if ec.blockCache == nil {
+		return 0, ErrBlockCacheDisabled
+	}
+	return ec.blockCache.Prune(start, end)
+}
@@ -0,0 +1,297 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func cachedBlock(index int64) *RosettaTypes.Block {
+	return &RosettaTypes.Block{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Index: index,
+			Hash:  fmt.Sprintf("0x%064x", index),
+		},
+		ParentBlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Index: index - 1,
+			Hash:  fmt.Sprintf("0x%064x", index-1),
+		},
+		Timestamp:    1000 * index,
+		Transactions: []*RosettaTypes.Transaction{},
+	}
+}
+
+func openTestBlockCache(t *testing.T, path string, maxSize int64, version string) *BlockCache {
+	c, err := OpenBlockCache(path, maxSize, version)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestBlockCache(t *testing.T) {
+	c := openTestBlockCache(t, t.TempDir(), 1<<20, "v1")
+	block := cachedBlock(10)
+	assert.NoError(t, c.Put(block))
+
+	index := block.BlockIdentifier.Index
+	hash := block.BlockIdentifier.Hash
+	otherHash := cachedBlock(11).BlockIdentifier.Hash
+	for name, identifier := range map[string]*RosettaTypes.PartialBlockIdentifier{
+		"index":          {Index: &index},
+		"hash":           {Hash: &hash},
+		"index and hash": {Index: &index, Hash: &hash},
+	} {
+		cached, err := c.Get(identifier)
+		assert.NoError(t, err, name)
+		assert.Equal(t, block, cached, name)
+	}
+
+	for name, identifier := range map[string]*RosettaTypes.PartialBlockIdentifier{
+		"latest":              nil,
+		"empty":               {},
+		"uncached hash":       {Hash: &otherHash},
+		"mismatched hash":     {Index: &index, Hash: &otherHash},
+		"uncached index":      {Index: RosettaTypes.Int64(11)},
+		"uncached index hash": {Index: RosettaTypes.Int64(11), Hash: &hash},
+	} {
+		cached, err := c.Get(identifier)
+		assert.NoError(t, err, name)
+		assert.Nil(t, cached, name)
+	}
+}
+
+func TestBlockCache_Version(t *testing.T) {
+	path := t.TempDir()
+	c, err := OpenBlockCache(path, 1<<20, "v1")
+	require.NoError(t, err)
+	require.NoError(t, c.Put(cachedBlock(10)))
+	size := c.Size()
+	assert.Positive(t, size)
+	require.NoError(t, c.Close())
+
+	// Reopening with the same version keeps the cached blocks
+	c, err = OpenBlockCache(path, 1<<20, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, size, c.Size())
+	cached, err := c.Get(&RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(10)})
+	assert.NoError(t, err)
+	assert.NotNil(t, cached)
+	require.NoError(t, c.Close())
+
+	// Reopening with another version drops them
+	c = openTestBlockCache(t, path, 1<<20, "v2")
+	assert.Zero(t, c.Size())
+	cached, err = c.Get(&RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(10)})
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+}
+
+func TestBlockCache_Eviction(t *testing.T) {
+	c := openTestBlockCache(t, t.TempDir(), 1<<20, "v1")
+	require.NoError(t, c.Put(cachedBlock(1)))
+	blockSize := c.Size()
+
+	// Room for 3 blocks: caching a 4th evicts the lowest one
+	c.maxSize = 3 * blockSize
+	for _, index := range []int64{3, 2, 4} {
+		require.NoError(t, c.Put(cachedBlock(index)))
+	}
+	assert.Equal(t, 3*blockSize, c.Size())
+
+	lowest := cachedBlock(1).BlockIdentifier.Hash
+	cached, err := c.Get(&RosettaTypes.PartialBlockIdentifier{Hash: &lowest})
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+	for _, index := range []int64{2, 3, 4} {
+		cached, err := c.Get(&RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(index)})
+		assert.NoError(t, err)
+		assert.NotNil(t, cached, index)
+	}
+
+	// Caching a block twice does not count it twice
+	require.NoError(t, c.Put(cachedBlock(4)))
+	assert.Equal(t, 3*blockSize, c.Size())
+}
+
+func TestBlockCache_Prune(t *testing.T) {
+	c := openTestBlockCache(t, t.TempDir(), 1<<20, "v1")
+	for index := int64(1); index <= 5; index++ {
+		require.NoError(t, c.Put(cachedBlock(index)))
+	}
+	blockSize := c.Size() / 5
+
+	freed, err := c.Prune(2, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*blockSize, freed)
+	assert.Equal(t, 2*blockSize, c.Size())
+
+	for index, pruned := range map[int64]bool{1: false, 2: true, 3: true, 4: true, 5: false} {
+		cached, err := c.Get(&RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(index)})
+		assert.NoError(t, err)
+		assert.Equal(t, pruned, cached == nil, index)
+	}
+	hash := cachedBlock(3).BlockIdentifier.Hash
+	cached, err := c.Get(&RosettaTypes.PartialBlockIdentifier{Hash: &hash})
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+}
+
+func TestBlockCacheConfigVersion(t *testing.T) {
+	opts := ClientOptions{
+		SupportedTokens:  map[string]bool{usdcAddress: true, daiAddress: true},
+		NFTContracts:     map[string]bool{},
+		ChainID:          big.NewInt(10),
+		GenesisBlockHash: "0x7ca38a1916c42007829c55e69d3e9a73265554b586a499015373241b8a3fa48b",
+		BedrockBlock:     big.NewInt(105235063),
+	}
+	version := blockCacheConfigVersion(opts)
+
+	// Token addresses and hashes are case insensitive and unsupported tokens are ignored
+	same := ClientOptions{
+		SupportedTokens:  map[string]bool{daiAddress: true, "0x7f5c764cbc14f9669b88837ca1490cca17c31607": true, opTokenContractAddress.String(): false},
+		ChainID:          big.NewInt(10),
+		GenesisBlockHash: "0x" + strings.ToUpper(opts.GenesisBlockHash[2:]),
+		BedrockBlock:     big.NewInt(105235063),
+	}
+	assert.Equal(t, version, blockCacheConfigVersion(same))
+
+	// Each of these options changes the version on its own
+	with := func(change func(*ClientOptions)) ClientOptions {
+		other := opts
+		change(&other)
+		return other
+	}
+	for name, other := range map[string]ClientOptions{
+		"chain id":      with(func(o *ClientOptions) { o.ChainID = big.NewInt(420) }),
+		"genesis hash":  with(func(o *ClientOptions) { o.GenesisBlockHash = "0x" + strings.Repeat("0", 64) }),
+		"bedrock block": with(func(o *ClientOptions) { o.BedrockBlock = big.NewInt(0) }),
+		"filter tokens": with(func(o *ClientOptions) { o.FilterTokens = true }),
+		"mint ops":      with(func(o *ClientOptions) { o.EnableMintOps = true }),
+		"nft ops":       with(func(o *ClientOptions) { o.EnableNFTOps = true }),
+		"bridge ops":    with(func(o *ClientOptions) { o.EnableBridgeOps = true }),
+		"tokens":        with(func(o *ClientOptions) { o.SupportedTokens = map[string]bool{usdcAddress: true} }),
+		"nft contracts": with(func(o *ClientOptions) { o.NFTContracts = map[string]bool{daiAddress: true} }),
+		"gpo owner":     with(func(o *ClientOptions) { o.GasPriceOracleOwner = daiAddress }),
+	} {
+		assert.NotEqual(t, version, blockCacheConfigVersion(other), name)
+	}
+}
+
+func TestBlock_Cached(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{
+		c:          mockJSONRPC,
+		blockCache: openTestBlockCache(t, t.TempDir(), 1<<20, "v1"),
+	}
+	mockFinalized := func(number int64) {
+		mockJSONRPC.On(
+			"CallContext", ctx, mock.Anything, "eth_getBlockByNumber", FinalizedInclusion, false,
+		).Return(nil).Run(func(args mock.Arguments) {
+			header := args.Get(1).(**rpcHeader)
+			*header = &rpcHeader{Header: EthTypes.Header{Number: big.NewInt(number)}}
+		}).Once()
+	}
+	isCached := func(index int64) bool {
+		cached, err := c.blockCache.Get(&RosettaTypes.PartialBlockIdentifier{Index: &index})
+		require.NoError(t, err)
+		return cached != nil
+	}
+
+	// Blocks above the finalized head are not cached
+	mockFinalized(10)
+	c.cacheFinalizedBlock(ctx, cachedBlock(11))
+	assert.False(t, isCached(11))
+
+	// Blocks at or below the last finalized head are cached without fetching it again
+	c.cacheFinalizedBlock(ctx, cachedBlock(10))
+	c.cacheFinalizedBlock(ctx, cachedBlock(9))
+	assert.True(t, isCached(10))
+	assert.True(t, isCached(9))
+
+	// The finalized head is not fetched again until the refresh interval has passed
+	c.cacheFinalizedBlock(ctx, cachedBlock(11))
+	assert.False(t, isCached(11))
+
+	// The finalized head is refreshed once it is behind the block
+	c.finalizedRefreshed.Store(time.Now().Add(-finalizedRefreshInterval).UnixNano())
+	mockFinalized(12)
+	c.cacheFinalizedBlock(ctx, cachedBlock(11))
+	assert.True(t, isCached(11))
+
+	// Cached blocks are returned without calling the node
+	block, err := c.Block(ctx, &RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(9)})
+	assert.NoError(t, err)
+	assert.Equal(t, cachedBlock(9), block)
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestBlockWithOtherTransactions_Cached(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{
+		c:                          mockJSONRPC,
+		blockCache:                 openTestBlockCache(t, t.TempDir(), 1<<20, "v1"),
+		otherTransactionsThreshold: 1,
+	}
+	withTransactions := func(index int64, count int) *RosettaTypes.Block {
+		block := cachedBlock(index)
+		for i := 0; i < count; i++ {
+			block.Transactions = append(block.Transactions, &RosettaTypes.Transaction{
+				TransactionIdentifier: &RosettaTypes.TransactionIdentifier{Hash: fmt.Sprintf("0x%064x", 100*index+int64(i))},
+				Operations:            []*RosettaTypes.Operation{},
+			})
+		}
+		return block
+	}
+	require.NoError(t, c.blockCache.Put(withTransactions(8, 1)))
+	require.NoError(t, c.blockCache.Put(withTransactions(9, 2)))
+
+	// Cached blocks within the threshold are returned populated without calling the node
+	block, otherTxs, err := c.BlockWithOtherTransactions(ctx, &RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(8)})
+	assert.NoError(t, err)
+	assert.Equal(t, withTransactions(8, 1), block)
+	assert.Nil(t, otherTxs)
+
+	// Cached blocks above the threshold are returned with the identifiers of their transactions
+	block, otherTxs, err = c.BlockWithOtherTransactions(ctx, &RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(9)})
+	assert.NoError(t, err)
+	assert.Equal(t, cachedBlock(9), block)
+	assert.Equal(t, []*RosettaTypes.TransactionIdentifier{
+		{Hash: fmt.Sprintf("0x%064x", 900)},
+		{Hash: fmt.Sprintf("0x%064x", 901)},
+	}, otherTxs)
+
+	// The cached block stays populated for [Client.Block]
+	block, err = c.Block(ctx, &RosettaTypes.PartialBlockIdentifier{Index: RosettaTypes.Int64(9)})
+	assert.NoError(t, err)
+	assert.Equal(t, withTransactions(9, 2), block)
+
+	mockJSONRPC.AssertExpectations(t)
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
@@ -32,11 +33,10 @@ import (
 	"github.com/ethereum-optimism/optimism/l2geth/params"
 	"github.com/ethereum-optimism/optimism/l2geth/rpc"
 
-	types2 "github.com/ethereum/go-ethereum/core/types"
-
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/inphi/optimism-rosetta/metrics"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -47,6 +47,10 @@ const (
 	defaultMaxTraceConcurrency = int64(1) // nolint:gomnd
 	semaphoreTraceWeight       = int64(1) // nolint:gomnd
 
+	defaultBalanceBatchSize = 100
+
+	defaultBlockCacheSize = int64(1 << 30) // 1 GiB
+
 	burnSelector          = "0x9dc29fac" // keccak(burn(address,uint256))
 	mintSelector          = "0x40c10f19" // keccak(mint(address,uint256))
 	erc20TransferSelector = "0xa9059cbb" // keccak(transfer(address,uint256))
@@ -68,7 +72,7 @@ const (
 var (
 	ovmEthAddr         = common.HexToAddress("0xdeaddeaddeaddeaddeaddeaddeaddeaddead0000")
 	gasPriceOracleAddr = common.HexToAddress("0x420000000000000000000000000000000000000f")
-	// TODO: load the gpo owner from a config
+	// Known gpo owners, used when the network config does not provide one
 	gasPriceOracleOwnerMainnet = common.HexToAddress("0x7107142636C85c549690b1Aca12Bdb8052d26Ae6")
 	gasPriceOracleOwnerKovan   = common.HexToAddress("0x84f70449f90300997840eCb0918873745Ede7aE6")
 	gasPriceOracleOwnerGoerli  = common.HexToAddress("0xa693B8f8207FF043F6bbC2E2120bbE4C2251Efe9")
@@ -113,16 +117,32 @@ type Client struct {
 	c JSONRPC
 	g GraphQL
 
-	currencyFetcher     CurrencyFetcher
-	traceSemaphore      *semaphore.Weighted
-	filterTokens        bool
-	supportedTokens     map[string]bool
-	supportsSyncing     bool
-	skipAdminCalls      bool
-	supportsPeering     bool
-	bedrockBlock        *big.Int
-	customBedrockTracer bool
-	traceByBlock        bool
+	currencyFetcher            CurrencyFetcher
+	traceSemaphore             *semaphore.Weighted
+	filterTokens               bool
+	supportedTokens            map[string]bool
+	supportsSyncing            bool
+	skipAdminCalls             bool
+	supportsPeering            bool
+	bedrockBlock               *big.Int
+	customBedrockTracer        bool
+	traceByBlock               bool
+	otherTransactionsThreshold int
+	gasPriceOracleOwner        *common.Address
+	enableMintOps              bool
+	enableNFTOps               bool
+	nftContracts               map[string]bool
+	enableBridgeOps            bool
+	submitters                 []*submitEndpoint
+	submitFanout               string
+	balanceBatchSize           int
+	multicall3                 *common.Address
+	tokenDiscovery             string
+	tokenDiscoveryBlockRange   uint64
+	blockCache                 *BlockCache
+	finalizedIndex             atomic.Int64
+	finalizedRefreshed         atomic.Int64
+	pendingFilter              pendingTxFilter
 }
 
 type ClientOptions struct {
@@ -139,6 +159,52 @@ type ClientOptions struct {
 	EnableCustomBedrockTracer bool
 	TraceByBlock              bool
 	TraceCacheSize            int
+	// TraceCacheBackend is where the trace cache stores traces: [MemoryTraceCacheBackend] (the default),
+	// [DiskTraceCacheBackend] or [RedisTraceCacheBackend].
+	TraceCacheBackend string
+	// TraceCachePath is the directory of the [DiskTraceCacheBackend].
+	TraceCachePath string
+	// TraceCacheRedisURL is the redis:// URL of the server of the [RedisTraceCacheBackend].
+	TraceCacheRedisURL string
+	// TraceCacheTTL is how long traces are kept by the [RedisTraceCacheBackend]. Zero never expires them.
+	TraceCacheTTL time.Duration
+	// OtherTransactionsThreshold is the number of transactions above which
+	// [Client.BlockWithOtherTransactions] returns transaction identifiers instead of populated transactions.
+	// Zero disables the threshold.
+	OtherTransactionsThreshold int
+	// GasPriceOracleOwner is the pre-bedrock gas price oracle owner, whose transactions are not charged a fee.
+	GasPriceOracleOwner string
+	// ChainID is the chain ID of the network, which versions the block cache along with the bedrock block.
+	ChainID *big.Int
+	// GenesisBlockHash is the hash of the genesis block of the network, which versions the block cache.
+	GenesisBlockHash string
+	// EnableMintOps emits a MINT operation for the minted amount of deposits (see [DepositOps])
+	// instead of crediting their value with a CALL.
+	EnableMintOps bool
+	// EnableNFTOps emits ERC721_TRANSFER and ERC1155_TRANSFER operations.
+	EnableNFTOps bool
+	// NFTContracts restricts NFT operations to the given lowercase contract addresses. All contracts are included if empty.
+	NFTContracts map[string]bool
+	// EnableBridgeOps gives the ERC20 mints and burns of the L2StandardBridge the
+	// ERC20_BRIDGE_MINT and ERC20_BRIDGE_BURN operation types.
+	EnableBridgeOps bool
+	// SubmitURLs are the endpoints that transactions are sent to instead of url, such as the sequencer.
+	SubmitURLs []string
+	// SubmitFanout is how transactions are sent to SubmitURLs: [FirstSuccessFanout] (default) or [AllFanout].
+	SubmitFanout string
+	// BalanceBatchSize is the maximum number of calls in a JSON-RPC batch sent by [Client.Balance].
+	BalanceBatchSize int
+	// Multicall3Address is the Multicall3 contract aggregating the ERC20 balanceOf calls of [Client.Balance].
+	Multicall3Address string
+	// TokenDiscovery is how [Client.Balance] discovers the tokens of an account when no currencies are requested:
+	// [SupportedTokensDiscovery] or [TransferLogsDiscovery]. Only ETH and the OP token are returned if empty.
+	TokenDiscovery string
+	// TokenDiscoveryBlockRange is the number of blocks whose transfer logs are scanned by [TransferLogsDiscovery].
+	TokenDiscoveryBlockRange uint64
+	// BlockCachePath is the directory of the on-disk cache of finalized blocks. Blocks are not cached if empty.
+	BlockCachePath string
+	// BlockCacheSize is the size in bytes above which the lowest blocks are evicted from the block cache.
+	BlockCacheSize int64
 }
 
 // NewClient creates a Client that from the provided url and params.
@@ -146,12 +212,13 @@ func NewClient(url string, params *params.ChainConfig, opts ClientOptions) (*Cli
 	if opts.HTTPTimeout == 0 {
 		opts.HTTPTimeout = defaultHTTPTimeout
 	}
-	c, err := rpc.DialHTTPWithClient(url, &http.Client{
+	rc, err := rpc.DialHTTPWithClient(url, &http.Client{
 		Timeout: opts.HTTPTimeout,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: unable to dial node", err)
 	}
+	c := instrumentJSONRPC(rc)
 
 	tspec := tracerSpec{
 		TracerPath:    defaultTracerPath,
@@ -184,34 +251,118 @@ func NewClient(url string, params *params.ChainConfig, opts ClientOptions) (*Cli
 		if traceCacheSize == 0 {
 			traceCacheSize = defaultCacheSize
 		}
-		log.Printf("using trace cache. cache_size=%d", traceCacheSize)
-		if traceCache, err = NewTraceCache(c, tspec, opts.HTTPTimeout, traceCacheSize); err != nil {
+		if opts.TraceCacheBackend == "" {
+			opts.TraceCacheBackend = MemoryTraceCacheBackend
+		}
+		var backend TraceCacheBackend
+		switch opts.TraceCacheBackend {
+		case MemoryTraceCacheBackend:
+		case DiskTraceCacheBackend:
+			backend, err = OpenDiskTraceCacheBackend(opts.TraceCachePath, traceCacheSize)
+		case RedisTraceCacheBackend:
+			backend, err = NewRedisTraceCacheBackend(opts.TraceCacheRedisURL, opts.TraceCacheTTL)
+		default:
+			err = fmt.Errorf("unknown trace cache backend %s", opts.TraceCacheBackend)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to create trace cache backend", err)
+		}
+		log.Printf("using trace cache. backend=%s cache_size=%d", opts.TraceCacheBackend, traceCacheSize)
+		if traceCache, err = NewTraceCache(c, tspec, opts.HTTPTimeout, traceCacheSize, backend); err != nil {
 			return nil, fmt.Errorf("%w: unable to create trace cache", err)
 		}
 	}
 
+	submitters := make([]*submitEndpoint, len(opts.SubmitURLs))
+	for i, submitURL := range opts.SubmitURLs {
+		sc, err := rpc.DialHTTPWithClient(submitURL, &http.Client{
+			Timeout: opts.HTTPTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to dial submission endpoint %s", err, redactURL(submitURL))
+		}
+		submitters[i] = &submitEndpoint{name: redactURL(submitURL), c: instrumentJSONRPC(sc)}
+	}
+	if len(submitters) > 0 {
+		log.Printf("submitting transactions to %d endpoints. fanout=%s", len(submitters), opts.SubmitFanout)
+	}
+
+	balanceBatchSize := opts.BalanceBatchSize
+	if balanceBatchSize == 0 {
+		balanceBatchSize = defaultBalanceBatchSize
+	}
+	var multicall3 *common.Address
+	if len(opts.Multicall3Address) > 0 {
+		address := common.HexToAddress(opts.Multicall3Address)
+		multicall3 = &address
+	}
+
+	var blockCache *BlockCache
+	if len(opts.BlockCachePath) > 0 {
+		if opts.BlockCacheSize == 0 {
+			opts.BlockCacheSize = defaultBlockCacheSize
+		}
+		if blockCache, err = OpenBlockCache(opts.BlockCachePath, opts.BlockCacheSize, blockCacheConfigVersion(opts)); err != nil {
+			return nil, err
+		}
+		log.Printf("using block cache. path=%s size=%d", opts.BlockCachePath, opts.BlockCacheSize)
+	}
+
+	var gasPriceOracleOwner *common.Address
+	if len(opts.GasPriceOracleOwner) > 0 {
+		owner := common.HexToAddress(opts.GasPriceOracleOwner)
+		gasPriceOracleOwner = &owner
+	}
+
 	return &Client{
-		p:                   params,
-		tc:                  tc,
-		c:                   c,
-		g:                   g,
-		currencyFetcher:     currencyFetcher,
-		traceSemaphore:      semaphore.NewWeighted(opts.MaxTraceConcurrency),
-		traceCache:          traceCache,
-		filterTokens:        opts.FilterTokens,
-		supportedTokens:     opts.SupportedTokens,
-		supportsSyncing:     opts.SuportsSyncing,
-		skipAdminCalls:      opts.SkipAdminCalls,
-		supportsPeering:     opts.SupportsPeering,
-		bedrockBlock:        opts.BedrockBlock,
-		customBedrockTracer: opts.EnableCustomBedrockTracer,
-		traceByBlock:        opts.TraceByBlock,
+		p:                          params,
+		tc:                         tc,
+		c:                          c,
+		g:                          g,
+		currencyFetcher:            currencyFetcher,
+		traceSemaphore:             semaphore.NewWeighted(opts.MaxTraceConcurrency),
+		traceCache:                 traceCache,
+		filterTokens:               opts.FilterTokens,
+		supportedTokens:            opts.SupportedTokens,
+		supportsSyncing:            opts.SuportsSyncing,
+		skipAdminCalls:             opts.SkipAdminCalls,
+		supportsPeering:            opts.SupportsPeering,
+		bedrockBlock:               opts.BedrockBlock,
+		customBedrockTracer:        opts.EnableCustomBedrockTracer,
+		traceByBlock:               opts.TraceByBlock,
+		otherTransactionsThreshold: opts.OtherTransactionsThreshold,
+		gasPriceOracleOwner:        gasPriceOracleOwner,
+		enableMintOps:              opts.EnableMintOps,
+		enableNFTOps:               opts.EnableNFTOps,
+		nftContracts:               opts.NFTContracts,
+		enableBridgeOps:            opts.EnableBridgeOps,
+		submitters:                 submitters,
+		submitFanout:               opts.SubmitFanout,
+		balanceBatchSize:           balanceBatchSize,
+		multicall3:                 multicall3,
+		tokenDiscovery:             opts.TokenDiscovery,
+		tokenDiscoveryBlockRange:   opts.TokenDiscoveryBlockRange,
+		blockCache:                 blockCache,
 	}, nil
 }
 
 // Close shuts down the RPC client connection.
 func (ec *Client) Close() {
+	ec.uninstallPendingFilter(context.Background())
 	ec.c.Close()
+	for _, submitter := range ec.submitters {
+		submitter.c.Close()
+	}
+	if ec.blockCache != nil {
+		if err := ec.blockCache.Close(); err != nil {
+			log.Printf("unable to close block cache: %v", err)
+		}
+	}
+	if ec.traceCache != nil {
+		if err := ec.traceCache.Close(); err != nil {
+			log.Printf("unable to close trace cache: %v", err)
+		}
+	}
 }
 
 // PendingNonceAt returns the account nonce of the given account in the pending state.
@@ -232,23 +383,11 @@ func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
-// SendTransaction injects a signed transaction into the pending pool for execution.
-//
-// If the transaction was a contract creation use the TransactionReceipt method to get the
-// contract address after the transaction has been mined.
-func (ec *Client) SendTransaction(ctx context.Context, tx *types2.Transaction) error {
-	data, err := tx.MarshalBinary()
-	if err != nil {
-		return err
-	}
-	return ec.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
-}
-
 func (ec *Client) getTransactionTraces(
 	ctx context.Context,
 	txs []rpcTransaction,
 ) ([]*Call, error) {
-	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+	if err := ec.acquireTraceSemaphore(ctx); err != nil {
 		return nil, err
 	}
 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
@@ -300,6 +439,7 @@ func (ec *Client) getBlockReceipts(
 			Result: &receipts[i],
 		}
 	}
+	metrics.ReceiptBatchSize.Observe(float64(len(reqs)))
 	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
 		return nil, err
 	}
@@ -1068,6 +1208,14 @@ func (ec *Client) Call(
 	return nil, fmt.Errorf("%w: %s", ErrCallMethodInvalid, request.Method)
 }
 
+// isGasPriceOracleOwner returns true if the address is the owner of the pre-bedrock gas price oracle.
+func (ec *Client) isGasPriceOracleOwner(address string) bool {
+	if ec.gasPriceOracleOwner != nil {
+		return address == ec.gasPriceOracleOwner.Hex()
+	}
+	return address == gasPriceOracleOwnerMainnet.Hex() || address == gasPriceOracleOwnerKovan.Hex() || address == gasPriceOracleOwnerGoerli.Hex()
+}
+
 func (ec *Client) supportsToken(contractAddress string) bool {
 	if !ec.filterTokens {
 		return true
@@ -0,0 +1,54 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// accessListResult is the result of eth_createAccessList
+type accessListResult struct {
+	AccessList *EthTypes.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64       `json:"gasUsed"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// CreateAccessList generates the access list of a transaction against the pending state,
+// along with the gas used by the transaction once the access list is applied.
+func (ec *Client) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (*EthTypes.AccessList, uint64, error) {
+	arg := toCallArg(msg).(map[string]interface{})
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+
+	var result accessListResult
+	if err := ec.c.CallContext(ctx, &result, "eth_createAccessList", arg, "pending"); err != nil {
+		return nil, 0, err
+	}
+	// The access list is still returned when the transaction reverts
+	if result.Error != "" {
+		return nil, 0, errors.New(result.Error)
+	}
+
+	return result.AccessList, uint64(result.GasUsed), nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ClientAccessListSuite tests [Client.CreateAccessList].
+type ClientAccessListSuite struct {
+	suite.Suite
+
+	mockJSONRPC *mocks.JSONRPC
+	client      *Client
+}
+
+// SetupTest sets up the test suite.
+func (testSuite *ClientAccessListSuite) SetupTest() {
+	testSuite.mockJSONRPC = &mocks.JSONRPC{}
+	testSuite.client = &Client{
+		c: testSuite.mockJSONRPC,
+	}
+}
+
+// TestClientAccessList runs the ClientAccessListSuite.
+func TestClientAccessList(t *testing.T) {
+	suite.Run(t, new(ClientAccessListSuite))
+}
+
+func (testSuite *ClientAccessListSuite) mockCreateAccessList(result accessListResult) {
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_createAccessList",
+		mock.MatchedBy(func(arg map[string]interface{}) bool {
+			return arg["maxFeePerGas"] != nil && arg["data"] != nil
+		}),
+		"pending",
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(*accessListResult) = result
+		},
+	).Once()
+}
+
+// TestCreateAccessList tests that access lists are generated against the pending state.
+func (testSuite *ClientAccessListSuite) TestCreateAccessList() {
+	ctx := context.Background()
+	to := common.HexToAddress("0xaD6D458402F60fD3Bd25163575031ACDce07538D")
+	msg := ethereum.CallMsg{
+		From:      common.HexToAddress("0xE550f300E477C60CE7e7172d12e5a27e9379D2e3"),
+		To:        &to,
+		Data:      common.FromHex("0x70a08231000000000000000000000000ae7e48ee0f758cd706b76cf7e2175d982800879a"),
+		GasFeeCap: hexutil.MustDecodeBig("0x12c"),
+		GasTipCap: hexutil.MustDecodeBig("0x14"),
+	}
+	accessList := &EthTypes.AccessList{
+		{
+			Address:     to,
+			StorageKeys: []common.Hash{common.HexToHash("0x01")},
+		},
+	}
+
+	testSuite.mockCreateAccessList(accessListResult{AccessList: accessList, GasUsed: 27500})
+	resp, gasUsed, err := testSuite.client.CreateAccessList(ctx, msg)
+	testSuite.NoError(err)
+	testSuite.Equal(accessList, resp)
+	testSuite.Equal(uint64(27500), gasUsed)
+
+	testSuite.mockCreateAccessList(accessListResult{AccessList: accessList, GasUsed: 27500, Error: "execution reverted"})
+	resp, _, err = testSuite.client.CreateAccessList(ctx, msg)
+	testSuite.EqualError(err, "execution reverted")
+	testSuite.Nil(resp)
+
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
@@ -17,20 +17,26 @@ package optimism
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math/big"
 	"reflect"
+	"strings"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
 	OptimismEth "github.com/ethereum-optimism/optimism/l2geth"
 	OptimismCommon "github.com/ethereum-optimism/optimism/l2geth/common"
 	OptimismHexUtil "github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
 	OptimismRpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthCommon "github.com/ethereum/go-ethereum/common"
 	OptimismArtifacts "github.com/inphi/optimism-rosetta/optimism/utilities/artifacts"
 )
 
 // Balance returns the balance of a *RosettaTypes.AccountIdentifier
 // at a *RosettaTypes.PartialBlockIdentifier.
-// The OP Token and ETH balances will be returned if currencies is unspecified
+// The OP Token and ETH balances will be returned if currencies is unspecified,
+// unless tokens are discovered (see [SupportedTokensDiscovery])
 //
 //nolint:gocognit
 func (ec *Client) Balance(
@@ -76,6 +82,16 @@ func (ec *Client) Balance(
 		return nil, err
 	}
 
+	// Discovered tokens are only reported when the account holds them
+	discovered := len(currencies) == 0 && len(ec.tokenDiscovery) > 0
+	if discovered {
+		var err error
+		currencies, err = ec.discoverCurrencies(ctx, account.Address, head.Number.ToInt().Uint64())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var (
 		balance OptimismHexUtil.Big
 		nonce   OptimismHexUtil.Uint64
@@ -88,21 +104,13 @@ func (ec *Client) Balance(
 		{Method: "eth_getTransactionCount", Args: []interface{}{account.Address, blockNum}, Result: &nonce},
 		{Method: "eth_getCode", Args: []interface{}{account.Address, blockNum}, Result: &code},
 	}
-	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
-		return nil, err
-	}
-	for i := range reqs {
-		if reqs[i].Error != nil {
-			return nil, reqs[i].Error
-		}
-	}
+	accountReqs := len(reqs)
 
-	nativeBalance := &RosettaTypes.Amount{
-		Value:    balance.ToInt().String(),
-		Currency: Currency,
-	}
+	nativeBalance := &RosettaTypes.Amount{Currency: Currency}
 
 	var balances []*RosettaTypes.Amount
+	var tokens []*tokenBalance
+	var nftCurrencies []*RosettaTypes.Amount
 	for _, curr := range currencies {
 		if reflect.DeepEqual(curr, Currency) {
 			balances = append(balances, nativeBalance)
@@ -115,25 +123,89 @@ func (ec *Client) Balance(
 			return nil, fmt.Errorf("invalid contract address %s", contractAddress)
 		}
 
-		balance, err := ec.getBalance(ctx, account.Address, blockNum, contractAddress)
-		if err != nil {
-			return nil, fmt.Errorf("err encountered for currency %s, token address %s; %v", curr.Symbol, contractAddress, err)
+		amount := &RosettaTypes.Amount{Currency: curr}
+		balances = append(balances, amount)
+		if _, ok := curr.Metadata[TokenIDKey]; ok {
+			nftCurrencies = append(nftCurrencies, amount)
+		} else {
+			tokens = append(tokens, &tokenBalance{amount: amount, contractAddress: contractAddress})
 		}
-		balances = append(balances, &RosettaTypes.Amount{
-			Value:    balance,
-			Currency: curr,
-		})
 	}
 
 	if len(currencies) == 0 {
-		opTokenBalance, err := ec.getBalance(ctx, account.Address, blockNum, opTokenContractAddress.String())
+		opTokenBalance := &RosettaTypes.Amount{Currency: OPTokenCurrency}
+		balances = append(balances, nativeBalance, opTokenBalance)
+		tokens = append(tokens, &tokenBalance{amount: opTokenBalance, contractAddress: opTokenContractAddress.String()})
+	}
+
+	// balanceOf only takes the account, so its calldata is the same for every token
+	balanceOfData, err := OptimismArtifacts.ERC20ABI.Pack("balanceOf", OptimismCommon.HexToAddress(account.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	// The balanceOf calls of every token are sent in the same batch as the native balance
+	var multicallResult string
+	useMulticall := ec.multicall3 != nil && len(tokens) > 1
+	if useMulticall {
+		multicallReq, err := ec.multicallBalanceOfElem(balanceOfData, blockNum, tokens, &multicallResult)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, multicallReq)
+	} else {
+		reqs = append(reqs, balanceOfElems(balanceOfData, blockNum, tokens)...)
+	}
+
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, stateError(err)
+	}
+	for i := range reqs[:accountReqs] {
+		if reqs[i].Error != nil {
+			return nil, stateError(reqs[i].Error)
+		}
+	}
+	nativeBalance.Value = balance.ToInt().String()
+
+	// Blocks that predate the deployment of Multicall3 fall back to a batch of balanceOf calls
+	if useMulticall && multicallResult == "0x" {
+		reqs = balanceOfElems(balanceOfData, blockNum, tokens)
+		if err := ec.batchCall(ctx, reqs); err != nil {
+			return nil, stateError(err)
+		}
+		useMulticall = false
+	}
+
+	if useMulticall {
+		if err := decodeMulticallBalances(reqs[len(reqs)-1], tokens); err != nil {
+			return nil, err
+		}
+	} else {
+		decodeBalanceOfElems(reqs[len(reqs)-len(tokens):], tokens)
+	}
+	for _, token := range tokens {
+		if token.err == nil {
+			continue
+		}
+		// Any contract emitting a Transfer event can be discovered, so the tokens whose balance
+		// cannot be fetched are skipped unless the state of the block is unavailable
+		if !discovered || errors.Is(token.err, ErrStateUnavailable) {
+			return nil, token.err
+		}
+		log.Printf("skipping discovered token %s: %v", token.contractAddress, token.err)
+	}
+
+	for _, amount := range nftCurrencies {
+		value, err := ec.nftBalance(ctx, account.Address, blockNum, amount.Currency)
 		if err != nil {
-			return nil, fmt.Errorf("err getting OP token balance; %v", err)
+			contractAddress := fmt.Sprintf("%s", amount.Currency.Metadata[ContractAddressKey])
+			return nil, fmt.Errorf("err encountered for currency %s, token address %s; %w", amount.Currency.Symbol, contractAddress, err)
 		}
-		balances = append(balances, nativeBalance, &RosettaTypes.Amount{
-			Value:    opTokenBalance,
-			Currency: OPTokenCurrency,
-		})
+		amount.Value = value
+	}
+
+	if discovered {
+		balances = nonZeroBalances(balances)
 	}
 
 	return &RosettaTypes.AccountBalanceResponse{
@@ -149,29 +221,174 @@ func (ec *Client) Balance(
 	}, nil
 }
 
-func (ec *Client) getBalance(ctx context.Context, accountAddress string, blockNum string, contractAddress string) (string, error) {
-	erc20Data, err := OptimismArtifacts.ERC20ABI.Pack("balanceOf", OptimismCommon.HexToAddress(accountAddress))
+// erc20BalanceLength is the length of the uint256 returned by balanceOf
+const erc20BalanceLength = 32
+
+// tokenBalance is the ERC20 balance of an account that is fetched with a balanceOf call
+type tokenBalance struct {
+	amount          *RosettaTypes.Amount
+	contractAddress string
+	result          string
+	// err is set if the balance of this token could not be fetched
+	err error
+}
+
+// multicall3Call is a call aggregated by Multicall3.aggregate3
+type multicall3Call struct {
+	Target       EthCommon.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result is the outcome of a call aggregated by Multicall3.aggregate3
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// batchCall sends reqs in batches of at most balanceBatchSize calls
+func (ec *Client) batchCall(ctx context.Context, reqs []OptimismRpc.BatchElem) error {
+	batchSize := ec.balanceBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBalanceBatchSize
+	}
+	for start := 0; start < len(reqs); start += batchSize {
+		end := start + batchSize
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		if err := ec.c.BatchCallContext(ctx, reqs[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// balanceOfElems returns an eth_call of balanceOf for each token
+func balanceOfElems(balanceOfData []byte, blockNum string, tokens []*tokenBalance) []OptimismRpc.BatchElem {
+	data := OptimismHexUtil.Encode(balanceOfData)
+	reqs := make([]OptimismRpc.BatchElem, len(tokens))
+	for i, token := range tokens {
+		callParams := map[string]string{
+			"to":   token.contractAddress,
+			"data": data,
+		}
+		reqs[i] = OptimismRpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{callParams, blockNum},
+			Result: &token.result,
+		}
+	}
+	return reqs
+}
+
+func decodeBalanceOfElems(reqs []OptimismRpc.BatchElem, tokens []*tokenBalance) {
+	for i, token := range tokens {
+		err := reqs[i].Error
+		if err == nil {
+			token.amount.Value, err = decodeBalance(token.result)
+		}
+		if err != nil {
+			token.err = fmt.Errorf(
+				"err encountered for currency %s, token address %s; %w",
+				token.amount.Currency.Symbol, token.contractAddress, stateError(err),
+			)
+		}
+	}
+}
+
+// decodeBalance decodes the result of a balanceOf eth_call. Pruned state is reported
+// by the node as an error (see [stateError]), not as an empty result.
+func decodeBalance(result string) (string, error) {
+	// "0x" is returned when the token has no code at this block, such as before it was deployed
+	if result == "0x" {
+		return "0", nil
+	}
+	balance, err := decodeHexData(result)
 	if err != nil {
 		return "", err
 	}
-	encodedERC20Data := OptimismHexUtil.Encode(erc20Data)
+	return balance.String(), nil
+}
+
+// multicallBalanceOfElem returns an eth_call that aggregates the balanceOf calls of every token with Multicall3
+func (ec *Client) multicallBalanceOfElem(
+	balanceOfData []byte,
+	blockNum string,
+	tokens []*tokenBalance,
+	result *string,
+) (OptimismRpc.BatchElem, error) {
+	calls := make([]multicall3Call, len(tokens))
+	for i, token := range tokens {
+		calls[i] = multicall3Call{
+			Target:       EthCommon.HexToAddress(token.contractAddress),
+			AllowFailure: true,
+			CallData:     balanceOfData,
+		}
+	}
+	multicallData, err := OptimismArtifacts.Multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		return OptimismRpc.BatchElem{}, err
+	}
 
 	callParams := map[string]string{
-		"to":   contractAddress,
-		"data": encodedERC20Data,
+		"to":   ec.multicall3.Hex(),
+		"data": OptimismHexUtil.Encode(multicallData),
 	}
-	var resp string
-	if err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, blockNum); err != nil {
-		return "", err
+	return OptimismRpc.BatchElem{
+		Method: "eth_call",
+		Args:   []interface{}{callParams, blockNum},
+		Result: result,
+	}, nil
+}
+
+func decodeMulticallBalances(req OptimismRpc.BatchElem, tokens []*tokenBalance) error {
+	if req.Error != nil {
+		return fmt.Errorf("err encountered for multicall of %d token balances; %w", len(tokens), stateError(req.Error))
 	}
-	// "0x" may be returned when retrieving balances of historical state that have been pruned by non-archival nodes
-	if resp == "0x" {
-		return "0", nil
+	returnData, err := OptimismHexUtil.Decode(*req.Result.(*string))
+	if err != nil {
+		return err
 	}
-	balance, err := decodeHexData(resp)
+	values, err := OptimismArtifacts.Multicall3ABI.Unpack("aggregate3", returnData)
 	if err != nil {
-		return "", err
+		return err
+	}
+	var results []multicall3Result
+	if err := OptimismArtifacts.Multicall3ABI.Methods["aggregate3"].Outputs.Copy(&results, values); err != nil {
+		return err
+	}
+	if len(results) != len(tokens) {
+		return fmt.Errorf("multicall returned %d results for %d token balances", len(results), len(tokens))
 	}
 
-	return balance.String(), nil
+	for i, token := range tokens {
+		switch {
+		case !results[i].Success:
+			token.err = fmt.Errorf(
+				"err encountered for currency %s, token address %s; balanceOf reverted",
+				token.amount.Currency.Symbol, token.contractAddress,
+			)
+		case len(results[i].ReturnData) == 0:
+			// The aggregated calls did execute, so the token has no code at this block
+			token.amount.Value = "0"
+		case len(results[i].ReturnData) < erc20BalanceLength:
+			token.err = fmt.Errorf(
+				"err encountered for currency %s, token address %s; malformed balance %x",
+				token.amount.Currency.Symbol, token.contractAddress, results[i].ReturnData,
+			)
+		default:
+			token.amount.Value = new(big.Int).SetBytes(results[i].ReturnData[:erc20BalanceLength]).String()
+		}
+	}
+	return nil
+}
+
+// stateError wraps the errors returned by nodes for state that has been pruned with ErrStateUnavailable
+func stateError(err error) error {
+	msg := err.Error()
+	if strings.Contains(msg, "missing trie node") || strings.Contains(msg, "historical state") {
+		return fmt.Errorf("%w: %s", ErrStateUnavailable, msg)
+	}
+	return err
 }
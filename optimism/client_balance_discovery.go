@@ -0,0 +1,144 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	Eth "github.com/ethereum/go-ethereum"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// SupportedTokensDiscovery returns the balances of every supported token
+	// when no currencies are requested.
+	SupportedTokensDiscovery = "SUPPORTED_TOKENS"
+
+	// TransferLogsDiscovery also returns the balances of the tokens the account
+	// sent or received within the token discovery block range.
+	TransferLogsDiscovery = "TRANSFER_LOGS"
+
+	defaultTokenDiscoveryBlockRange = uint64(10000)
+)
+
+// discoverCurrencies returns the currencies whose balances are returned for an
+// account when none are requested: the native currency followed by the
+// discovered tokens, sorted by contract address.
+func (ec *Client) discoverCurrencies(
+	ctx context.Context,
+	account string,
+	blockNum uint64,
+) ([]*RosettaTypes.Currency, error) {
+	contracts := make(map[string]bool, len(ec.supportedTokens))
+	for contract, supported := range ec.supportedTokens {
+		if supported {
+			contracts[strings.ToLower(contract)] = true
+		}
+	}
+
+	if ec.tokenDiscovery == TransferLogsDiscovery {
+		transferred, err := ec.transferredTokens(ctx, account, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to discover tokens from transfer logs", err)
+		}
+		for _, contract := range transferred {
+			contracts[contract] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(contracts))
+	for contract := range contracts {
+		sorted = append(sorted, contract)
+	}
+	sort.Strings(sorted)
+
+	currencies := []*RosettaTypes.Currency{Currency}
+	for _, contract := range sorted {
+		checksumContract, ok := ChecksumAddress(contract)
+		if !ok {
+			return nil, fmt.Errorf("invalid contract address %s", contract)
+		}
+		currency, err := ec.currencyFetcher.FetchCurrency(ctx, blockNum, checksumContract)
+		if err != nil {
+			// Any contract emitting a Transfer event can be discovered, including ones that are not tokens
+			log.Printf("skipping discovered token %s: unable to fetch currency: %v", checksumContract, err)
+			continue
+		}
+		currencies = append(currencies, currency)
+	}
+
+	return currencies, nil
+}
+
+// transferredTokens returns the lowercase addresses of the ERC20 tokens that
+// account sent or received within the token discovery block range ending at blockNum
+func (ec *Client) transferredTokens(ctx context.Context, account string, blockNum uint64) ([]string, error) {
+	blockRange := ec.tokenDiscoveryBlockRange
+	if blockRange == 0 {
+		blockRange = defaultTokenDiscoveryBlockRange
+	}
+	var fromBlock uint64
+	if blockNum >= blockRange {
+		fromBlock = blockNum - blockRange + 1
+	}
+
+	transferTopic := crypto.Keccak256Hash([]byte(erc20TransferEventLogTopics))
+	accountTopic := EthCommon.BytesToHash(EthCommon.HexToAddress(account).Bytes())
+	// The account is either the sender or the recipient of a transfer
+	queries := [][][]EthCommon.Hash{
+		{{transferTopic}, {accountTopic}},
+		{{transferTopic}, nil, {accountTopic}},
+	}
+
+	var tokens []string
+	for _, topics := range queries {
+		logs, err := ec.FilterLogs(ctx, Eth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(fromBlock),
+			ToBlock:   new(big.Int).SetUint64(blockNum),
+			Topics:    topics,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range logs {
+			// ERC721 transfers share the event signature, but index the token ID
+			if len(logs[i].Topics) != numTopicsERC20Transfer {
+				continue
+			}
+			tokens = append(tokens, strings.ToLower(logs[i].Address.Hex()))
+		}
+	}
+
+	return tokens, nil
+}
+
+// nonZeroBalances removes the tokens without a balance, or whose balance could not be
+// fetched, from discovered balances. The balance of the native currency is always kept.
+func nonZeroBalances(balances []*RosettaTypes.Amount) []*RosettaTypes.Amount {
+	filtered := balances[:0]
+	for _, balance := range balances {
+		if balance.Currency == Currency || (balance.Value != "0" && balance.Value != "") {
+			filtered = append(filtered, balance)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,266 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	usdcAddress = "0x7F5c764cBc14f9669B88837ca1490cCa17c31607"
+	daiAddress  = "0xDA10009cBd5D07dd0CeCc66161FC93D7c9000da1"
+)
+
+var daiCurrency = &RosettaTypes.Currency{
+	Symbol:   "DAI",
+	Decimals: 18,
+	Metadata: map[string]interface{}{ContractAddressKey: daiAddress},
+}
+
+// mockDiscoveredCurrencies mocks the currency lookups of discovered tokens.
+func (testSuite *ClientBalanceSuite) mockDiscoveredCurrencies(ctx context.Context, currencies ...*RosettaTypes.Currency) {
+	mockCurrencyFetcher := &mocks.CurrencyFetcher{}
+	for _, currency := range currencies {
+		mockCurrencyFetcher.On(
+			"FetchCurrency", ctx, uint64(10992), currency.Metadata[ContractAddressKey],
+		).Return(currency, nil).Once()
+	}
+	testSuite.client.currencyFetcher = mockCurrencyFetcher
+}
+
+// TestBalanceSupportedTokens tests that the supported tokens held by an account are discovered.
+func (testSuite *ClientBalanceSuite) TestBalanceSupportedTokens() {
+	ctx := context.Background()
+	testSuite.client.tokenDiscovery = SupportedTokensDiscovery
+	testSuite.client.supportedTokens = map[string]bool{
+		strings.ToLower(opTokenContractAddress.String()): true,
+		strings.ToLower(usdcAddress):                     true,
+		strings.ToLower(daiAddress):                      true,
+	}
+	testSuite.mockDiscoveredCurrencies(ctx, opCurrency, usdcCurrency, daiCurrency)
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 6 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				opTokenContractAddress.String(): balanceHex(20),
+				usdcAddress:                     balanceHex(0),
+				daiAddress:                      balanceHex(30),
+			})
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, nil)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Amount{
+		{Value: "1000", Currency: Currency},
+		{Value: "20", Currency: opCurrency},
+		{Value: "30", Currency: daiCurrency},
+	}, resp.Balances)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceTransferLogTokens tests that tokens are discovered from the Transfer logs of an account.
+func (testSuite *ClientBalanceSuite) TestBalanceTransferLogTokens() {
+	ctx := context.Background()
+	testSuite.client.tokenDiscovery = TransferLogsDiscovery
+	testSuite.client.tokenDiscoveryBlockRange = 100
+	testSuite.client.supportedTokens = map[string]bool{
+		strings.ToLower(opTokenContractAddress.String()): true,
+	}
+	testSuite.mockDiscoveredCurrencies(ctx, opCurrency, usdcCurrency)
+	testSuite.mockLatestBlock(ctx)
+
+	transferTopic := crypto.Keccak256Hash([]byte(erc20TransferEventLogTopics))
+	accountTopic := EthCommon.BytesToHash(EthCommon.HexToAddress(account).Bytes())
+	otherTopic := EthCommon.HexToHash("0x01")
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getLogs",
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			arg := args.Get(3).(map[string]interface{})
+			testSuite.Equal("0x2a8d", arg["fromBlock"])
+			testSuite.Equal("0x2af0", arg["toBlock"])
+			topics := arg["topics"].([][]EthCommon.Hash)
+			if len(topics) == 2 {
+				// Received USDC
+				*args.Get(1).(*[]EthTypes.Log) = []EthTypes.Log{{
+					Address: EthCommon.HexToAddress(usdcAddress),
+					Topics:  []EthCommon.Hash{transferTopic, otherTopic, accountTopic},
+				}}
+			} else {
+				// Sent an ERC721 token
+				*args.Get(1).(*[]EthTypes.Log) = []EthTypes.Log{{
+					Address: EthCommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+					Topics:  []EthCommon.Hash{transferTopic, accountTopic, otherTopic, otherTopic},
+				}}
+			}
+		},
+	).Twice()
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 5 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				opTokenContractAddress.String(): balanceHex(0),
+				usdcAddress:                     balanceHex(30),
+			})
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, nil)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Amount{
+		{Value: "1000", Currency: Currency},
+		{Value: "30", Currency: usdcCurrency},
+	}, resp.Balances)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceTransferLogUnfetchableTokens tests that discovered tokens whose currency or balance
+// cannot be fetched, such as contracts emitting fake Transfer events, are skipped.
+func (testSuite *ClientBalanceSuite) TestBalanceTransferLogUnfetchableTokens() {
+	ctx := context.Background()
+	testSuite.client.tokenDiscovery = TransferLogsDiscovery
+	testSuite.client.supportedTokens = map[string]bool{
+		strings.ToLower(opTokenContractAddress.String()): true,
+	}
+	fakeAddress := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	mockCurrencyFetcher := &mocks.CurrencyFetcher{}
+	for _, currency := range []*RosettaTypes.Currency{opCurrency, usdcCurrency} {
+		mockCurrencyFetcher.On(
+			"FetchCurrency", ctx, uint64(10992), currency.Metadata[ContractAddressKey],
+		).Return(currency, nil).Once()
+	}
+	mockCurrencyFetcher.On(
+		"FetchCurrency", ctx, uint64(10992), fakeAddress,
+	).Return(nil, errors.New("execution reverted")).Once()
+	testSuite.client.currencyFetcher = mockCurrencyFetcher
+	testSuite.mockLatestBlock(ctx)
+
+	transferTopic := crypto.Keccak256Hash([]byte(erc20TransferEventLogTopics))
+	accountTopic := EthCommon.BytesToHash(EthCommon.HexToAddress(account).Bytes())
+	otherTopic := EthCommon.HexToHash("0x01")
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getLogs",
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(*[]EthTypes.Log) = []EthTypes.Log{
+				{
+					Address: EthCommon.HexToAddress(fakeAddress),
+					Topics:  []EthCommon.Hash{transferTopic, otherTopic, accountTopic},
+				},
+				{
+					Address: EthCommon.HexToAddress(usdcAddress),
+					Topics:  []EthCommon.Hash{transferTopic, otherTopic, accountTopic},
+				},
+			}
+		},
+	).Twice()
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 5 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			fillBalanceBatch(r, map[string]string{
+				opTokenContractAddress.String(): balanceHex(20),
+			})
+			for i := range r {
+				if r[i].Method == "eth_call" && r[i].Args[0].(map[string]string)["to"] == usdcAddress {
+					r[i].Error = errors.New("execution reverted")
+				}
+			}
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, nil)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Amount{
+		{Value: "1000", Currency: Currency},
+		{Value: "20", Currency: opCurrency},
+	}, resp.Balances)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+	mockCurrencyFetcher.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceSupportedTokensUndeployed tests that supported tokens without code at the block are omitted.
+func (testSuite *ClientBalanceSuite) TestBalanceSupportedTokensUndeployed() {
+	ctx := context.Background()
+	testSuite.client.tokenDiscovery = SupportedTokensDiscovery
+	testSuite.client.supportedTokens = map[string]bool{
+		strings.ToLower(opTokenContractAddress.String()): true,
+		strings.ToLower(usdcAddress):                     true,
+	}
+	testSuite.mockDiscoveredCurrencies(ctx, opCurrency, usdcCurrency)
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 5 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				opTokenContractAddress.String(): balanceHex(20),
+				usdcAddress:                     "0x",
+			})
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, nil)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Amount{
+		{Value: "1000", Currency: Currency},
+		{Value: "20", Currency: opCurrency},
+	}, resp.Balances)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
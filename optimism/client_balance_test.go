@@ -17,7 +17,9 @@ package optimism
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 	"testing"
@@ -26,6 +28,7 @@ import (
 	"github.com/ethereum-optimism/optimism/l2geth/common"
 	"github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
 	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthCommon "github.com/ethereum/go-ethereum/common"
 	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
 	"github.com/inphi/optimism-rosetta/optimism/utilities/artifacts"
 
@@ -62,36 +65,17 @@ func TestBalanceSuite(t *testing.T) {
 	suite.Run(t, new(ClientBalanceSuite))
 }
 
-// TestBalance tests [Client.Balance].
-func (testSuite *ClientBalanceSuite) TestBalance() {
-	ctx := context.Background()
-
-	testSuite.mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"eth_getBlockByNumber",
-		"latest",
-		false,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*json.RawMessage)
-
-			file, err := os.ReadFile("testdata/block_10992.json")
-			testSuite.NoError(err)
-
-			*r = json.RawMessage(file)
-		},
-	).Once()
+// mockBalanceBatch mocks the batch fetching the native balance, nonce, code and OP token balance of account.
+func (testSuite *ClientBalanceSuite) mockBalanceBatch(ctx context.Context, blockNum string) {
+	callData, err := artifacts.ERC20ABI.Pack("balanceOf", common.HexToAddress(account))
+	testSuite.NoError(err)
 
-	blockNum := fmt.Sprintf("0x%s", strconv.FormatInt(10992, 16))
 	testSuite.mockJSONRPC.On(
 		"BatchCallContext",
 		ctx,
 		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool {
-			return len(rpcs) == 3 && rpcs[0].Method == "eth_getBalance" && rpcs[1].Method == "eth_getTransactionCount" && rpcs[2].Method == "eth_getCode"
+			return len(rpcs) == 4 && rpcs[0].Method == "eth_getBalance" && rpcs[1].Method == "eth_getTransactionCount" &&
+				rpcs[2].Method == "eth_getCode" && rpcs[3].Method == "eth_call"
 		}),
 	).Return(
 		nil,
@@ -99,48 +83,61 @@ func (testSuite *ClientBalanceSuite) TestBalance() {
 		func(args mock.Arguments) {
 			r := args.Get(1).([]rpc.BatchElem)
 
-			testSuite.Len(r, 3)
-			for i := range r {
+			testSuite.Len(r, 4)
+			for i := range r[:3] {
 				testSuite.Len(r[i].Args, 2)
 				testSuite.Equal(r[i].Args[0], account)
 				testSuite.Equal(r[i].Args[1], blockNum)
 			}
+			testSuite.Equal([]interface{}{
+				map[string]string{
+					"data": fmt.Sprintf("0x%s", common.Bytes2Hex(callData)),
+					"to":   opTokenContractAddress.String(),
+				},
+				blockNum,
+			}, r[3].Args)
 
 			balance := hexutil.MustDecodeBig("0x2324c0d180077fe7000")
 			*(r[0].Result.(*hexutil.Big)) = (hexutil.Big)(*balance)
 			*(r[1].Result.(*hexutil.Uint64)) = hexutil.Uint64(0)
 			*(r[2].Result.(*string)) = "0x"
+
+			var expected map[string]interface{}
+			file, err := os.ReadFile("testdata/call_balance_token_10992.json")
+			testSuite.NoError(err)
+			testSuite.NoError(json.Unmarshal(file, &expected))
+			*(r[3].Result.(*string)) = expected["data"].(string)
 		},
 	).Once()
+}
+
+// TestBalance tests [Client.Balance].
+func (testSuite *ClientBalanceSuite) TestBalance() {
+	ctx := context.Background()
 
-	callData, err := artifacts.ERC20ABI.Pack("balanceOf", common.HexToAddress(account))
-	testSuite.NoError(err)
 	testSuite.mockJSONRPC.On(
 		"CallContext",
 		ctx,
 		mock.Anything,
-		"eth_call",
-		map[string]string{
-			"data": fmt.Sprintf("0x%s", common.Bytes2Hex(callData)),
-			"to":   opTokenContractAddress.String(),
-		},
-		blockNum,
+		"eth_getBlockByNumber",
+		"latest",
+		false,
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
-			r := args.Get(1).(*string)
-			var expected map[string]interface{}
-			file, err := os.ReadFile("testdata/call_balance_token_10992.json")
-			testSuite.NoError(err)
+			r := args.Get(1).(*json.RawMessage)
 
-			err = json.Unmarshal(file, &expected)
+			file, err := os.ReadFile("testdata/block_10992.json")
 			testSuite.NoError(err)
 
-			*r = expected["data"].(string)
+			*r = json.RawMessage(file)
 		},
 	).Once()
 
+	blockNum := fmt.Sprintf("0x%s", strconv.FormatInt(10992, 16))
+	testSuite.mockBalanceBatch(ctx, blockNum)
+
 	resp, err := testSuite.client.Balance(
 		ctx,
 		&RosettaTypes.AccountIdentifier{
@@ -196,59 +193,7 @@ func (testSuite *ClientBalanceSuite) TestBalanceHistorical_Hash() {
 			*r = json.RawMessage(file)
 		},
 	).Once()
-	testSuite.mockJSONRPC.On(
-		"BatchCallContext",
-		ctx,
-		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool {
-			return len(rpcs) == 3 && rpcs[0].Method == "eth_getBalance" && rpcs[1].Method == "eth_getTransactionCount" && rpcs[2].Method == "eth_getCode"
-		}),
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).([]rpc.BatchElem)
-
-			testSuite.Len(r, 3)
-			for i := range r {
-				testSuite.Len(r[i].Args, 2)
-				testSuite.Equal(r[i].Args[0], account)
-				testSuite.Equal(r[i].Args[1], blockNum)
-			}
-
-			balance := hexutil.MustDecodeBig("0x2324c0d180077fe7000")
-			*(r[0].Result.(*hexutil.Big)) = (hexutil.Big)(*balance)
-			*(r[1].Result.(*hexutil.Uint64)) = hexutil.Uint64(0)
-			*(r[2].Result.(*string)) = "0x"
-		},
-	).Once()
-
-	callData, err := artifacts.ERC20ABI.Pack("balanceOf", common.HexToAddress(account))
-	testSuite.NoError(err)
-	testSuite.mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"eth_call",
-		map[string]string{
-			"data": fmt.Sprintf("0x%s", common.Bytes2Hex(callData)),
-			"to":   opTokenContractAddress.String(),
-		},
-		blockNum,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*string)
-			var expected map[string]interface{}
-			file, err := os.ReadFile("testdata/call_balance_token_10992.json")
-			testSuite.NoError(err)
-
-			err = json.Unmarshal(file, &expected)
-			testSuite.NoError(err)
-
-			*r = expected["data"].(string)
-		},
-	).Once()
+	testSuite.mockBalanceBatch(ctx, blockNum)
 
 	resp, err := testSuite.client.Balance(
 		ctx,
@@ -307,59 +252,7 @@ func (testSuite *ClientBalanceSuite) TestBalanceHistorical_Index() {
 			*r = json.RawMessage(file)
 		},
 	).Once()
-	testSuite.mockJSONRPC.On(
-		"BatchCallContext",
-		ctx,
-		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool {
-			return len(rpcs) == 3 && rpcs[0].Method == "eth_getBalance" && rpcs[1].Method == "eth_getTransactionCount" && rpcs[2].Method == "eth_getCode"
-		}),
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).([]rpc.BatchElem)
-
-			testSuite.Len(r, 3)
-			for i := range r {
-				testSuite.Len(r[i].Args, 2)
-				testSuite.Equal(r[i].Args[0], account)
-				testSuite.Equal(r[i].Args[1], blockNum)
-			}
-
-			balance := hexutil.MustDecodeBig("0x2324c0d180077fe7000")
-			*(r[0].Result.(*hexutil.Big)) = (hexutil.Big)(*balance)
-			*(r[1].Result.(*hexutil.Uint64)) = hexutil.Uint64(0)
-			*(r[2].Result.(*string)) = "0x"
-		},
-	).Once()
-
-	callData, err := artifacts.ERC20ABI.Pack("balanceOf", common.HexToAddress(account))
-	testSuite.NoError(err)
-	testSuite.mockJSONRPC.On(
-		"CallContext",
-		ctx,
-		mock.Anything,
-		"eth_call",
-		map[string]string{
-			"data": fmt.Sprintf("0x%s", common.Bytes2Hex(callData)),
-			"to":   opTokenContractAddress.String(),
-		},
-		blockNum,
-	).Return(
-		nil,
-	).Run(
-		func(args mock.Arguments) {
-			r := args.Get(1).(*string)
-			var expected map[string]interface{}
-			file, err := os.ReadFile("testdata/call_balance_token_10992.json")
-			testSuite.NoError(err)
-
-			err = json.Unmarshal(file, &expected)
-			testSuite.NoError(err)
-
-			*r = expected["data"].(string)
-		},
-	).Once()
+	testSuite.mockBalanceBatch(ctx, blockNum)
 
 	resp, err := testSuite.client.Balance(
 		ctx,
@@ -420,14 +313,14 @@ func (testSuite *ClientBalanceSuite) TestBalanceInvalidAddress() {
 		"BatchCallContext",
 		ctx,
 		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool {
-			return len(rpcs) == 3 && rpcs[0].Method == "eth_getBalance" && rpcs[1].Method == "eth_getTransactionCount" && rpcs[2].Method == "eth_getCode"
+			return len(rpcs) == 4 && rpcs[0].Method == "eth_getBalance" && rpcs[1].Method == "eth_getTransactionCount" && rpcs[2].Method == "eth_getCode"
 		}),
 	).Return(
 		nil,
 	).Run(
 		func(args mock.Arguments) {
 			r := args.Get(1).([]rpc.BatchElem)
-			testSuite.Len(r, 3)
+			testSuite.Len(r, 4)
 			r[0].Error = fmt.Errorf("invalid argument 0")
 		},
 	).Once()
@@ -479,3 +372,313 @@ func (testSuite *ClientBalanceSuite) TestBalanceInvalidHash() {
 	testSuite.Nil(resp)
 	testSuite.Error(err)
 }
+
+var opCurrency = &RosettaTypes.Currency{
+	Symbol:   TokenSymbol,
+	Decimals: TokenDecimals,
+	Metadata: map[string]interface{}{ContractAddressKey: opTokenContractAddress.String()},
+}
+
+var usdcCurrency = &RosettaTypes.Currency{
+	Symbol:   "USDC",
+	Decimals: 6,
+	Metadata: map[string]interface{}{ContractAddressKey: usdcAddress},
+}
+
+// mockLatestBlock mocks the lookup of the latest block, 10992.
+func (testSuite *ClientBalanceSuite) mockLatestBlock(ctx context.Context) {
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"latest",
+		false,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			file, err := os.ReadFile("testdata/block_10992.json")
+			testSuite.NoError(err)
+			*args.Get(1).(*json.RawMessage) = json.RawMessage(file)
+		},
+	).Once()
+}
+
+// fillBalanceBatch sets the results of a balance batch, with the eth_calls answered by contract address.
+func fillBalanceBatch(r []rpc.BatchElem, calls map[string]string) {
+	for i := range r {
+		switch r[i].Method {
+		case "eth_getBalance":
+			*(r[i].Result.(*hexutil.Big)) = (hexutil.Big)(*big.NewInt(1000))
+		case "eth_getTransactionCount":
+			*(r[i].Result.(*hexutil.Uint64)) = hexutil.Uint64(3)
+		case "eth_getCode":
+			*(r[i].Result.(*string)) = "0x"
+		case "eth_call":
+			to := r[i].Args[0].(map[string]string)["to"]
+			*(r[i].Result.(*string)) = calls[to]
+		}
+	}
+}
+
+func balanceHex(value int64) string {
+	return hexutil.Encode(common.LeftPadBytes(big.NewInt(value).Bytes(), 32))
+}
+
+// TestBalanceTokens tests that the balanceOf calls of every token are batched with the native balance.
+func (testSuite *ClientBalanceSuite) TestBalanceTokens() {
+	ctx := context.Background()
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 5 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				opTokenContractAddress.String(): balanceHex(20),
+				usdcAddress:                     balanceHex(30),
+			})
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{Address: account},
+		nil,
+		[]*RosettaTypes.Currency{opCurrency, Currency, usdcCurrency},
+	)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Amount{
+		{Value: "20", Currency: opCurrency},
+		{Value: "1000", Currency: Currency},
+		{Value: "30", Currency: usdcCurrency},
+	}, resp.Balances)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceBatchSize tests that batches are split at the batch size.
+func (testSuite *ClientBalanceSuite) TestBalanceBatchSize() {
+	ctx := context.Background()
+	testSuite.client.balanceBatchSize = 2
+	calls := map[string]string{
+		opTokenContractAddress.String(): balanceHex(20),
+		usdcAddress:                     balanceHex(30),
+	}
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 2 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) { fillBalanceBatch(args.Get(1).([]rpc.BatchElem), calls) },
+	).Twice()
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 1 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) { fillBalanceBatch(args.Get(1).([]rpc.BatchElem), calls) },
+	).Once()
+
+	resp, err := testSuite.client.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{Address: account},
+		nil,
+		[]*RosettaTypes.Currency{opCurrency, usdcCurrency},
+	)
+	testSuite.NoError(err)
+	testSuite.Equal("20", resp.Balances[0].Value)
+	testSuite.Equal("30", resp.Balances[1].Value)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceStateUnavailable tests that a balanceOf call on pruned state is reported instead of a zero balance.
+func (testSuite *ClientBalanceSuite) TestBalanceStateUnavailable() {
+	ctx := context.Background()
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).([]rpc.BatchElem)
+			fillBalanceBatch(r, nil)
+			r[3].Error = errors.New("historical state 1a2b is not available")
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, nil)
+	testSuite.Nil(resp)
+	testSuite.ErrorIs(err, ErrStateUnavailable)
+}
+
+// TestBalanceTokenWithoutCode tests that tokens without code at the block, such as before
+// their deployment, have a zero balance whether or not their balances are aggregated with Multicall3.
+func (testSuite *ClientBalanceSuite) TestBalanceTokenWithoutCode() {
+	ctx := context.Background()
+	currencies := []*RosettaTypes.Currency{opCurrency, usdcCurrency}
+
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 5 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				opTokenContractAddress.String(): balanceHex(20),
+				usdcAddress:                     "0x",
+			})
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, currencies)
+	testSuite.NoError(err)
+	testSuite.Equal("20", resp.Balances[0].Value)
+	testSuite.Equal("0", resp.Balances[1].Value)
+
+	multicall3 := EthCommon.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	testSuite.client.multicall3 = &multicall3
+	returnData, err := artifacts.Multicall3ABI.Methods["aggregate3"].Outputs.Pack([]multicall3Result{
+		{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(20).Bytes(), 32)},
+		{Success: true, ReturnData: []byte{}},
+	})
+	testSuite.NoError(err)
+
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 4 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				multicall3.Hex(): hexutil.Encode(returnData),
+			})
+		},
+	).Once()
+
+	resp, err = testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, currencies)
+	testSuite.NoError(err)
+	testSuite.Equal("20", resp.Balances[0].Value)
+	testSuite.Equal("0", resp.Balances[1].Value)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceMissingTrieNode tests that node errors for pruned state are reported as unavailable state.
+func (testSuite *ClientBalanceSuite) TestBalanceMissingTrieNode() {
+	ctx := context.Background()
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			args.Get(1).([]rpc.BatchElem)[0].Error = errors.New("missing trie node 1a2b (path )")
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(ctx, &RosettaTypes.AccountIdentifier{Address: account}, nil, nil)
+	testSuite.Nil(resp)
+	testSuite.ErrorIs(err, ErrStateUnavailable)
+}
+
+// TestBalanceMulticall tests that the balanceOf calls are aggregated with Multicall3.
+func (testSuite *ClientBalanceSuite) TestBalanceMulticall() {
+	ctx := context.Background()
+	multicall3 := EthCommon.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	testSuite.client.multicall3 = &multicall3
+
+	returnData, err := artifacts.Multicall3ABI.Methods["aggregate3"].Outputs.Pack([]multicall3Result{
+		{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(20).Bytes(), 32)},
+		{Success: true, ReturnData: common.LeftPadBytes(big.NewInt(30).Bytes(), 32)},
+	})
+	testSuite.NoError(err)
+
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 4 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			fillBalanceBatch(args.Get(1).([]rpc.BatchElem), map[string]string{
+				multicall3.Hex(): hexutil.Encode(returnData),
+			})
+		},
+	).Once()
+
+	resp, err := testSuite.client.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{Address: account},
+		nil,
+		[]*RosettaTypes.Currency{opCurrency, usdcCurrency},
+	)
+	testSuite.NoError(err)
+	testSuite.Equal("20", resp.Balances[0].Value)
+	testSuite.Equal("30", resp.Balances[1].Value)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+// TestBalanceMulticallUndeployed tests that blocks predating Multicall3 fall back to batched balanceOf calls.
+func (testSuite *ClientBalanceSuite) TestBalanceMulticallUndeployed() {
+	ctx := context.Background()
+	multicall3 := EthCommon.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	testSuite.client.multicall3 = &multicall3
+	calls := map[string]string{
+		multicall3.Hex():                "0x",
+		opTokenContractAddress.String(): balanceHex(20),
+		usdcAddress:                     balanceHex(30),
+	}
+
+	testSuite.mockLatestBlock(ctx)
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 4 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) { fillBalanceBatch(args.Get(1).([]rpc.BatchElem), calls) },
+	).Once()
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext",
+		ctx,
+		mock.MatchedBy(func(rpcs []rpc.BatchElem) bool { return len(rpcs) == 2 }),
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) { fillBalanceBatch(args.Get(1).([]rpc.BatchElem), calls) },
+	).Once()
+
+	resp, err := testSuite.client.Balance(
+		ctx,
+		&RosettaTypes.AccountIdentifier{Address: account},
+		nil,
+		[]*RosettaTypes.Currency{opCurrency, usdcCurrency},
+	)
+	testSuite.NoError(err)
+	testSuite.Equal("20", resp.Balances[0].Value)
+	testSuite.Equal("30", resp.Balances[1].Value)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
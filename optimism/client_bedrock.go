@@ -199,8 +199,31 @@ func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*EthType
 	return nil, errors.New("HeaderByNumber is not implemented")
 }
 
+// FilterLogs executes a filter query with eth_getLogs
 func (ec *Client) FilterLogs(ctx context.Context, query Eth.FilterQuery) ([]EthTypes.Log, error) {
-	return nil, errors.New("FilterLogs is not implemented")
+	arg := map[string]interface{}{
+		"address": query.Addresses,
+		"topics":  query.Topics,
+	}
+	if query.BlockHash != nil {
+		if query.FromBlock != nil || query.ToBlock != nil {
+			return nil, errors.New("cannot specify both BlockHash and FromBlock/ToBlock")
+		}
+		arg["blockHash"] = *query.BlockHash
+	} else {
+		if query.FromBlock == nil {
+			arg["fromBlock"] = "0x0"
+		} else {
+			arg["fromBlock"] = toBlockNumArg(query.FromBlock)
+		}
+		arg["toBlock"] = toBlockNumArg(query.ToBlock)
+	}
+
+	var logs []EthTypes.Log
+	if err := ec.c.CallContext(ctx, &logs, "eth_getLogs", arg); err != nil {
+		return nil, err
+	}
+	return logs, nil
 }
 
 func (ec *Client) SubscribeFilterLogs(ctx context.Context, query Eth.FilterQuery, ch chan<- EthTypes.Log) (Eth.Subscription, error) {
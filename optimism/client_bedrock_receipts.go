@@ -23,7 +23,9 @@ import (
 
 	"github.com/ethereum-optimism/optimism/l2geth/rpc"
 	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/inphi/optimism-rosetta/metrics"
 )
 
 var eip1559TxType = 2
@@ -76,6 +78,87 @@ func ExtractL1Fee(rosettaTxReceipt *RosettaTxReceipt) *big.Int {
 	return big.NewInt(0).SetUint64(l1FeeUint64)
 }
 
+// operatorFeeScalarDivisor scales down the Isthmus operator fee scalar, which has 6 decimals.
+var operatorFeeScalarDivisor = big.NewInt(1_000_000)
+
+// OperatorFee returns the Isthmus operator fee charged for the receipt, i.e.
+// gasUsed * operatorFeeScalar / 1e6 + operatorFeeConstant, or nil if the receipt has none.
+func (r *L2Receipt) OperatorFee() *big.Int {
+	if r.OperatorFeeScalar == nil || r.OperatorFeeConstant == nil || r.GasUsed == nil {
+		return nil
+	}
+
+	fee := new(big.Int).SetUint64(uint64(*r.GasUsed))
+	fee.Mul(fee, new(big.Int).SetUint64(uint64(*r.OperatorFeeScalar)))
+	fee.Div(fee, operatorFeeScalarDivisor)
+	return fee.Add(fee, new(big.Int).SetUint64(uint64(*r.OperatorFeeConstant)))
+}
+
+// ExtractOperatorFee attempts to compute the operator fee from the RawMessage field in a [RosettaTxReceipt]
+func ExtractOperatorFee(rosettaTxReceipt *RosettaTxReceipt) *big.Int {
+	var receipt L2Receipt
+	if err := json.Unmarshal(rosettaTxReceipt.RawMessage, &receipt); err != nil {
+		return nil
+	}
+	return receipt.OperatorFee()
+}
+
+// FeeComponents is the breakdown of the fee paid by a non-deposit transaction into the amounts
+// credited to the sequencer and to each fee vault, along with the receipt fields that the L1 and
+// operator fees were computed from. Fields that do not apply to the hardfork of the block are omitted.
+type FeeComponents struct {
+	SequencerFee *hexutil.Big `json:"sequencer_fee"`
+	BaseFee      *hexutil.Big `json:"base_fee"`
+	L1Fee        *hexutil.Big `json:"l1_fee,omitempty"`
+	OperatorFee  *hexutil.Big `json:"operator_fee,omitempty"`
+
+	L1GasPrice          *hexutil.Uint64 `json:"l1_gas_price,omitempty"`
+	L1GasUsed           *hexutil.Uint64 `json:"l1_gas_used,omitempty"`
+	L1FeeScalar         *big.Float      `json:"l1_fee_scalar,omitempty"`
+	L1BaseFeeScalar     *hexutil.Uint64 `json:"l1_base_fee_scalar,omitempty"`
+	L1BlobBaseFee       *hexutil.Big    `json:"l1_blob_base_fee,omitempty"`
+	L1BlobBaseFeeScalar *hexutil.Uint64 `json:"l1_blob_base_fee_scalar,omitempty"`
+	OperatorFeeScalar   *hexutil.Uint64 `json:"operator_fee_scalar,omitempty"`
+	OperatorFeeConstant *hexutil.Uint64 `json:"operator_fee_constant,omitempty"`
+}
+
+// ExtractFeeComponents splits the fee of a loaded transaction using its receipt.
+// The sequencer receives whatever remains of the fee once the base fee, L1 fee and operator fee are paid out.
+func ExtractFeeComponents(tx *bedrockTransaction) (*FeeComponents, error) {
+	var receipt L2Receipt
+	if err := json.Unmarshal(tx.Receipt.RawMessage, &receipt); err != nil {
+		return nil, err
+	}
+
+	sequencerFee := new(big.Int).Set(tx.FeeAmount)
+	components := &FeeComponents{
+		SequencerFee:        (*hexutil.Big)(sequencerFee),
+		BaseFee:             (*hexutil.Big)(tx.FeeBurned),
+		L1GasPrice:          receipt.L1GasPrice,
+		L1GasUsed:           receipt.L1GasUsed,
+		L1FeeScalar:         receipt.FeeScalar,
+		L1BaseFeeScalar:     receipt.L1BaseFeeScalar,
+		L1BlobBaseFee:       receipt.L1BlobBaseFee,
+		L1BlobBaseFeeScalar: receipt.L1BlobBaseFeeScalar,
+		OperatorFeeScalar:   receipt.OperatorFeeScalar,
+		OperatorFeeConstant: receipt.OperatorFeeConstant,
+	}
+	if tx.FeeBurned != nil {
+		sequencerFee.Sub(sequencerFee, tx.FeeBurned)
+	}
+	if receipt.L1Fee != nil {
+		l1Fee := new(big.Int).SetUint64(uint64(*receipt.L1Fee))
+		sequencerFee.Sub(sequencerFee, l1Fee)
+		components.L1Fee = (*hexutil.Big)(l1Fee)
+	}
+	if operatorFee := receipt.OperatorFee(); operatorFee != nil {
+		sequencerFee.Sub(sequencerFee, operatorFee)
+		components.OperatorFee = (*hexutil.Big)(operatorFee)
+	}
+
+	return components, nil
+}
+
 // getBedrockBlockReceipts returns the receipts for all transactions in a block.
 func (ec *Client) getBedrockBlockReceipts(
 	ctx context.Context,
@@ -102,10 +185,12 @@ func (ec *Client) getBedrockBlockReceipts(
 	maxBatchSize := 25
 	for i := 0; i < len(txs); i += maxBatchSize {
 		if i+maxBatchSize < len(txs) {
+			metrics.ReceiptBatchSize.Observe(float64(maxBatchSize))
 			if err := ec.c.BatchCallContext(ctx, reqs[i:i+maxBatchSize]); err != nil {
 				return nil, err
 			}
 		} else {
+			metrics.ReceiptBatchSize.Observe(float64(len(txs) - i))
 			if err := ec.c.BatchCallContext(ctx, reqs[i:]); err != nil {
 				return nil, err
 			}
@@ -136,6 +221,9 @@ func (ec *Client) getBedrockBlockReceipts(
 				l1FeeBigInt := new(big.Int).SetUint64(uint64(*r.L1Fee))
 				feeAmount.Add(feeAmount, l1FeeBigInt)
 			}
+			if operatorFee := r.OperatorFee(); operatorFee != nil {
+				feeAmount.Add(feeAmount, operatorFee)
+			}
 		}
 
 		receipt := &RosettaTxReceipt{
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"testing"
 
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum-optimism/optimism/l2geth/rpc"
 	EthCommon "github.com/ethereum/go-ethereum/common"
 	EthHexutil "github.com/ethereum/go-ethereum/common/hexutil"
@@ -195,6 +196,83 @@ func (testSuite *ClientBedrockReceiptsTestSuite) TestGetBlockReceiptsL2() {
 	}
 }
 
+// TestFeeOpsByHardfork tests that fees are split between the sequencer and the fee vaults
+// according to the receipt fields of each hardfork.
+func (testSuite *ClientBedrockReceiptsTestSuite) TestFeeOpsByHardfork() {
+	ctx := context.Background()
+	blockHash := EthCommon.HexToHash("0x9d2f5b6c1a7e3f4d8c2b1a0e9f8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d")
+	blockNumber := "0x1a2b3c"
+	from := EthCommon.HexToAddress("0xe261e28d9fccd3742629fef031e63327585b40f0")
+	to := EthCommon.HexToAddress("0x4200000000000000000000000000000000000006")
+	baseFee := big.NewInt(252)
+	nonce := uint64(7)
+
+	for _, fork := range []string{"bedrock", "ecotone", "fjord", "isthmus"} {
+		testSuite.Run(fork, func() {
+			testSuite.mockJSONRPC = &mocks.JSONRPC{}
+			testSuite.client.c = testSuite.mockJSONRPC
+
+			receiptRaw, err := os.ReadFile("testdata/" + fork + "_tx_receipt.json")
+			testSuite.NoError(err)
+			var receipt L2Receipt
+			testSuite.NoError(json.Unmarshal(receiptRaw, &receipt))
+			testSuite.mockJSONRPC.On("BatchCallContext", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				*(args.Get(1).([]rpc.BatchElem)[0].Result.(*json.RawMessage)) = receiptRaw
+			}).Once()
+
+			txHash := receipt.TxHash
+			innerTx := &transaction{
+				Type:                 EthHexutil.Uint64(eip1559TxType),
+				Nonce:                (*EthHexutil.Uint64)(&nonce),
+				Recipient:            &to,
+				Value:                (*EthHexutil.Big)(big.NewInt(0)),
+				GasLimit:             EthHexutil.Uint64(21000),
+				MaxFeePerGas:         (*EthHexutil.Big)(big.NewInt(2000000)),
+				MaxPriorityFeePerGas: (*EthHexutil.Big)(big.NewInt(1000000)),
+				Data:                 (*EthHexutil.Bytes)(&[]byte{}),
+				HashValue:            EthCommon.Hash(txHash),
+			}
+			txs := []BedrockRPCTransaction{
+				{
+					Tx: innerTx,
+					TxExtraInfo: TxExtraInfo{
+						BlockNumber: &blockNumber,
+						BlockHash:   &blockHash,
+						From:        &from,
+						TxHash:      (*EthCommon.Hash)(&txHash),
+					},
+				},
+			}
+			receipts, err := testSuite.client.getBedrockBlockReceipts(ctx, blockHash, txs, baseFee)
+			testSuite.NoError(err)
+
+			tx := txs[0].LoadTransaction()
+			tx.Transaction = innerTx
+			tx.Receipt = receipts[0]
+			tx.FeeAmount = receipts[0].TransactionFee
+			tx.FeeBurned = new(big.Int).Mul(receipts[0].GasUsed, baseFee)
+			tx.Miner = MustChecksum("0x4200000000000000000000000000000000000011")
+
+			ops, err := FeeOps(tx)
+			testSuite.NoError(err)
+			fees, err := ExtractFeeComponents(tx)
+			testSuite.NoError(err)
+			feesMap, err := MarshalJSONMap(fees)
+			testSuite.NoError(err)
+
+			correctRaw, err := os.ReadFile("testdata/" + fork + "_fee_ops.json")
+			testSuite.NoError(err)
+			var correct struct {
+				Operations    []*RosettaTypes.Operation `json:"operations"`
+				FeeComponents map[string]interface{}    `json:"fee_components"`
+			}
+			testSuite.NoError(json.Unmarshal(correctRaw, &correct))
+			testSuite.Equal(correct.Operations, ops)
+			testSuite.Equal(correct.FeeComponents, feesMap)
+		})
+	}
+}
+
 func mockBedrockReceipt(mocker *mocks.JSONRPC) EthTypes.Receipt {
 	ctx := context.Background()
 	hash := EthCommon.HexToHash("0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d")
@@ -327,6 +327,124 @@ func (testSuite *ClientBedrockTestSuite) TestBedrockBlockCurrent() {
 	runTest(client)
 }
 
+func (testSuite *ClientBedrockTestSuite) TestBedrockBlockTransaction() {
+	ctx := context.Background()
+	blockHash := "0x4503cbd671b3ca292e9f54998b2d566b705a32a178fc467f311c79b43e8e1774"
+	tx2 := EthCommon.HexToHash("0x6103c9a945fabd69b2cfe25cd0f5c9ebe73b7f68f4fed2c68b2cfdd8429a6a88")
+	client := &Client{
+		c:               testSuite.mockJSONRPC,
+		g:               testSuite.mockGraphQL,
+		currencyFetcher: testSuite.mockCurrencyFetcher,
+		tc:              testBedrockTraceConfig,
+		p:               params.GoerliChainConfig,
+		traceSemaphore:  semaphore.NewWeighted(100),
+		bedrockBlock:    big.NewInt(5_003_318),
+		// Single transactions must be traced individually, even if blocks are traced as a whole
+		traceByBlock: true,
+	}
+
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByHash",
+		blockHash,
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := os.ReadFile("testdata/goerli_bedrock_block_5003318.json")
+			testSuite.NoError(err)
+
+			*r = json.RawMessage(file)
+		},
+	).Twice()
+	testSuite.mockCurrencyFetcher.On(
+		"FetchCurrency",
+		ctx,
+		uint64(5003318),
+		mock.Anything,
+	).Return(
+		&RosettaTypes.Currency{
+			Symbol:   "LINK",
+			Decimals: 18,
+			Metadata: map[string]interface{}{ContractAddressKey: "0xdc2CC710e42857672E7907CF474a69B63B93089f"}},
+		nil,
+	)
+	mockTraceTransaction(ctx, testSuite, "testdata/goerli_bedrock_tx_trace_5003318_2.json", tx2.Hex())
+	mockGetBedrockTransactionReceipt(ctx, testSuite, []EthCommon.Hash{tx2}, []string{"testdata/goerli_bedrock_tx_receipt_5003318_2.json"})
+
+	correctRaw, err := os.ReadFile("testdata/goerli_bedrock_block_response_5003318.json")
+	testSuite.NoError(err)
+	var correct *RosettaTypes.BlockResponse
+	testSuite.NoError(json.Unmarshal(correctRaw, &correct))
+
+	blockIdentifier := &RosettaTypes.BlockIdentifier{Hash: blockHash, Index: 5003318}
+	tx, err := client.BlockTransaction(ctx, blockIdentifier, &RosettaTypes.TransactionIdentifier{Hash: tx2.Hex()})
+	testSuite.NoError(err)
+	testSuite.Equal(correct.Block.Transactions[1], tx)
+
+	// Transactions that are not in the block are not found
+	tx, err = client.BlockTransaction(ctx, blockIdentifier, &RosettaTypes.TransactionIdentifier{Hash: EthCommon.Hash{}.Hex()})
+	testSuite.Nil(tx)
+	testSuite.ErrorIs(err, ErrTransactionNotFound)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+func (testSuite *ClientBedrockTestSuite) TestBedrockBlockWithOtherTransactions() {
+	ctx := context.Background()
+	client := &Client{
+		c:                          testSuite.mockJSONRPC,
+		g:                          testSuite.mockGraphQL,
+		currencyFetcher:            testSuite.mockCurrencyFetcher,
+		tc:                         testBedrockTraceConfig,
+		p:                          params.GoerliChainConfig,
+		traceSemaphore:             semaphore.NewWeighted(100),
+		bedrockBlock:               big.NewInt(5_003_318),
+		otherTransactionsThreshold: 1,
+	}
+
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_getBlockByNumber",
+		"latest",
+		true,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+
+			file, err := os.ReadFile("testdata/goerli_bedrock_block_5003318.json")
+			testSuite.NoError(err)
+
+			*r = json.RawMessage(file)
+		},
+	).Once()
+
+	correctRaw, err := os.ReadFile("testdata/goerli_bedrock_block_response_5003318.json")
+	testSuite.NoError(err)
+	var correct *RosettaTypes.BlockResponse
+	testSuite.NoError(json.Unmarshal(correctRaw, &correct))
+
+	// No traces or receipts are fetched when the block is above the threshold
+	block, otherTxs, err := client.BlockWithOtherTransactions(ctx, nil)
+	testSuite.NoError(err)
+	testSuite.Equal(correct.Block.BlockIdentifier, block.BlockIdentifier)
+	testSuite.Equal(correct.Block.ParentBlockIdentifier, block.ParentBlockIdentifier)
+	testSuite.Empty(block.Transactions)
+	testSuite.Equal([]*RosettaTypes.TransactionIdentifier{
+		{Hash: "0x035437471437d2e61be662be806ea7a3603e37230e13f1c04e36e8ca891e9611"},
+		{Hash: "0x6103c9a945fabd69b2cfe25cd0f5c9ebe73b7f68f4fed2c68b2cfdd8429a6a88"},
+	}, otherTxs)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
 //nolint:unused
 func mockDebugTraceBedrockBlock(ctx context.Context, testSuite *ClientBedrockTestSuite, txFileData string) {
 	testSuite.mockJSONRPC.On(
@@ -32,6 +32,9 @@ type InnerBedrockTransaction interface {
 	GetType() uint64
 	GetValue() *big.Int
 	EffectiveGasTip(*big.Int) (*big.Int, error)
+	SourceHash() *EthCommon.Hash
+	Mint() *big.Int
+	IsSystemTx() bool
 }
 
 // transaction is a JSON representation of a Transaction
@@ -50,6 +53,11 @@ type transaction struct {
 	Recipient            *EthCommon.Address `json:"to"`
 	ChainID              *EthHexutil.Big    `json:"chainId,omitempty"`
 	HashValue            EthCommon.Hash     `json:"hash"`
+
+	// Deposit transaction fields
+	SourceHashValue *EthCommon.Hash `json:"sourceHash,omitempty"`
+	MintValue       *EthHexutil.Big `json:"mint,omitempty"`
+	IsSystemTxValue *bool           `json:"isSystemTx,omitempty"`
 }
 
 // IsDepositTx returns true if the transaction is a deposit tx type.
@@ -69,6 +77,25 @@ func (t *transaction) Hash() EthCommon.Hash {
 	return t.HashValue
 }
 
+// SourceHash returns the source hash of a deposit transaction, which uniquely identifies its L1 origin.
+// It is nil for other transactions.
+func (t *transaction) SourceHash() *EthCommon.Hash {
+	return t.SourceHashValue
+}
+
+// Mint returns the amount of ETH minted on L2 by a deposit transaction, or nil.
+func (t *transaction) Mint() *big.Int {
+	if t.MintValue == nil {
+		return nil
+	}
+	return t.MintValue.ToInt()
+}
+
+// IsSystemTx returns whether the transaction is a system deposit.
+func (t *transaction) IsSystemTx() bool {
+	return t.IsSystemTxValue != nil && *t.IsSystemTxValue
+}
+
 // To returns the recipient of the transaction.
 //
 //nolint:golint
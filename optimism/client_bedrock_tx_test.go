@@ -55,6 +55,8 @@ func (testSuite *ClientBedrockTxTestSuite) TestTransactionDeserialization() {
 	data := EthCommon.Hex2Bytes("015d8eb900000000000000000000000000000000000000000000000000000000008097790000000000000000000000000000000000000000000000000000000063dd1a98000000000000000000000000000000000000000000000000000000000004ee2f1ed96835176d084c845bd2c09456d60401d74861b690bdabac97f6724f4b4bdf00000000000000000000000000000000000000000000000000000000000000020000000000000000000000007431310e026b69bfc676c0013e12a1a11411eec9000000000000000000000000000000000000000000000000000000000000083400000000000000000000000000000000000000000000000000000000000f4240")
 	recipient := EthCommon.HexToAddress("0x4200000000000000000000000000000000000015")
 	nonce := uint64(0)
+	sourceHash := EthCommon.HexToHash("0xe498acd8ac4c577ba87349e5f649034404485515ba7f2fa3b8dfda726dd62c16")
+	isSystemTx := true
 	expectedTransaction := BedrockRPCTransaction{
 		Tx: &transaction{
 			Type:                 (EthHexutil.Uint64)(convertBigInt("0x7e").Uint64()),
@@ -71,6 +73,9 @@ func (testSuite *ClientBedrockTxTestSuite) TestTransactionDeserialization() {
 			Recipient:            &recipient,
 			ChainID:              (*EthHexutil.Big)(nil),
 			HashValue:            expectedTxHash,
+			SourceHashValue:      &sourceHash,
+			MintValue:            (*EthHexutil.Big)(EthHexutil.MustDecodeBig("0x0")),
+			IsSystemTxValue:      &isSystemTx,
 		},
 		TxExtraInfo: TxExtraInfo{
 			BlockNumber: &expectedBlockNumber,
@@ -16,11 +16,15 @@ package optimism
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"math/big"
+	"strings"
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
 	ethereum "github.com/ethereum-optimism/optimism/l2geth"
 	"github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
+	"github.com/inphi/optimism-rosetta/metrics"
 )
 
 // toBlockNumArg returns a jsonrpc string identifier for a block.
@@ -64,7 +68,116 @@ func (ec *Client) Block(
 	ctx context.Context,
 	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
 ) (*RosettaTypes.Block, error) {
-	// Derive block method and id
+	if block := ec.cachedBlock(blockIdentifier); block != nil {
+		return block, nil
+	}
+
+	derivedBlockMethod, derivedBlockID := deriveBlockRequest(blockIdentifier)
+	block, _, err := ec.disptachBlockRequest(ctx, 0, derivedBlockMethod, derivedBlockID, true)
+	if err == nil && ec.blockCache != nil {
+		ec.cacheFinalizedBlock(ctx, block)
+	}
+	return block, err
+}
+
+// BlockWithOtherTransactions behaves like [Client.Block], except that blocks containing more
+// transactions than the configured threshold are returned without populated transactions.
+// Their transaction identifiers are returned instead, to be fetched individually with [Client.BlockTransaction].
+//
+// Only populated blocks are cached, and cached blocks above the threshold are returned with
+// their transaction identifiers. Blocks above the threshold are cheap to fetch, as none of their
+// transactions is traced, so they are only cached once [Client.Block] has populated them.
+func (ec *Client) BlockWithOtherTransactions(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.PartialBlockIdentifier,
+) (*RosettaTypes.Block, []*RosettaTypes.TransactionIdentifier, error) {
+	if block := ec.cachedBlock(blockIdentifier); block != nil {
+		return block, splitOtherTransactions(block, ec.otherTransactionsThreshold), nil
+	}
+
+	derivedBlockMethod, derivedBlockID := deriveBlockRequest(blockIdentifier)
+	block, otherTxs, err := ec.disptachBlockRequest(ctx, ec.otherTransactionsThreshold, derivedBlockMethod, derivedBlockID, true)
+	if err == nil && otherTxs == nil && ec.blockCache != nil {
+		ec.cacheFinalizedBlock(ctx, block)
+	}
+	return block, otherTxs, err
+}
+
+// cachedBlock returns the block at blockIdentifier from the block cache, or nil if it is not cached.
+func (ec *Client) cachedBlock(blockIdentifier *RosettaTypes.PartialBlockIdentifier) *RosettaTypes.Block {
+	if ec.blockCache == nil {
+		return nil
+	}
+	block, err := ec.blockCache.Get(blockIdentifier)
+	if err != nil {
+		log.Printf("unable to read block cache: %v", err)
+		return nil
+	}
+	metrics.ObserveCache(metrics.BlockCache, block != nil)
+	return block
+}
+
+// splitOtherTransactions removes the transactions of a populated block containing more transactions
+// than otherTransactionsThreshold, and returns their identifiers as parsing the block with the threshold would.
+func splitOtherTransactions(block *RosettaTypes.Block, otherTransactionsThreshold int) []*RosettaTypes.TransactionIdentifier {
+	if otherTransactionsThreshold <= 0 || len(block.Transactions) <= otherTransactionsThreshold {
+		return nil
+	}
+	otherTxs := make([]*RosettaTypes.TransactionIdentifier, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		otherTxs[i] = tx.TransactionIdentifier
+	}
+	block.Transactions = []*RosettaTypes.Transaction{}
+	return otherTxs
+}
+
+// BlockTransaction returns a single populated transaction of the block at the *RosettaTypes.BlockIdentifier.
+// Only the requested transaction is traced and has its receipt fetched.
+func (ec *Client) BlockTransaction(
+	ctx context.Context,
+	blockIdentifier *RosettaTypes.BlockIdentifier,
+	transactionIdentifier *RosettaTypes.TransactionIdentifier,
+) (*RosettaTypes.Transaction, error) {
+	header, block, raw, blockErr := ec.getBlock(ctx, "eth_getBlockByHash", blockIdentifier.Hash, true)
+	if blockErr != nil && blockErr.IsBlockFetchError() {
+		return nil, blockErr.Err
+	}
+	if blockErr == nil && ec.IsPreBedrock(header.Number) {
+		if header.Number.Int64() != blockIdentifier.Index {
+			return nil, fmt.Errorf("%w: block %s is at height %d, not %d", ErrBlockOrphaned, blockIdentifier.Hash, header.Number.Int64(), blockIdentifier.Index)
+		}
+		for i := range block.Transactions {
+			if !strings.EqualFold(block.Transactions[i].tx.Hash().Hex(), transactionIdentifier.Hash) {
+				continue
+			}
+			return ec.getParsedTransaction(ctx, header, block.Hash, block.Transactions[i])
+		}
+		return nil, fmt.Errorf("%w: %s in block %s", ErrTransactionNotFound, transactionIdentifier.Hash, blockIdentifier.Hash)
+	}
+
+	head, body, err := ec.parseBedrockBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+	if head.Number.Int64() != blockIdentifier.Index {
+		return nil, fmt.Errorf("%w: block %s is at height %d, not %d", ErrBlockOrphaned, blockIdentifier.Hash, head.Number.Int64(), blockIdentifier.Index)
+	}
+	for i := range body.Transactions {
+		if !strings.EqualFold(body.Transactions[i].TxHash.Hex(), transactionIdentifier.Hash) {
+			continue
+		}
+		// Tracing by block would trace every transaction, so always trace the transaction on its own
+		txs, err := ec.populateBedrockTransactions(ctx, head, body.Hash, body.Transactions[i:i+1], false)
+		if err != nil {
+			return nil, err
+		}
+		return txs[0], nil
+	}
+	return nil, fmt.Errorf("%w: %s in block %s", ErrTransactionNotFound, transactionIdentifier.Hash, blockIdentifier.Hash)
+}
+
+// deriveBlockRequest returns the RPC method and argument used to fetch the block at the *RosettaTypes.PartialBlockIdentifier.
+func deriveBlockRequest(blockIdentifier *RosettaTypes.PartialBlockIdentifier) (string, string) {
 	derivedBlockMethod := "eth_getBlockByNumber"
 	derivedBlockID := toBlockNumArg(nil)
 	if blockIdentifier != nil {
@@ -78,29 +191,29 @@ func (ec *Client) Block(
 			derivedBlockID = toBlockNumArg(big.NewInt(*blockIdentifier.Index))
 		}
 	}
-
-	return ec.disptachBlockRequest(ctx, derivedBlockMethod, derivedBlockID, true)
+	return derivedBlockMethod, derivedBlockID
 }
 
 // dispatchBlockRequest dispatches a block request to the correct block fetcher.
 func (ec *Client) disptachBlockRequest(
 	ctx context.Context,
+	otherTransactionsThreshold int,
 	blockMethod string,
 	args ...interface{},
-) (*RosettaTypes.Block, error) {
+) (*RosettaTypes.Block, []*RosettaTypes.TransactionIdentifier, error) {
 	// Attempt pre-bedrock block + header fetch
 	header, block, raw, err := ec.getBlock(ctx, blockMethod, args...)
 	if err == nil {
 		preBedrock := ec.IsPreBedrock(header.Number)
 		if preBedrock {
-			return ec.getParsedBlock(ctx, header, block)
+			return ec.getParsedBlock(ctx, header, block, otherTransactionsThreshold)
 		}
 	}
 	// Block fetch errors should short-circuit
 	if err != nil && err.IsBlockFetchError() {
-		return nil, err.Err
+		return nil, nil, err.Err
 	}
 
 	// Revert to bedrock otherwise
-	return ec.getParsedBedrockBlock(ctx, raw)
+	return ec.getParsedBedrockBlock(ctx, raw, otherTransactionsThreshold)
 }
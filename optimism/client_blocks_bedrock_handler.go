@@ -31,31 +31,115 @@ import (
 const TopicsInErc20Transfer = 3
 
 // getParsedBedrockBlock constructs a [RosettaTypes.Block] from a raw block response.
-//
-//nolint:gocognit
+// If otherTransactionsThreshold is positive and the block contains more transactions than it,
+// the transactions are not populated and their identifiers are returned instead.
 func (ec *Client) getParsedBedrockBlock(
 	ctx context.Context,
 	raw *json.RawMessage,
+	otherTransactionsThreshold int,
 ) (
 	*RosettaTypes.Block,
+	[]*RosettaTypes.TransactionIdentifier,
 	error,
 ) {
 	head, body, err := ec.parseBedrockBlock(raw)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	block := bedrockBlockSkeleton(head)
+	block.Metadata = l1BlockInfoMetadata(body.Transactions)
+	if otherTransactionsThreshold > 0 && len(body.Transactions) > otherTransactionsThreshold {
+		otherTxs := make([]*RosettaTypes.TransactionIdentifier, len(body.Transactions))
+		for i, tx := range body.Transactions {
+			otherTxs[i] = &RosettaTypes.TransactionIdentifier{
+				Hash: tx.TxHash.String(),
+			}
+		}
+		block.Transactions = []*RosettaTypes.Transaction{}
+		return block, otherTxs, nil
+	}
+
+	block.Transactions, err = ec.populateBedrockTransactions(ctx, head, body.Hash, body.Transactions, ec.traceByBlock)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, nil, nil
+}
+
+// bedrockBlockSkeleton returns a [RosettaTypes.Block] with everything but the transactions populated.
+func bedrockBlockSkeleton(head *rpcHeader) *RosettaTypes.Block {
+	parentIndex := head.Number.Int64()
+	if parentIndex != GenesisBlockIndex {
+		parentIndex--
 	}
 
+	return &RosettaTypes.Block{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Index: head.Number.Int64(),
+			Hash:  head.Hash.String(),
+		},
+		ParentBlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  head.ParentHash.Hex(),
+			Index: parentIndex,
+		},
+		Timestamp: convertTime(head.Time),
+		Metadata:  nil,
+	}
+}
+
+// l1BlockInfoMetadata returns the L1 attributes of a block as block metadata.
+// The L1 attributes deposit is always the first transaction of a bedrock block.
+func l1BlockInfoMetadata(txs []BedrockRPCTransaction) map[string]interface{} {
+	if len(txs) == 0 || txs[0].Tx.GetType() != L1ToL2DepositType {
+		return nil
+	}
+	to := txs[0].Tx.To()
+	inner, ok := txs[0].Tx.(*transaction)
+	if to == nil || *to != L1BlockAddr || !ok || inner.Data == nil {
+		return nil
+	}
+
+	info, err := DecodeL1BlockInfo(*inner.Data)
+	if err != nil {
+		// The block is still valid without its L1 attributes, so we do not fail it
+		logger.Printf("unable to decode L1 attributes deposit %s: %v", txs[0].TxHash, err)
+		return nil
+	}
+	metadata, err := MarshalJSONMap(info)
+	if err != nil {
+		logger.Printf("unable to marshal L1 attributes of deposit %s: %v", txs[0].TxHash, err)
+		return nil
+	}
+
+	return metadata
+}
+
+// populateBedrockTransactions traces, fetches the receipts for, and populates the given transactions of a block.
+// When traceByBlock is set, the whole block is traced with debug_traceBlockByHash,
+// so txs must then contain every transaction of the block in order.
+//
+//nolint:gocognit
+func (ec *Client) populateBedrockTransactions(
+	ctx context.Context,
+	head *rpcHeader,
+	blockHash EthCommon.Hash,
+	txs []BedrockRPCTransaction,
+	traceByBlock bool,
+) ([]*RosettaTypes.Transaction, error) {
 	// Use a client option here to fetch traces from either debug_traceBlockByHash or debug_traceTransaction
 	var m map[string][]*FlatCall
+	var err error
 	addTraces := head.Number.Int64() != GenesisBlockIndex
 	if addTraces {
-		if ec.traceByBlock {
-			m, err = ec.TraceBlockByHash(ctx, body.Hash, body.Transactions)
+		if traceByBlock {
+			m, err = ec.TraceBlockByHash(ctx, blockHash, txs)
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			m, err = ec.TraceTransactions(ctx, body.Hash, body.Transactions)
+			m, err = ec.TraceTransactions(ctx, blockHash, txs)
 			if err != nil {
 				return nil, err
 			}
@@ -63,13 +147,13 @@ func (ec *Client) getParsedBedrockBlock(
 	}
 
 	// Convert all txs to loaded txs
-	txs := make([]InnerBedrockTransaction, len(body.Transactions))
-	loadedTxs := make([]*bedrockTransaction, len(body.Transactions))
-	for i, tx := range body.Transactions {
-		txs[i] = tx.Tx
+	innerTxs := make([]InnerBedrockTransaction, len(txs))
+	loadedTxs := make([]*bedrockTransaction, len(txs))
+	for i, tx := range txs {
+		innerTxs[i] = tx.Tx
 
 		loadedTxs[i] = tx.LoadTransaction()
-		loadedTxs[i].Transaction = txs[i]
+		loadedTxs[i].Transaction = innerTxs[i]
 		loadedTxs[i].BaseFee = head.BaseFee
 		loadedTxs[i].Miner = MustChecksum(head.Coinbase.Hex())
 
@@ -87,12 +171,12 @@ func (ec *Client) getParsedBedrockBlock(
 
 	// Get all transaction receipts
 	var baseFee *big.Int
-	if len(body.Transactions) > 0 {
+	if len(txs) > 0 {
 		baseFee = loadedTxs[0].BaseFee
 	}
-	receipts, err := ec.getBedrockBlockReceipts(ctx, body.Hash, body.Transactions, baseFee)
+	receipts, err := ec.getBedrockBlockReceipts(ctx, blockHash, txs, baseFee)
 	if err != nil {
-		return nil, fmt.Errorf("%w: could not get receipts for %x", err, body.Hash[:])
+		return nil, fmt.Errorf("%w: could not get receipts for %x", err, blockHash[:])
 	}
 	for i, tx := range loadedTxs {
 		if receipts != nil {
@@ -115,11 +199,6 @@ func (ec *Client) getParsedBedrockBlock(
 		}
 	}
 
-	parentIndex := head.Number.Int64()
-	if parentIndex != GenesisBlockIndex {
-		parentIndex--
-	}
-
 	rosettaTxs := make([]*RosettaTypes.Transaction, len(loadedTxs))
 	for i, tx := range loadedTxs {
 		rosettaTxs[i], err = ec.populateBedrockTransaction(ctx, head, tx)
@@ -128,19 +207,7 @@ func (ec *Client) getParsedBedrockBlock(
 		}
 	}
 
-	return &RosettaTypes.Block{
-		BlockIdentifier: &RosettaTypes.BlockIdentifier{
-			Index: head.Number.Int64(),
-			Hash:  head.Hash.String(),
-		},
-		ParentBlockIdentifier: &RosettaTypes.BlockIdentifier{
-			Hash:  head.ParentHash.Hex(),
-			Index: parentIndex,
-		},
-		Timestamp:    convertTime(head.Time),
-		Transactions: rosettaTxs,
-		Metadata:     nil,
-	}, nil
+	return rosettaTxs, nil
 }
 
 // populateBedrockTransaction populates a Rosetta transaction from a bedrock transaction.
@@ -164,7 +231,20 @@ func (ec *Client) populateBedrockTransaction(
 
 	// Compute tx operations via tx.Receipt logs for ERC20 transfers
 	// if Filter == false, we record every ERC20 tokens
-	for _, log := range receiptLogs {
+	bridgeTransfers := matchBridgeTransfers(receiptLogs)
+	for i, log := range receiptLogs {
+		if ec.supportsNFT(log.Address) {
+			nftOps, err := NFTOps(log, int64(len(ops)))
+			if err != nil {
+				// Malformed logs of a contract must not prevent the block from being parsed
+				logger.Printf("unable to parse NFT transfer of %s in %s: %v", log.Address.Hex(), tx.TxHash.Hex(), err)
+			}
+			if len(nftOps) > 0 {
+				ops = append(ops, nftOps...)
+				continue
+			}
+		}
+
 		// If this isn't an ERC20 transfer, skip
 		if !BedrockContainsTopic(log, encodedTransferMethod) {
 			continue
@@ -172,46 +252,79 @@ func (ec *Client) populateBedrockTransaction(
 		if ec.supportsToken(log.Address.String()) {
 			switch len(log.Topics) {
 			case TopicsInErc20Transfer:
-				currency, err := ec.currencyFetcher.FetchCurrency(ctx, head.Number.Uint64(), log.Address.Hex())
-				if err != nil {
-					// If an error is encountered while fetching currency details, return a default value and let the client handle it.
-					logger.Printf("error while fetching currency details for currency: %s: %v", log.Address.Hex(), err)
-					currency = &RosettaTypes.Currency{
-						Symbol:   defaultERC20Symbol,
-						Decimals: defaultERC20Decimals,
-						Metadata: map[string]interface{}{
-							ContractAddressKey: log.Address.Hex(),
-						},
-					}
-				}
+				currency := ec.erc20Currency(ctx, head.Number.Uint64(), log.Address)
 				erc20Ops := Erc20Ops(log, currency, int64(len(ops)))
+				if transfer, ok := bridgeTransfers[i]; ok {
+					transfer.apply(erc20Ops, ec.enableBridgeOps)
+				}
 				ops = append(ops, erc20Ops...)
 			default:
 			}
 		}
 	}
 
+	ops = append(ops, WithdrawalOps(receiptLogs, int64(len(ops)))...)
+
 	// Marshal receipt and trace data
 	receiptMap, err := MarshalJSONMap(tx.Receipt)
 	if err != nil {
 		return nil, err
 	}
 
+	metadata := map[string]interface{}{
+		"gas_limit": hexutil.EncodeUint64(tx.Transaction.Gas()),
+		"gas_price": hexutil.EncodeBig(tx.Transaction.GasPrice()),
+		"receipt":   receiptMap,
+	}
+	// Deposits are linked back to their L1 origin through the source hash
+	if tx.IsDepositTx() {
+		if sourceHash := tx.Transaction.SourceHash(); sourceHash != nil {
+			metadata["source_hash"] = sourceHash.Hex()
+		}
+		if mint := tx.Transaction.Mint(); mint != nil {
+			metadata["mint"] = hexutil.EncodeBig(mint)
+		}
+		metadata["is_system_tx"] = tx.Transaction.IsSystemTx()
+	} else if tx.Receipt != nil {
+		fees, err := ExtractFeeComponents(tx)
+		if err != nil {
+			return nil, err
+		}
+		feesMap, err := MarshalJSONMap(fees)
+		if err != nil {
+			return nil, err
+		}
+		metadata["fee_components"] = feesMap
+	}
+
 	populatedTransaction := &RosettaTypes.Transaction{
 		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
 			Hash: tx.TxHash.String(),
 		},
 		Operations: ops,
-		Metadata: map[string]interface{}{
-			"gas_limit": hexutil.EncodeUint64(tx.Transaction.Gas()),
-			"gas_price": hexutil.EncodeBig(tx.Transaction.GasPrice()),
-			"receipt":   receiptMap,
-		},
+		Metadata:   metadata,
 	}
 
 	return populatedTransaction, nil
 }
 
+// erc20Currency fetches the currency of an ERC20 token. If an error is encountered while
+// fetching currency details, a default value is returned and the client is left to handle it.
+func (ec *Client) erc20Currency(ctx context.Context, blockNum uint64, token EthCommon.Address) *RosettaTypes.Currency {
+	currency, err := ec.currencyFetcher.FetchCurrency(ctx, blockNum, token.Hex())
+	if err != nil {
+		logger.Printf("error while fetching currency details for currency: %s: %v", token.Hex(), err)
+		return &RosettaTypes.Currency{
+			Symbol:   defaultERC20Symbol,
+			Decimals: defaultERC20Decimals,
+			Metadata: map[string]interface{}{
+				ContractAddressKey: token.Hex(),
+			},
+		}
+	}
+	return currency
+}
+
 // Erc20Ops returns a list of erc20 operations parsed from the log from a transaction receipt
 func Erc20Ops(
 	transferLog *EthTypes.Log,
@@ -37,6 +37,9 @@ func (ec *Client) ParseOps(
 		return nil, err
 	}
 	ops = append(ops, feeOps...)
+	if ec.enableMintOps && tx.IsDepositTx() {
+		return append(ops, DepositOps(tx, len(ops))...), nil
+	}
 	ops = append(ops, MintOps(tx, len(ops))...)
 	tracedOps := TraceOps(tx.Trace, len(ops))
 	ops = append(ops, tracedOps...)
@@ -45,6 +48,7 @@ func (ec *Client) ParseOps(
 }
 
 // MintOps constructs a list of [RosettaTypes.Operation]s for an Optimism Deposit or "mint" transaction.
+// It credits the deposit value to the sender with a CALL, and is used unless MINT operations are enabled (see [DepositOps]).
 func MintOps(tx *bedrockTransaction, startIndex int) []*RosettaTypes.Operation {
 	if !tx.IsDepositTx() {
 		return nil
@@ -62,6 +66,33 @@ func MintOps(tx *bedrockTransaction, startIndex int) []*RosettaTypes.Operation {
 	}
 }
 
+// DepositOps constructs the [RosettaTypes.Operation]s of a deposit transaction: a [MintOpType] operation
+// crediting the minted ETH to the sender, followed by the traced value transfers.
+//
+// The mint of a deposit persists even if its execution fails (since Regolith, the receipt of such a deposit
+// has a failed status), so the MINT operation is always successful while the traced operations of a failed
+// deposit never are.
+func DepositOps(tx *bedrockTransaction, startIndex int) []*RosettaTypes.Operation {
+	if !tx.IsDepositTx() {
+		return nil
+	}
+
+	var ops []*RosettaTypes.Operation
+	if mint := tx.Transaction.Mint(); mint != nil && mint.Sign() > 0 {
+		fromAddress := MustChecksum(tx.From.String())
+		ops = append(ops, GenerateOp(int64(startIndex), nil, MintOpType, SuccessStatus, fromAddress, Amount(mint, Currency), nil))
+	}
+
+	tracedOps := TraceOps(tx.Trace, startIndex+len(ops))
+	if tx.Receipt != nil && !tx.Status {
+		for _, op := range tracedOps {
+			op.Status = RosettaTypes.String(FailureStatus)
+		}
+	}
+
+	return append(ops, tracedOps...)
+}
+
 // TraceOps constructs [RosettaTypes.Operation]s from a list of [FlatCall]s.
 //
 //nolint:gocognit
@@ -178,6 +209,8 @@ func TraceOps(calls []*FlatCall, startIndex int) []*RosettaTypes.Operation {
 }
 
 // FeeOps returns the fee operations for a given transaction.
+// The fee debited from the sender is split between the sequencer, the BaseFeeVault, the L1FeeVault and,
+// since the Isthmus hardfork, the OperatorFeeVault (see [ExtractFeeComponents]).
 func FeeOps(tx *bedrockTransaction) ([]*RosettaTypes.Operation, error) {
 	if tx.IsDepositTx() {
 		return nil, nil
@@ -188,16 +221,9 @@ func FeeOps(tx *bedrockTransaction) ([]*RosettaTypes.Operation, error) {
 		return nil, err
 	}
 
-	sequencerFeeAmount := new(big.Int).Set(tx.FeeAmount)
-	if tx.FeeBurned != nil {
-		sequencerFeeAmount.Sub(sequencerFeeAmount, tx.FeeBurned)
-	}
-	L1Fee := ExtractL1Fee(tx.Receipt)
-	if L1Fee != nil {
-		sequencerFeeAmount.Sub(sequencerFeeAmount, L1Fee)
-	}
-	if sequencerFeeAmount == nil {
-		return nil, nil
+	fees, err := ExtractFeeComponents(tx)
+	if err != nil {
+		return nil, err
 	}
 
 	feeRewarder := tx.Miner
@@ -212,7 +238,7 @@ func FeeOps(tx *bedrockTransaction) ([]*RosettaTypes.Operation, error) {
 		},
 	}
 	sequencerAddress := MustChecksum(feeRewarder)
-	sequencerAmount := Amount(sequencerFeeAmount, Currency)
+	sequencerAmount := Amount(fees.SequencerFee.ToInt(), Currency)
 	baseFeeVaultRelatedOps := []*RosettaTypes.OperationIdentifier{
 		{
 			Index: 0,
@@ -226,7 +252,7 @@ func FeeOps(tx *bedrockTransaction) ([]*RosettaTypes.Operation, error) {
 		},
 	}
 	L1FeeVaultAddress := L1FeeVault.Hex()
-	L1FeeVaultAmount := Amount(L1Fee, Currency)
+	L1FeeVaultAmount := Amount(fees.L1Fee.ToInt(), Currency)
 
 	ops := []*RosettaTypes.Operation{
 		GenerateOp(0, nil, opType, opStatus, fromAddress, fromAmount, nil),
@@ -235,6 +261,18 @@ func FeeOps(tx *bedrockTransaction) ([]*RosettaTypes.Operation, error) {
 		GenerateOp(3, L1FeeVaultRelatedOps, opType, opStatus, L1FeeVaultAddress, L1FeeVaultAmount, nil),
 	}
 
+	// Only transactions of Isthmus blocks are charged an operator fee
+	if fees.OperatorFee != nil {
+		operatorFeeVaultRelatedOps := []*RosettaTypes.OperationIdentifier{
+			{
+				Index: 0,
+			},
+		}
+		operatorFeeVaultAddress := OperatorFeeVault.Hex()
+		operatorFeeVaultAmount := Amount(fees.OperatorFee.ToInt(), Currency)
+		ops = append(ops, GenerateOp(4, operatorFeeVaultRelatedOps, opType, opStatus, operatorFeeVaultAddress, operatorFeeVaultAmount, nil))
+	}
+
 	return ops, nil
 }
 
@@ -206,3 +206,98 @@ func (testSuite *BedrockOpsTestSuite) TestValidTrace() {
 		},
 	}, ops)
 }
+
+// newDepositTx constructs a loaded deposit transaction that mints to from and sends value to to.
+func newDepositTx(from, to EthCommon.Address, mint, value *big.Int, status bool) *bedrockTransaction {
+	txHash := EthCommon.HexToHash("0xb358c6958b1cab722752939cbb92e3fec6b6023de360305910ce80c56c3dad9d")
+	innerTx := &transaction{
+		Type:      L1ToL2DepositType,
+		Recipient: &to,
+		Value:     (*EthHexutil.Big)(value),
+		MintValue: (*EthHexutil.Big)(mint),
+		HashValue: txHash,
+	}
+	return &bedrockTransaction{
+		Transaction: innerTx,
+		From:        &from,
+		BlockHash:   &EthTypes.EmptyRootHash,
+		TxHash:      &txHash,
+		FeeAmount:   big.NewInt(0),
+		Status:      status,
+		Receipt:     &RosettaTxReceipt{},
+		Trace: []*FlatCall{
+			{
+				Type:   "call",
+				From:   from,
+				To:     to,
+				Value:  value,
+				Revert: !status,
+			},
+		},
+	}
+}
+
+// TestDepositOps tests that [DepositOps] credits the minted amount separately from the traced value transfer,
+// and that the mint of a failed deposit persists.
+func (testSuite *BedrockOpsTestSuite) TestDepositOps() {
+	from := EthCommon.HexToAddress("0x095E7BAea6a6c7c4c2DfeB977eFac326aF552d87")
+	to := EthCommon.HexToAddress("0x4200000000000000000000000000000000000016")
+	index := 2
+
+	tests := map[string]struct {
+		tx       *bedrockTransaction
+		expected []*RosettaTypes.Operation
+	}{
+		"mint differs from value": {
+			tx: newDepositTx(from, to, big.NewInt(100), big.NewInt(40), true),
+			expected: []*RosettaTypes.Operation{
+				GenerateOp(2, nil, MintOpType, SuccessStatus, from.Hex(), Amount(big.NewInt(100), Currency), nil),
+				GenerateOp(3, nil, CallOpType, SuccessStatus, from.Hex(), Amount(big.NewInt(-40), Currency), map[string]interface{}{}),
+				GenerateOp(4, []*RosettaTypes.OperationIdentifier{{Index: 3}}, CallOpType, SuccessStatus, to.Hex(), Amount(big.NewInt(40), Currency), map[string]interface{}{}),
+			},
+		},
+		"failed deposit": {
+			tx: newDepositTx(from, to, big.NewInt(100), big.NewInt(40), false),
+			expected: []*RosettaTypes.Operation{
+				GenerateOp(2, nil, MintOpType, SuccessStatus, from.Hex(), Amount(big.NewInt(100), Currency), nil),
+				GenerateOp(3, nil, CallOpType, FailureStatus, from.Hex(), Amount(big.NewInt(-40), Currency), map[string]interface{}{"error": ""}),
+				GenerateOp(4, []*RosettaTypes.OperationIdentifier{{Index: 3}}, CallOpType, FailureStatus, to.Hex(), Amount(big.NewInt(40), Currency), map[string]interface{}{"error": ""}),
+			},
+		},
+		"nothing minted": {
+			tx: newDepositTx(from, to, big.NewInt(0), big.NewInt(40), true),
+			expected: []*RosettaTypes.Operation{
+				GenerateOp(2, nil, CallOpType, SuccessStatus, from.Hex(), Amount(big.NewInt(-40), Currency), map[string]interface{}{}),
+				GenerateOp(3, []*RosettaTypes.OperationIdentifier{{Index: 2}}, CallOpType, SuccessStatus, to.Hex(), Amount(big.NewInt(40), Currency), map[string]interface{}{}),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		testSuite.Run(name, func() {
+			testSuite.Equal(test.expected, DepositOps(test.tx, index))
+		})
+	}
+
+	// Non-deposit transactions have no deposit operations
+	nonDeposit := newDepositTx(from, to, big.NewInt(100), big.NewInt(40), true)
+	nonDeposit.Transaction.(*transaction).Type = 0
+	testSuite.Nil(DepositOps(nonDeposit, index))
+}
+
+// TestParseOpsMintOps tests that MINT operations are only emitted when enabled.
+func (testSuite *BedrockOpsTestSuite) TestParseOpsMintOps() {
+	from := EthCommon.HexToAddress("0x095E7BAea6a6c7c4c2DfeB977eFac326aF552d87")
+	to := EthCommon.HexToAddress("0x4200000000000000000000000000000000000016")
+	tx := newDepositTx(from, to, big.NewInt(100), big.NewInt(40), true)
+
+	ops, err := (&Client{}).ParseOps(tx)
+	testSuite.NoError(err)
+	testSuite.Len(ops, 3)
+	testSuite.Equal(CallOpType, ops[0].Type)
+	testSuite.Equal("40", ops[0].Amount.Value)
+
+	ops, err = (&Client{enableMintOps: true}).ParseOps(tx)
+	testSuite.NoError(err)
+	testSuite.Equal(DepositOps(tx, 0), ops)
+}
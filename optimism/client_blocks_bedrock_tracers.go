@@ -36,7 +36,7 @@ func (ec *Client) TraceTransactions(
 	blockHash EthCommon.Hash,
 	txs []BedrockRPCTransaction,
 ) (map[string][]*FlatCall, error) {
-	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+	if err := ec.acquireTraceSemaphore(ctx); err != nil {
 		return nil, err
 	}
 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
@@ -91,29 +91,32 @@ func (ec *Client) TraceBlockByHash(
 	blockHash EthCommon.Hash,
 	txs []BedrockRPCTransaction,
 ) (map[string][]*FlatCall, error) {
-	if err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight); err != nil {
+	if err := ec.acquireTraceSemaphore(ctx); err != nil {
 		return nil, err
 	}
 	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
 
-	var calls []*rpcCall
-	var raw json.RawMessage
-
+	var calls []*Call
+	var err error
 	tracingConfig := ec.getBedrockTraceConfig()
-	err := ec.c.CallContext(ctx, &raw, "debug_traceBlockByHash", blockHash, tracingConfig)
-	if err != nil {
-		return nil, err
+	if ec.traceCache != nil {
+		calls, err = ec.traceCache.FetchBlock(ctx, common.Hash(blockHash), tracingConfig)
+	} else {
+		var raw json.RawMessage
+		if err = ec.c.CallContext(ctx, &raw, "debug_traceBlockByHash", blockHash, tracingConfig); err == nil {
+			calls, err = decodeBlockTraces(raw)
+		}
 	}
-	if err := json.Unmarshal(raw, &calls); err != nil {
+	if err != nil {
 		return nil, err
 	}
 	m := make(map[string][]*FlatCall)
-	for i, tx := range calls {
-		if tx.Result.Type == "" {
+	for i, call := range calls {
+		if call.Type == "" {
 			// ignore calls with an empty type
 			continue
 		}
-		flatCalls := FlattenTraces(tx.Result, []*FlatCall{})
+		flatCalls := FlattenTraces(call, []*FlatCall{})
 		// Ethereum native traces are guaranteed to return all transactions
 		txHash := txs[i].TxExtraInfo.TxHash.Hex()
 		if txHash == "" {
@@ -128,17 +131,22 @@ func (ec *Client) getBedrockTraceConfig() *L2Eth.TraceConfig {
 	// NOTE: By default, we replace the TraceConfig here since l2geth and op-geth have different tracings
 	tracingConfig := ec.tc
 	if !ec.customBedrockTracer {
-		tracer := "callTracer"
-		tracingConfig = &L2Eth.TraceConfig{
-			LogConfig: ec.tc.LogConfig,
-			Tracer:    &tracer,
-			Timeout:   ec.tc.Timeout,
-			Reexec:    ec.tc.Reexec,
-		}
+		tracingConfig = ec.callTraceConfig()
 	}
 	return tracingConfig
 }
 
+// callTraceConfig returns the trace config of op-geth's callTracer, regardless of the custom bedrock tracer.
+func (ec *Client) callTraceConfig() *L2Eth.TraceConfig {
+	tracer := "callTracer"
+	return &L2Eth.TraceConfig{
+		LogConfig: ec.tc.LogConfig,
+		Tracer:    &tracer,
+		Timeout:   ec.tc.Timeout,
+		Reexec:    ec.tc.Reexec,
+	}
+}
+
 // FlattenTraces recursively flattens all traces.
 func FlattenTraces(data *Call, flattened []*FlatCall) []*FlatCall {
 	if data == nil {
@@ -79,6 +79,40 @@ func (testSuite *BedrockTracersTestSuite) TestTraceBlockByHash() {
 		bedrockBlock:    big.NewInt(5_003_318),
 	}
 
+	tx1, txs := traceBlockTxs()
+
+	mockTraceBlock(ctx, testSuite, "testdata/goerli_bedrock_block_trace_5003318.json")
+	blkHash := EthCommon.HexToHash("0x4503cbd671b3ca292e9f54998b2d566b705a32a178fc467f311c79b43e8e1774")
+	m, err := c.TraceBlockByHash(ctx, blkHash, txs)
+	testSuite.NoError(err)
+
+	testSuite.Equal(len(m), 2)
+	testSuite.NotNil(m[tx1.Hex()])
+}
+
+func mockTraceBlock(ctx context.Context, testSuite *BedrockTracersTestSuite, txFileData string) {
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+			file, err := os.ReadFile(txFileData)
+			testSuite.NoError(err)
+			rawMessage := json.RawMessage(file)
+			*r = rawMessage
+		},
+	).Once()
+}
+
+// traceBlockTxs returns the transactions of the block of the goerli_bedrock_block_trace_5003318.json traces
+func traceBlockTxs() (EthCommon.Hash, []BedrockRPCTransaction) {
 	tx1 := EthCommon.HexToHash("0x035437471437d2e61be662be806ea7a3603e37230e13f1c04e36e8ca891e9611")
 	tx2 := EthCommon.HexToHash("0x6103c9a945fabd69b2cfe25cd0f5c9ebe73b7f68f4fed2c68b2cfdd8429a6a88")
 	gasPrice := big.NewInt(10000)
@@ -113,20 +147,31 @@ func (testSuite *BedrockTracersTestSuite) TestTraceBlockByHash() {
 			},
 		},
 	}
+	return tx1, txs
+}
 
-	mockTraceBlock(ctx, testSuite, "testdata/goerli_bedrock_block_trace_5003318.json")
-	blkHash := EthCommon.HexToHash("0x4503cbd671b3ca292e9f54998b2d566b705a32a178fc467f311c79b43e8e1774")
-	m, err := c.TraceBlockByHash(ctx, blkHash, txs)
-	testSuite.NoError(err)
+// TestTraceBlockByHash_Cached tests that block traces are read from the trace cache.
+func (testSuite *BedrockTracersTestSuite) TestTraceBlockByHash_Cached() {
+	ctx := context.Background()
 
-	testSuite.Equal(len(m), 2)
-	testSuite.NotNil(m[tx1.Hex()])
-}
+	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tracerSpec{UseGethTracer: true}, time.Second*120, 10, nil)
+	testSuite.NoError(err)
+	c := &Client{
+		c:               testSuite.mockJSONRPC,
+		g:               testSuite.mockGraphQL,
+		currencyFetcher: testSuite.mockCurrencyFetcher,
+		tc:              testBedrockTraceConfig,
+		p:               params.GoerliChainConfig,
+		traceSemaphore:  semaphore.NewWeighted(100),
+		bedrockBlock:    big.NewInt(5_003_318),
+		traceCache:      traceCache,
+		traceByBlock:    true,
+	}
 
-func mockTraceBlock(ctx context.Context, testSuite *BedrockTracersTestSuite, txFileData string) {
+	tx1, txs := traceBlockTxs()
 	testSuite.mockJSONRPC.On(
 		"CallContext",
-		ctx,
+		mock.Anything,
 		mock.Anything,
 		"debug_traceBlockByHash",
 		mock.Anything,
@@ -136,12 +181,21 @@ func mockTraceBlock(ctx context.Context, testSuite *BedrockTracersTestSuite, txF
 	).Run(
 		func(args mock.Arguments) {
 			r := args.Get(1).(*json.RawMessage)
-			file, err := os.ReadFile(txFileData)
+			file, err := os.ReadFile("testdata/goerli_bedrock_block_trace_5003318.json")
 			testSuite.NoError(err)
-			rawMessage := json.RawMessage(file)
-			*r = rawMessage
+			*r = json.RawMessage(file)
 		},
 	).Once()
+	blkHash := EthCommon.HexToHash("0x4503cbd671b3ca292e9f54998b2d566b705a32a178fc467f311c79b43e8e1774")
+
+	// The block is only traced once
+	for i := 0; i < 2; i++ {
+		m, err := c.TraceBlockByHash(ctx, blkHash, txs)
+		testSuite.NoError(err)
+		testSuite.Equal(len(m), 2)
+		testSuite.NotNil(m[tx1.Hex()])
+	}
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
 }
 
 //nolint:unparam
@@ -168,7 +222,7 @@ func (testSuite *BedrockTracersTestSuite) TestTracedCachedTransaction() {
 	ctx := context.Background()
 
 	tspec := tracerSpec{TracerPath: "call_tracer.js", UseGethTracer: true}
-	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tspec, time.Second*120, 10)
+	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tspec, time.Second*120, 10, nil)
 	testSuite.NoError(err)
 
 	c := &Client{
@@ -248,7 +302,7 @@ func (testSuite *BedrockTracersTestSuite) TestErrTracedCachedTransaction() {
 	ctx := context.Background()
 
 	tspec := tracerSpec{TracerPath: "call_tracer.js", UseGethTracer: true}
-	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tspec, time.Second*120, 10)
+	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tspec, time.Second*120, 10, nil)
 	testSuite.NoError(err)
 
 	c := &Client{
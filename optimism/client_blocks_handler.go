@@ -83,18 +83,111 @@ func (ec *Client) getBlock(
 	return &head, &body, &raw, nil
 }
 
+// getParsedBlock constructs a [RosettaTypes.Block] from a pre-bedrock header and body.
+// If otherTransactionsThreshold is positive and the block contains more transactions than it,
+// the transactions are not populated and their identifiers are returned instead.
 func (ec *Client) getParsedBlock(
 	ctx context.Context,
 	head *types.Header,
 	body *rpcBlock,
+	otherTransactionsThreshold int,
 ) (
 	*RosettaTypes.Block,
+	[]*RosettaTypes.TransactionIdentifier,
+	error,
+) {
+	if otherTransactionsThreshold > 0 && len(body.Transactions) > otherTransactionsThreshold {
+		txs := make([]*types.Transaction, len(body.Transactions))
+		otherTxs := make([]*RosettaTypes.TransactionIdentifier, len(body.Transactions))
+		for i, tx := range body.Transactions {
+			txs[i] = tx.tx
+			otherTxs[i] = &RosettaTypes.TransactionIdentifier{
+				Hash: tx.tx.Hash().Hex(),
+			}
+		}
+		block := legacyBlockSkeleton(types.NewBlockWithHeader(head).WithBody(txs, nil))
+		block.Transactions = []*RosettaTypes.Transaction{}
+		return block, otherTxs, nil
+	}
+
+	txs, loadedTxs, err := ec.loadLegacyTransactions(ctx, head, body.Hash, body.Transactions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block := types.NewBlockWithHeader(head).WithBody(
+		txs,
+		nil, // Sequencer blocks do not have uncles with instant confirmation
+	)
+
+	rosettaBlock := legacyBlockSkeleton(block)
+	populatedTxs, err := ec.populateTransactions(ctx, rosettaBlock.BlockIdentifier, block, loadedTxs)
+	if err != nil {
+		return nil, nil, err
+	}
+	rosettaBlock.Transactions = populatedTxs
+
+	return rosettaBlock, nil, nil
+}
+
+// getParsedTransaction populates a single pre-bedrock transaction of a block.
+func (ec *Client) getParsedTransaction(
+	ctx context.Context,
+	head *types.Header,
+	blockHash common.Hash,
+	rpcTx rpcTransaction,
+) (*RosettaTypes.Transaction, error) {
+	txs, loadedTxs, err := ec.loadLegacyTransactions(ctx, head, blockHash, []rpcTransaction{rpcTx})
+	if err != nil {
+		return nil, err
+	}
+
+	block := types.NewBlockWithHeader(head).WithBody(txs, nil)
+	populatedTxs, err := ec.populateTransactions(ctx, nil, block, loadedTxs)
+	if err != nil {
+		return nil, err
+	}
+
+	return populatedTxs[0], nil
+}
+
+// legacyBlockSkeleton returns a [RosettaTypes.Block] with everything but the transactions populated.
+func legacyBlockSkeleton(block *types.Block) *RosettaTypes.Block {
+	blockIdentifier := &RosettaTypes.BlockIdentifier{
+		Hash:  block.Hash().String(),
+		Index: block.Number().Int64(),
+	}
+
+	parentBlockIdentifier := blockIdentifier
+	if blockIdentifier.Index != GenesisBlockIndex {
+		parentBlockIdentifier = &RosettaTypes.BlockIdentifier{
+			Hash:  block.ParentHash().Hex(),
+			Index: blockIdentifier.Index - 1,
+		}
+	}
+
+	return &RosettaTypes.Block{
+		BlockIdentifier:       blockIdentifier,
+		ParentBlockIdentifier: parentBlockIdentifier,
+		Timestamp:             convertTime(block.Time()),
+	}
+}
+
+// loadLegacyTransactions fetches the receipts and traces for the given pre-bedrock transactions of a block.
+func (ec *Client) loadLegacyTransactions(
+	ctx context.Context,
+	head *types.Header,
+	blockHash common.Hash,
+	rpcTxs []rpcTransaction,
+) (
+	[]*types.Transaction,
+	[]*legacyTransaction,
 	error,
 ) {
 	// Get all transaction receipts
-	receipts, err := ec.getBlockReceipts(ctx, body.Hash, body.Transactions)
+	receipts, err := ec.getBlockReceipts(ctx, blockHash, rpcTxs)
 	if err != nil {
-		return nil, fmt.Errorf("%w: could not get pre-bedrock receipts for %x", err, body.Hash[:])
+		return nil, nil, fmt.Errorf("%w: could not get pre-bedrock receipts for %x", err, blockHash[:])
 	}
 
 	// Get block traces (not possible to make idempotent block transaction trace requests)
@@ -106,21 +199,21 @@ func (ec *Client) getParsedBlock(
 	var addTraces bool
 	if head.Number.Int64() != GenesisBlockIndex { // not possible to get traces at genesis
 		addTraces = true
-		traces, err = ec.getTransactionTraces(ctx, body.Transactions)
+		traces, err = ec.getTransactionTraces(ctx, rpcTxs)
 		if err != nil {
-			return nil, fmt.Errorf("%w: could not get traces for all txs in block %x", err, body.Hash[:])
+			return nil, nil, fmt.Errorf("%w: could not get traces for all txs in block %x", err, blockHash[:])
 		}
 	}
 
 	// Convert all txs to loaded txs
-	txs := make([]*types.Transaction, len(body.Transactions))
-	loadedTxs := make([]*legacyTransaction, len(body.Transactions))
-	for i, tx := range body.Transactions {
+	txs := make([]*types.Transaction, len(rpcTxs))
+	loadedTxs := make([]*legacyTransaction, len(rpcTxs))
+	for i, tx := range rpcTxs {
 		txs[i] = tx.tx
 		receipt := receipts[i]
 
 		var feeAmount *big.Int
-		if feeAmountInDupTx := originalFeeAmountInDupTx[body.Hash.Hex()]; feeAmountInDupTx == "" {
+		if feeAmountInDupTx := originalFeeAmountInDupTx[blockHash.Hex()]; feeAmountInDupTx == "" {
 			gasUsedBig := new(big.Int).SetUint64(receipt.GasUsed)
 			l2feeAmount := gasUsedBig.Mul(gasUsedBig, txs[i].GasPrice())
 			feeAmount = l2feeAmount.Add(l2feeAmount, receipts[i].L1Fee)
@@ -149,35 +242,7 @@ func (ec *Client) getParsedBlock(
 		loadedTxs[i].Trace = traces[i]
 	}
 
-	block := types.NewBlockWithHeader(head).WithBody(
-		txs,
-		nil, // Sequencer blocks do not have uncles with instant confirmation
-	)
-
-	blockIdentifier := &RosettaTypes.BlockIdentifier{
-		Hash:  block.Hash().String(),
-		Index: block.Number().Int64(),
-	}
-
-	parentBlockIdentifier := blockIdentifier
-	if blockIdentifier.Index != GenesisBlockIndex {
-		parentBlockIdentifier = &RosettaTypes.BlockIdentifier{
-			Hash:  block.ParentHash().Hex(),
-			Index: blockIdentifier.Index - 1,
-		}
-	}
-
-	populatedTxs, err := ec.populateTransactions(ctx, blockIdentifier, block, loadedTxs)
-	if err != nil {
-		return nil, err
-	}
-
-	return &RosettaTypes.Block{
-		BlockIdentifier:       blockIdentifier,
-		ParentBlockIdentifier: parentBlockIdentifier,
-		Timestamp:             convertTime(block.Time()),
-		Transactions:          populatedTxs,
-	}, nil
+	return txs, loadedTxs, nil
 }
 
 //nolint:unparam
@@ -199,7 +264,7 @@ func (ec *Client) populateTransactions(
 			// These are tx across L1 and L2. These cost zero gas as they're manufactured by the sequencer
 			if from == zeroAddr {
 				tx.FeeAmount.SetUint64(0)
-			} else if (to == gasPriceOracleAddr.Hex()) && (from == gasPriceOracleOwnerMainnet.Hex() || from == gasPriceOracleOwnerKovan.Hex() || from == gasPriceOracleOwnerGoerli.Hex()) {
+			} else if to == gasPriceOracleAddr.Hex() && ec.isGasPriceOracleOwner(from) {
 				// The sequencer doesn't charge the owner of the gpo.
 				// Set the fee mount to zero to not affect gpo owner balances
 				tx.FeeAmount.SetUint64(0)
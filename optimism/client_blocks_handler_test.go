@@ -257,7 +257,7 @@ func (testSuite *ClientBlocksHandlerTestSuite) TestBlockCurrent_TraceCache() {
 	testSuite.NoError(err)
 
 	tspec := tracerSpec{TracerPath: "call_tracer.js"}
-	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tspec, time.Second*120, 10)
+	traceCache, err := NewTraceCache(testSuite.mockJSONRPC, tspec, time.Second*120, 10, nil)
 	testSuite.NoError(err)
 
 	c := &Client{
@@ -0,0 +1,114 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"math/big"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var erc20TransferTopic = crypto.Keccak256Hash([]byte(erc20TransferEventLogTopics))
+
+// bridgeTransfer describes an ERC20 transfer made by the L2StandardBridge to finalize a deposit or initiate a withdrawal.
+type bridgeTransfer struct {
+	event   string
+	l1Token EthCommon.Address
+	l2Token EthCommon.Address
+	// counterpart is the L1 account on the other side of the bridge:
+	// the sender of a deposit, or the recipient of a withdrawal.
+	counterpart EthCommon.Address
+	extraData   []byte
+}
+
+// matchBridgeTransfers pairs the DepositFinalized and WithdrawalInitiated events of the L2StandardBridge with
+// the ERC20 Transfer logs that moved the bridged tokens. The result is keyed by the index of the Transfer log.
+//
+// The bridge mints (or releases) deposited tokens and burns (or escrows) withdrawn tokens before emitting its event,
+// so each event is matched with the latest preceding Transfer of the same token and amount to or from the same account.
+func matchBridgeTransfers(logs []*EthTypes.Log) map[int]*bridgeTransfer {
+	matched := make(map[int]*bridgeTransfer)
+	match := func(before int, token, account EthCommon.Address, amount *big.Int, deposit bool, transfer *bridgeTransfer) {
+		for i := before - 1; i >= 0; i-- {
+			log := logs[i]
+			if _, ok := matched[i]; ok || log.Address != token || len(log.Topics) != TopicsInErc20Transfer || log.Topics[0] != erc20TransferTopic {
+				continue
+			}
+			party := log.Topics[1]
+			if deposit {
+				party = log.Topics[2]
+			}
+			if *ConvertEVMTopicHashToAddress(&party) == account && new(big.Int).SetBytes(log.Data).Cmp(amount) == 0 {
+				matched[i] = transfer
+				return
+			}
+		}
+	}
+
+	for i, log := range logs {
+		if log.Address != predeploys.L2StandardBridgeAddr {
+			continue
+		}
+		if deposit, err := standardBridgeEvents.ParseDepositFinalized(*log); err == nil {
+			match(i, deposit.L2Token, deposit.To, deposit.Amount, true, &bridgeTransfer{
+				event:       "DepositFinalized",
+				l1Token:     deposit.L1Token,
+				l2Token:     deposit.L2Token,
+				counterpart: deposit.From,
+				extraData:   deposit.ExtraData,
+			})
+		}
+		if withdrawal, err := standardBridgeEvents.ParseWithdrawalInitiated(*log); err == nil {
+			match(i, withdrawal.L2Token, withdrawal.From, withdrawal.Amount, false, &bridgeTransfer{
+				event:       "WithdrawalInitiated",
+				l1Token:     withdrawal.L1Token,
+				l2Token:     withdrawal.L2Token,
+				counterpart: withdrawal.To,
+				extraData:   withdrawal.ExtraData,
+			})
+		}
+	}
+
+	return matched
+}
+
+// apply marks the operations of a bridged ERC20 transfer with the bridge details as metadata. With retype,
+// mints and burns are also given the bridge operation types, while transfers to or from the bridge escrow
+// keep their type.
+func (t *bridgeTransfer) apply(ops []*RosettaTypes.Operation, retype bool) {
+	for _, op := range ops {
+		if retype {
+			switch op.Type {
+			case ERC20MintOpType:
+				op.Type = ERC20BridgeMintOpType
+			case ERC20BurnOpType:
+				op.Type = ERC20BridgeBurnOpType
+			}
+		}
+		if op.Metadata == nil {
+			op.Metadata = make(map[string]interface{})
+		}
+		op.Metadata["bridge_event"] = t.event
+		op.Metadata["l1_token"] = t.l1Token.Hex()
+		op.Metadata["l2_token"] = t.l2Token.Hex()
+		op.Metadata["counterpart"] = t.counterpart.Hex()
+		op.Metadata["extra_data"] = hexutil.Encode(t.extraData)
+	}
+}
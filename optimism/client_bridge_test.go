@@ -0,0 +1,137 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func erc20TransferLog(token, from, to EthCommon.Address, amount int64) *EthTypes.Log {
+	return &EthTypes.Log{
+		Address: token,
+		Topics: []EthCommon.Hash{
+			erc20TransferTopic,
+			EthCommon.BytesToHash(from.Bytes()),
+			EthCommon.BytesToHash(to.Bytes()),
+		},
+		Data: EthCommon.BigToHash(big.NewInt(amount)).Bytes(),
+	}
+}
+
+func bridgeEventLog(t *testing.T, name string, l1Token, l2Token, from, to EthCommon.Address, amount int64) *EthTypes.Log {
+	return packEventLog(
+		t,
+		bindings.L2StandardBridgeMetaData,
+		predeploys.L2StandardBridgeAddr,
+		name,
+		[]EthCommon.Hash{
+			EthCommon.BytesToHash(l1Token.Bytes()),
+			EthCommon.BytesToHash(l2Token.Bytes()),
+			EthCommon.BytesToHash(from.Bytes()),
+		},
+		to,
+		big.NewInt(amount),
+		[]byte{0x01},
+	)
+}
+
+func TestMatchBridgeTransfers(t *testing.T) {
+	zero := EthCommon.Address{}
+	user := EthCommon.HexToAddress("0x095E7BAea6a6c7c4c2DfeB977eFac326aF552d87")
+	l1User := EthCommon.HexToAddress("0xb0b0000000000000000000000000000000000001")
+	l1Token := EthCommon.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+	l2Token := EthCommon.HexToAddress("0xDA10009cBd5D07dd0CeCc66161FC93D7c9000da1")
+	nativeToken := EthCommon.HexToAddress("0x4200000000000000000000000000000000000042")
+	l1NativeToken := EthCommon.HexToAddress("0x4200000000000000000000000000000000000043")
+
+	logs := []*EthTypes.Log{
+		// 0: an unrelated mint of the same token, which must not be mistaken for the deposit
+		erc20TransferLog(l2Token, zero, user, 100),
+		// 1-2: a deposit minting the L2 token
+		erc20TransferLog(l2Token, zero, user, 100),
+		bridgeEventLog(t, "DepositFinalized", l1Token, l2Token, l1User, user, 100),
+		// 3-4: a withdrawal burning the L2 token
+		erc20TransferLog(l2Token, user, zero, 40),
+		bridgeEventLog(t, "WithdrawalInitiated", l1Token, l2Token, user, l1User, 40),
+		// 5-6: a withdrawal of a native L2 token escrowed by the bridge
+		erc20TransferLog(nativeToken, user, predeploys.L2StandardBridgeAddr, 7),
+		bridgeEventLog(t, "WithdrawalInitiated", l1NativeToken, nativeToken, user, l1User, 7),
+		// 7: an ETH withdrawal has no matching transfer
+		bridgeEventLog(t, "WithdrawalInitiated", zero, predeploys.LegacyERC20ETHAddr, user, l1User, 9),
+	}
+
+	matched := matchBridgeTransfers(logs)
+	assert.Len(t, matched, 3)
+	assert.Equal(t, &bridgeTransfer{
+		event:       "DepositFinalized",
+		l1Token:     l1Token,
+		l2Token:     l2Token,
+		counterpart: l1User,
+		extraData:   []byte{0x01},
+	}, matched[1])
+	assert.Equal(t, "WithdrawalInitiated", matched[3].event)
+	assert.Equal(t, l1User, matched[3].counterpart)
+	assert.Equal(t, nativeToken, matched[5].l2Token)
+
+	currency := &RosettaTypes.Currency{Symbol: "DAI", Decimals: 18}
+	metadata := func(event string, l1Token, l2Token EthCommon.Address) map[string]interface{} {
+		return map[string]interface{}{
+			"bridge_event": event,
+			"l1_token":     l1Token.Hex(),
+			"l2_token":     l2Token.Hex(),
+			"counterpart":  l1User.Hex(),
+			"extra_data":   "0x01",
+		}
+	}
+
+	// Without retyping, mints and burns keep their type and only gain the bridge metadata
+	mintOps := Erc20Ops(logs[1], currency, 0)
+	matched[1].apply(mintOps, false)
+	assert.Equal(t, ERC20MintOpType, mintOps[0].Type)
+	assert.Equal(t, "100", mintOps[0].Amount.Value)
+	assert.Equal(t, metadata("DepositFinalized", l1Token, l2Token), mintOps[0].Metadata)
+
+	burnOps := Erc20Ops(logs[3], currency, 0)
+	matched[3].apply(burnOps, false)
+	assert.Equal(t, ERC20BurnOpType, burnOps[0].Type)
+	assert.Equal(t, metadata("WithdrawalInitiated", l1Token, l2Token), burnOps[0].Metadata)
+
+	mintOps = Erc20Ops(logs[1], currency, 0)
+	matched[1].apply(mintOps, true)
+	assert.Equal(t, ERC20BridgeMintOpType, mintOps[0].Type)
+	assert.Equal(t, "100", mintOps[0].Amount.Value)
+	assert.Equal(t, metadata("DepositFinalized", l1Token, l2Token), mintOps[0].Metadata)
+
+	burnOps = Erc20Ops(logs[3], currency, 0)
+	matched[3].apply(burnOps, true)
+	assert.Equal(t, ERC20BridgeBurnOpType, burnOps[0].Type)
+	assert.Equal(t, "-40", burnOps[0].Amount.Value)
+
+	escrowOps := Erc20Ops(logs[5], currency, 0)
+	matched[5].apply(escrowOps, true)
+	assert.Len(t, escrowOps, 2)
+	for _, op := range escrowOps {
+		assert.Equal(t, ERC20TransferOpType, op.Type)
+		assert.Equal(t, metadata("WithdrawalInitiated", l1NativeToken, nativeToken), op.Metadata)
+	}
+}
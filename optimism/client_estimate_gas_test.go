@@ -23,6 +23,7 @@ import (
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
 
 	"github.com/stretchr/testify/mock"
@@ -102,3 +103,43 @@ func (testSuite *ClientEstimateGasSuite) TestClientEstimateGas() {
 	testSuite.Equal(uint64(1), resp)
 	testSuite.NoError(err)
 }
+
+// TestClientEstimateGasAccessList tests that [Client.EstimateGas] estimates the gas
+// with the access list of the message applied.
+func (testSuite *ClientEstimateGasSuite) TestClientEstimateGasAccessList() {
+	ctx := context.Background()
+	from := common.HexToAddress("0xE550f300E477C60CE7e7172d12e5a27e9379D2e3")
+	to := common.HexToAddress("0xaD6D458402F60fD3Bd25163575031ACDce07538D")
+	accessList := types.AccessList{
+		{
+			Address:     to,
+			StorageKeys: []common.Hash{common.HexToHash("0x01")},
+		},
+	}
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"eth_estimateGas",
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			arg := args.Get(3).(map[string]interface{})
+			testSuite.Equal(accessList, arg["accessList"])
+
+			r := args.Get(1).(*hexutil.Uint64)
+			*r = hexutil.Uint64(23400)
+		},
+	).Once()
+
+	resp, err := testSuite.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:       from,
+		To:         &to,
+		AccessList: accessList,
+	})
+	testSuite.NoError(err)
+	testSuite.Equal(uint64(23400), resp)
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// LatestInclusion considers a transaction included as soon as it is in the latest block.
+	LatestInclusion = "latest"
+
+	// SafeInclusion waits for the block of a transaction to be derived from data posted to L1.
+	SafeInclusion = "safe"
+
+	// FinalizedInclusion waits for the block of a transaction to be derived from finalized L1 data.
+	FinalizedInclusion = "finalized"
+)
+
+// Inclusion describes a transaction that was included in a block at the requested safety level.
+type Inclusion struct {
+	BlockIdentifier   *RosettaTypes.BlockIdentifier
+	Status            string
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int
+
+	// Fee is the total fee paid by the sender, including the L1 data fee and operator fee.
+	Fee *big.Int
+}
+
+// TransactionInclusion returns the inclusion of a transaction once its block has reached the given
+// safety level. It returns nil while the transaction is pending or its block is not yet safe enough.
+func (ec *Client) TransactionInclusion(
+	ctx context.Context,
+	hash EthCommon.Hash,
+	level string,
+) (*Inclusion, error) {
+	var raw json.RawMessage
+	if err := ec.c.CallContext(ctx, &raw, EthGetTransactionReceipt, hash); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var receipt L2Receipt
+	if err := json.Unmarshal(raw, &receipt); err != nil {
+		return nil, err
+	}
+	if receipt.BlockNumber == nil || receipt.Status == nil || receipt.GasUsed == nil {
+		return nil, fmt.Errorf("incomplete receipt for transaction %s", hash.Hex())
+	}
+
+	switch level {
+	case LatestInclusion:
+	case SafeInclusion, FinalizedInclusion:
+		header, err := ec.blockHeaderByTag(ctx, level)
+		if err != nil {
+			return nil, err
+		}
+		if header.Number.Uint64() < uint64(*receipt.BlockNumber) {
+			return nil, nil
+		}
+	default:
+		return nil, fmt.Errorf("%s is not a valid inclusion level", level)
+	}
+
+	status := FailureStatus
+	if *receipt.Status == 1 {
+		status = SuccessStatus
+	}
+
+	fee := new(big.Int)
+	var effectiveGasPrice *big.Int
+	if receipt.EffectiveGasPrice != nil {
+		effectiveGasPrice = new(big.Int).SetUint64(uint64(*receipt.EffectiveGasPrice))
+		fee.Mul(effectiveGasPrice, new(big.Int).SetUint64(uint64(*receipt.GasUsed)))
+	}
+	if receipt.L1Fee != nil {
+		fee.Add(fee, new(big.Int).SetUint64(uint64(*receipt.L1Fee)))
+	}
+	if operatorFee := receipt.OperatorFee(); operatorFee != nil {
+		fee.Add(fee, operatorFee)
+	}
+
+	return &Inclusion{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  receipt.BlockHash.Hex(),
+			Index: int64(*receipt.BlockNumber),
+		},
+		Status:            status,
+		GasUsed:           uint64(*receipt.GasUsed),
+		EffectiveGasPrice: effectiveGasPrice,
+		Fee:               fee,
+	}, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const inclusionReceipt = `{
+	"blockHash": "0x0b9e0e2d8b9b8b1aa0ff2a14ac8a4bcb0f1dca2a9ab4bf12c3c45c8e8a9e8e1c",
+	"blockNumber": "0x64",
+	"transactionHash": "0x4d7c6f3b0bb4c7bd1b2fb12fd0a3e4e4a0b1e8c1b6e27df1c1d5e3c8a7f6b5e4",
+	"transactionIndex": "0x1",
+	"status": "0x1",
+	"gasUsed": "0x5208",
+	"cumulativeGasUsed": "0x5208",
+	"effectiveGasPrice": "0x3b9aca00",
+	"l1Fee": "0x2710",
+	"logs": [],
+	"logsBloom": "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+}`
+
+// ClientInclusionTestSuite tests [Client.TransactionInclusion].
+type ClientInclusionTestSuite struct {
+	suite.Suite
+
+	mockJSONRPC *mocks.JSONRPC
+	client      *Client
+	hash        EthCommon.Hash
+}
+
+func TestClientInclusion(t *testing.T) {
+	suite.Run(t, new(ClientInclusionTestSuite))
+}
+
+func (testSuite *ClientInclusionTestSuite) SetupTest() {
+	testSuite.mockJSONRPC = &mocks.JSONRPC{}
+	testSuite.client = &Client{c: testSuite.mockJSONRPC}
+	testSuite.hash = EthCommon.HexToHash(mempoolTxHash)
+}
+
+func (testSuite *ClientInclusionTestSuite) TearDownTest() {
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
+
+func (testSuite *ClientInclusionTestSuite) mockReceipt(ctx context.Context, raw string) {
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, EthGetTransactionReceipt, testSuite.hash,
+	).Return(nil).Run(func(args mock.Arguments) {
+		r := args.Get(1).(*json.RawMessage)
+		*r = json.RawMessage(raw)
+	}).Once()
+}
+
+func (testSuite *ClientInclusionTestSuite) mockHeader(ctx context.Context, tag string, number int64) {
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_getBlockByNumber", tag, false,
+	).Return(nil).Run(func(args mock.Arguments) {
+		header := args.Get(1).(**rpcHeader)
+		*header = &rpcHeader{Header: EthTypes.Header{Number: big.NewInt(number)}}
+	}).Once()
+}
+
+func (testSuite *ClientInclusionTestSuite) TestPending() {
+	ctx := context.Background()
+	testSuite.mockReceipt(ctx, "null")
+
+	inclusion, err := testSuite.client.TransactionInclusion(ctx, testSuite.hash, LatestInclusion)
+	testSuite.NoError(err)
+	testSuite.Nil(inclusion)
+}
+
+func (testSuite *ClientInclusionTestSuite) TestLatest() {
+	ctx := context.Background()
+	testSuite.mockReceipt(ctx, inclusionReceipt)
+
+	inclusion, err := testSuite.client.TransactionInclusion(ctx, testSuite.hash, LatestInclusion)
+	testSuite.NoError(err)
+	testSuite.Equal(&Inclusion{
+		BlockIdentifier: &RosettaTypes.BlockIdentifier{
+			Hash:  "0x0b9e0e2d8b9b8b1aa0ff2a14ac8a4bcb0f1dca2a9ab4bf12c3c45c8e8a9e8e1c",
+			Index: 100,
+		},
+		Status:            SuccessStatus,
+		GasUsed:           21000,
+		EffectiveGasPrice: big.NewInt(1000000000),
+		// 21000 * 1 gwei + 10000 L1 fee
+		Fee: big.NewInt(21000000010000),
+	}, inclusion)
+}
+
+func (testSuite *ClientInclusionTestSuite) TestSafe_NotYetSafe() {
+	ctx := context.Background()
+	testSuite.mockReceipt(ctx, inclusionReceipt)
+	testSuite.mockHeader(ctx, SafeInclusion, 99)
+
+	inclusion, err := testSuite.client.TransactionInclusion(ctx, testSuite.hash, SafeInclusion)
+	testSuite.NoError(err)
+	testSuite.Nil(inclusion)
+}
+
+func (testSuite *ClientInclusionTestSuite) TestFinalized() {
+	ctx := context.Background()
+	testSuite.mockReceipt(ctx, inclusionReceipt)
+	testSuite.mockHeader(ctx, FinalizedInclusion, 100)
+
+	inclusion, err := testSuite.client.TransactionInclusion(ctx, testSuite.hash, FinalizedInclusion)
+	testSuite.NoError(err)
+	testSuite.Equal(int64(100), inclusion.BlockIdentifier.Index)
+	testSuite.Equal(SuccessStatus, inclusion.Status)
+}
+
+func (testSuite *ClientInclusionTestSuite) TestReverted() {
+	ctx := context.Background()
+	var receipt map[string]interface{}
+	testSuite.NoError(json.Unmarshal([]byte(inclusionReceipt), &receipt))
+	receipt["status"] = "0x0"
+	raw, err := json.Marshal(receipt)
+	testSuite.NoError(err)
+	testSuite.mockReceipt(ctx, string(raw))
+
+	inclusion, err := testSuite.client.TransactionInclusion(ctx, testSuite.hash, LatestInclusion)
+	testSuite.NoError(err)
+	testSuite.Equal(FailureStatus, inclusion.Status)
+}
+
+func (testSuite *ClientInclusionTestSuite) TestInvalidLevel() {
+	ctx := context.Background()
+	testSuite.mockReceipt(ctx, inclusionReceipt)
+
+	inclusion, err := testSuite.client.TransactionInclusion(ctx, testSuite.hash, "unsafe")
+	testSuite.EqualError(err, "unsafe is not a valid inclusion level")
+	testSuite.Nil(inclusion)
+}
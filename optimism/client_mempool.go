@@ -0,0 +1,391 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	OptimismRpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// rpcMempoolTx is the subset of a txpool_content entry we care about.
+type rpcMempoolTx struct {
+	Hash EthCommon.Hash `json:"hash"`
+}
+
+// rpcMempoolTxStatus is the subset of an eth_getTransactionByHash response telling whether it is mined.
+type rpcMempoolTxStatus struct {
+	BlockHash *EthCommon.Hash `json:"blockHash"`
+}
+
+// rpcTxPoolContent is the response of txpool_content, keyed by sender and then nonce.
+type rpcTxPoolContent struct {
+	Pending map[string]map[string]*rpcMempoolTx `json:"pending"`
+	Queued  map[string]map[string]*rpcMempoolTx `json:"queued"`
+}
+
+// Mempool returns the identifiers of all pending and queued transactions in the node's local pool.
+// txpool_content is preferred; if the node does not expose the txpool namespace, we fall back to
+// a long-lived pending transaction filter, which only reports transactions received since the first call.
+func (ec *Client) Mempool(ctx context.Context) ([]*RosettaTypes.TransactionIdentifier, error) {
+	hashes, err := ec.txPoolContentHashes(ctx)
+	if err != nil {
+		log.Printf("txpool_content unavailable, falling back to pending transaction filter: %v", err)
+		hashes, err = ec.pendingFilterHashes(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(hashes)
+	identifiers := make([]*RosettaTypes.TransactionIdentifier, len(hashes))
+	for i, hash := range hashes {
+		identifiers[i] = &RosettaTypes.TransactionIdentifier{Hash: hash}
+	}
+
+	return identifiers, nil
+}
+
+func (ec *Client) txPoolContentHashes(ctx context.Context) ([]string, error) {
+	var content rpcTxPoolContent
+	if err := ec.c.CallContext(ctx, &content, TxPoolContent); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var hashes []string
+	for _, pool := range []map[string]map[string]*rpcMempoolTx{content.Pending, content.Queued} {
+		for _, txsByNonce := range pool {
+			for _, tx := range txsByNonce {
+				if tx == nil {
+					continue
+				}
+				hash := tx.Hash.Hex()
+				if _, ok := seen[hash]; ok {
+					continue
+				}
+				seen[hash] = struct{}{}
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+// pendingTxFilter is a long-lived pending transaction filter. A filter only reports transactions
+// that arrive after it is installed, so the hashes it reports are kept until they are mined or dropped.
+type pendingTxFilter struct {
+	mu     sync.Mutex
+	id     string
+	hashes map[EthCommon.Hash]struct{}
+}
+
+// pendingFilterHashes polls the pending transaction filter of the client, installing it on first use.
+// Transactions that were already pending when the filter was installed are not reported.
+func (ec *Client) pendingFilterHashes(ctx context.Context) ([]string, error) {
+	f := &ec.pendingFilter
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hashes == nil {
+		f.hashes = make(map[EthCommon.Hash]struct{})
+	}
+	if f.id == "" {
+		if err := ec.installPendingFilter(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []EthCommon.Hash
+	if err := ec.c.CallContext(ctx, &changes, EthGetFilterChanges, f.id); err != nil {
+		// Nodes uninstall filters that are not polled for a while, so install it again
+		log.Printf("unable to poll pending transaction filter %s, reinstalling it: %v", f.id, err)
+		if err := ec.installPendingFilter(ctx); err != nil {
+			return nil, err
+		}
+		if err := ec.c.CallContext(ctx, &changes, EthGetFilterChanges, f.id); err != nil {
+			return nil, fmt.Errorf("%w: unable to get pending transaction filter changes", err)
+		}
+	}
+	for _, hash := range changes {
+		f.hashes[hash] = struct{}{}
+	}
+
+	if err := ec.prunePendingFilterHashes(ctx); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(f.hashes))
+	for hash := range f.hashes {
+		hashes = append(hashes, hash.Hex())
+	}
+
+	return hashes, nil
+}
+
+// installPendingFilter installs a new pending transaction filter. The caller must hold the filter lock.
+func (ec *Client) installPendingFilter(ctx context.Context) error {
+	var filterID string
+	if err := ec.c.CallContext(ctx, &filterID, EthNewPendingTransactionFilter); err != nil {
+		return fmt.Errorf("%w: unable to install pending transaction filter", err)
+	}
+	ec.pendingFilter.id = filterID
+	return nil
+}
+
+// prunePendingFilterHashes drops the hashes of the pending transaction filter that have been mined
+// or are no longer known to the node. The caller must hold the filter lock.
+func (ec *Client) prunePendingFilterHashes(ctx context.Context) error {
+	if len(ec.pendingFilter.hashes) == 0 {
+		return nil
+	}
+
+	hashes := make([]EthCommon.Hash, 0, len(ec.pendingFilter.hashes))
+	for hash := range ec.pendingFilter.hashes {
+		hashes = append(hashes, hash)
+	}
+	txs := make([]*rpcMempoolTxStatus, len(hashes))
+	reqs := make([]OptimismRpc.BatchElem, len(hashes))
+	for i, hash := range hashes {
+		reqs[i] = OptimismRpc.BatchElem{
+			Method: EthGetTransactionByHash,
+			Args:   []interface{}{hash.Hex()},
+			Result: &txs[i],
+		}
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return fmt.Errorf("%w: unable to get pending transactions", err)
+	}
+
+	for i, hash := range hashes {
+		if reqs[i].Error != nil {
+			continue
+		}
+		if txs[i] == nil || (txs[i].BlockHash != nil && *txs[i].BlockHash != (EthCommon.Hash{})) {
+			delete(ec.pendingFilter.hashes, hash)
+		}
+	}
+
+	return nil
+}
+
+// uninstallPendingFilter removes the pending transaction filter from the node, if one is installed.
+func (ec *Client) uninstallPendingFilter(ctx context.Context) {
+	f := &ec.pendingFilter
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.id == "" {
+		return
+	}
+	var uninstalled bool
+	if err := ec.c.CallContext(ctx, &uninstalled, EthUninstallFilter, f.id); err != nil {
+		log.Printf("unable to uninstall pending transaction filter %s: %v", f.id, err)
+	}
+	f.id = ""
+}
+
+// MempoolTransaction returns a pending transaction with its estimated operations.
+// Since the transaction has not been executed, the operations are derived from the
+// transaction fields alone (fee upper bound, native value transfer and ERC20 transfer calldata)
+// and are marked with [PendingStatus].
+func (ec *Client) MempoolTransaction(
+	ctx context.Context,
+	hash string,
+) (*RosettaTypes.Transaction, error) {
+	raw, extra, err := ec.pendingTransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx transaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return nil, err
+	}
+
+	ops, err := ec.mempoolOps(ctx, &tx, *extra.From)
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := tx.Hash().Hex()
+	if extra.TxHash != nil {
+		txHash = extra.TxHash.Hex()
+	}
+
+	metadata := map[string]interface{}{
+		"gas_limit": hexutil.EncodeUint64(tx.Gas()),
+		"gas_price": hexutil.EncodeBig(tx.GasPrice()),
+	}
+	if tx.Nonce != nil {
+		metadata["nonce"] = tx.Nonce.String()
+	}
+
+	return &RosettaTypes.Transaction{
+		TransactionIdentifier: &RosettaTypes.TransactionIdentifier{
+			Hash: txHash,
+		},
+		Operations: ops,
+		Metadata:   metadata,
+	}, nil
+}
+
+// PendingTransaction returns a transaction that has not been included yet along with its sender,
+// so that it can be replaced by a transaction with the same nonce.
+func (ec *Client) PendingTransaction(
+	ctx context.Context,
+	hash string,
+) (*EthTypes.Transaction, EthCommon.Address, error) {
+	raw, extra, err := ec.pendingTransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, EthCommon.Address{}, err
+	}
+
+	tx := new(EthTypes.Transaction)
+	if err := tx.UnmarshalJSON(raw); err != nil {
+		return nil, EthCommon.Address{}, err
+	}
+
+	return tx, *extra.From, nil
+}
+
+// pendingTransactionByHash fetches a transaction of the mempool. Transactions that
+// have already been included are reported as not found.
+func (ec *Client) pendingTransactionByHash(
+	ctx context.Context,
+	hash string,
+) (json.RawMessage, *TxExtraInfo, error) {
+	var raw json.RawMessage
+	if err := ec.c.CallContext(ctx, &raw, EthGetTransactionByHash, hash); err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, hash)
+	}
+
+	var extra TxExtraInfo
+	if err := json.Unmarshal(raw, &extra); err != nil {
+		return nil, nil, err
+	}
+	// Transactions that have already been included are no longer in the mempool
+	if extra.BlockHash != nil && *extra.BlockHash != (EthCommon.Hash{}) {
+		return nil, nil, fmt.Errorf("%w: %s is already included in block %s", ErrTransactionNotFound, hash, extra.BlockHash.Hex())
+	}
+	if extra.From == nil {
+		return nil, nil, fmt.Errorf("missing sender for pending transaction %s", hash)
+	}
+
+	return raw, &extra, nil
+}
+
+// mempoolOps estimates the operations of a pending transaction.
+func (ec *Client) mempoolOps(
+	ctx context.Context,
+	tx *transaction,
+	from EthCommon.Address,
+) ([]*RosettaTypes.Operation, error) {
+	var ops []*RosettaTypes.Operation
+	fromAddress := MustChecksum(from.Hex())
+
+	// Deposits are not charged a fee on L2
+	if !tx.IsDepositTx() {
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+		if fee.Sign() > 0 {
+			ops = append(ops, GenerateOp(0, nil, FeeOpType, PendingStatus, fromAddress, Amount(new(big.Int).Neg(fee), Currency), nil))
+		}
+	}
+
+	value := tx.GetValue()
+	if value != nil && value.Sign() > 0 {
+		opType := CallOpType
+		if tx.To() == nil {
+			opType = CreateOpType
+		}
+		fromOpIndex := int64(len(ops))
+		ops = append(ops, GenerateOp(fromOpIndex, nil, opType, PendingStatus, fromAddress, Amount(new(big.Int).Neg(value), Currency), nil))
+		if tx.To() != nil {
+			relatedOps := []*RosettaTypes.OperationIdentifier{{Index: fromOpIndex}}
+			ops = append(ops, GenerateOp(fromOpIndex+1, relatedOps, opType, PendingStatus, MustChecksum(tx.To().Hex()), Amount(value, Currency), nil))
+		}
+	}
+
+	erc20Ops, err := ec.mempoolERC20Ops(ctx, tx, fromAddress, int64(len(ops)))
+	if err != nil {
+		return nil, err
+	}
+	ops = append(ops, erc20Ops...)
+
+	return ops, nil
+}
+
+// mempoolERC20Ops decodes an ERC20 transfer from the calldata of a pending transaction.
+func (ec *Client) mempoolERC20Ops(
+	ctx context.Context,
+	tx *transaction,
+	fromAddress string,
+	startIndex int64,
+) ([]*RosettaTypes.Operation, error) {
+	if tx.To() == nil || tx.Data == nil {
+		return nil, nil
+	}
+	input := strings.ToLower(hexutil.Encode(*tx.Data))
+	if !strings.HasPrefix(input, erc20TransferSelector) {
+		return nil, nil
+	}
+	toAddress, amount, err := decodeAddressUint256(input[fnSelectorLen:])
+	if err != nil {
+		// Not a well-formed transfer, so there is nothing to estimate
+		return nil, nil
+	}
+
+	contractAddress := MustChecksum(tx.To().Hex())
+	if !ec.supportsToken(contractAddress) {
+		return nil, nil
+	}
+
+	var blockNum hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &blockNum, EthBlockNumber); err != nil {
+		return nil, err
+	}
+	currency, err := ec.currencyFetcher.FetchCurrency(ctx, uint64(blockNum), contractAddress)
+	if err != nil {
+		log.Printf("error while fetching currency details for currency: %s: %v", contractAddress, err)
+		currency = &RosettaTypes.Currency{
+			Symbol:   defaultERC20Symbol,
+			Decimals: defaultERC20Decimals,
+			Metadata: map[string]interface{}{
+				ContractAddressKey: contractAddress,
+			},
+		}
+	}
+
+	relatedOps := []*RosettaTypes.OperationIdentifier{{Index: startIndex}}
+	return []*RosettaTypes.Operation{
+		GenerateOp(startIndex, nil, ERC20TransferOpType, PendingStatus, fromAddress, Amount(new(big.Int).Neg(amount), currency), nil),
+		GenerateOp(startIndex+1, relatedOps, ERC20TransferOpType, PendingStatus, MustChecksum(toAddress.Hex()), Amount(amount, currency), nil),
+	}, nil
+}
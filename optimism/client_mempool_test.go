@@ -0,0 +1,351 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	mempoolTxHash   = "0x4d7c6f3b0bb4c7bd1b2fb12fd0a3e4e4a0b1e8c1b6e27df1c1d5e3c8a7f6b5e4"
+	mempoolSender   = "0x8C8e1a2A4e5D8dc5CF1E56bD1F4AEF3B2E19f0A0"
+	mempoolReceiver = "0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984"
+)
+
+// ClientMempoolTestSuite tests [Client.Mempool], [Client.MempoolTransaction] and [Client.PendingTransaction].
+type ClientMempoolTestSuite struct {
+	suite.Suite
+
+	mockJSONRPC         *mocks.JSONRPC
+	mockGraphQL         *mocks.GraphQL
+	mockCurrencyFetcher *mocks.CurrencyFetcher
+	client              *Client
+}
+
+func TestClientMempool(t *testing.T) {
+	suite.Run(t, new(ClientMempoolTestSuite))
+}
+
+func (testSuite *ClientMempoolTestSuite) SetupTest() {
+	testSuite.mockJSONRPC = &mocks.JSONRPC{}
+	testSuite.mockGraphQL = &mocks.GraphQL{}
+	testSuite.mockCurrencyFetcher = &mocks.CurrencyFetcher{}
+	testSuite.client = &Client{
+		c:               testSuite.mockJSONRPC,
+		g:               testSuite.mockGraphQL,
+		currencyFetcher: testSuite.mockCurrencyFetcher,
+		traceSemaphore:  semaphore.NewWeighted(100),
+	}
+}
+
+func (testSuite *ClientMempoolTestSuite) TearDownTest() {
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+	testSuite.mockCurrencyFetcher.AssertExpectations(testSuite.T())
+}
+
+func (testSuite *ClientMempoolTestSuite) mockGetTransactionByHash(ctx context.Context, raw string) {
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_getTransactionByHash", mempoolTxHash,
+	).Return(nil).Run(func(args mock.Arguments) {
+		r := args.Get(1).(*json.RawMessage)
+		*r = json.RawMessage(raw)
+	}).Once()
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempool_TxPoolContent() {
+	ctx := context.Background()
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "txpool_content",
+	).Return(nil).Run(func(args mock.Arguments) {
+		r := args.Get(1).(*rpcTxPoolContent)
+		testSuite.NoError(json.Unmarshal([]byte(`{
+			"pending": {"`+mempoolSender+`": {"1": {"hash": "`+EthCommon.HexToHash("0x02").Hex()+`"}, "2": {"hash": "`+EthCommon.HexToHash("0x01").Hex()+`"}}},
+			"queued": {"`+mempoolReceiver+`": {"7": {"hash": "`+EthCommon.HexToHash("0x03").Hex()+`"}}}
+		}`), r))
+	}).Once()
+
+	identifiers, err := testSuite.client.Mempool(ctx)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.TransactionIdentifier{
+		{Hash: EthCommon.HexToHash("0x01").Hex()},
+		{Hash: EthCommon.HexToHash("0x02").Hex()},
+		{Hash: EthCommon.HexToHash("0x03").Hex()},
+	}, identifiers)
+}
+
+// mockPendingFilterChanges mocks a poll of the pending transaction filter.
+func (testSuite *ClientMempoolTestSuite) mockPendingFilterChanges(ctx context.Context, filterID string, hashes ...EthCommon.Hash) {
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_getFilterChanges", filterID,
+	).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*[]EthCommon.Hash)) = hashes
+	}).Once()
+}
+
+// mockPendingTxStatus mocks the lookup of the transactions tracked by the pending transaction filter.
+func (testSuite *ClientMempoolTestSuite) mockPendingTxStatus(ctx context.Context, mined map[EthCommon.Hash]bool) {
+	testSuite.mockJSONRPC.On(
+		"BatchCallContext", ctx, mock.Anything,
+	).Return(nil).Run(func(args mock.Arguments) {
+		reqs := args.Get(1).([]rpc.BatchElem)
+		testSuite.Len(reqs, len(mined))
+		for _, req := range reqs {
+			testSuite.Equal("eth_getTransactionByHash", req.Method)
+			isMined, ok := mined[EthCommon.HexToHash(req.Args[0].(string))]
+			testSuite.True(ok)
+			status := &rpcMempoolTxStatus{}
+			if isMined {
+				blockHash := EthCommon.HexToHash("0xb1")
+				status.BlockHash = &blockHash
+			}
+			*(req.Result.(**rpcMempoolTxStatus)) = status
+		}
+	}).Once()
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempool_PendingFilterFallback() {
+	ctx := context.Background()
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "txpool_content",
+	).Return(errors.New("the method txpool_content does not exist/is not available")).Twice()
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_newPendingTransactionFilter",
+	).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*string)) = "0xfilter"
+	}).Once()
+
+	testSuite.mockPendingFilterChanges(ctx, "0xfilter", EthCommon.HexToHash("0x01"))
+	testSuite.mockPendingTxStatus(ctx, map[EthCommon.Hash]bool{EthCommon.HexToHash("0x01"): false})
+	identifiers, err := testSuite.client.Mempool(ctx)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.TransactionIdentifier{
+		{Hash: EthCommon.HexToHash("0x01").Hex()},
+	}, identifiers)
+
+	// The filter is reused, keeping pending transactions and dropping mined ones
+	testSuite.mockPendingFilterChanges(ctx, "0xfilter", EthCommon.HexToHash("0x02"), EthCommon.HexToHash("0x03"))
+	testSuite.mockPendingTxStatus(ctx, map[EthCommon.Hash]bool{
+		EthCommon.HexToHash("0x01"): true,
+		EthCommon.HexToHash("0x02"): false,
+		EthCommon.HexToHash("0x03"): false,
+	})
+	identifiers, err = testSuite.client.Mempool(ctx)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.TransactionIdentifier{
+		{Hash: EthCommon.HexToHash("0x02").Hex()},
+		{Hash: EthCommon.HexToHash("0x03").Hex()},
+	}, identifiers)
+
+	testSuite.mockJSONRPC.On(
+		"CallContext", mock.Anything, mock.Anything, "eth_uninstallFilter", "0xfilter",
+	).Return(nil).Once()
+	testSuite.mockJSONRPC.On("Close").Once()
+	testSuite.client.Close()
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempool_PendingFilterReinstalled() {
+	ctx := context.Background()
+	testSuite.client.pendingFilter.id = "0xexpired"
+	testSuite.client.pendingFilter.hashes = map[EthCommon.Hash]struct{}{EthCommon.HexToHash("0x01"): {}}
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "txpool_content",
+	).Return(errors.New("the method txpool_content does not exist/is not available")).Once()
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_getFilterChanges", "0xexpired",
+	).Return(errors.New("filter not found")).Once()
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_newPendingTransactionFilter",
+	).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*string)) = "0xfilter"
+	}).Once()
+	testSuite.mockPendingFilterChanges(ctx, "0xfilter", EthCommon.HexToHash("0x02"))
+	testSuite.mockPendingTxStatus(ctx, map[EthCommon.Hash]bool{
+		EthCommon.HexToHash("0x01"): false,
+		EthCommon.HexToHash("0x02"): false,
+	})
+
+	identifiers, err := testSuite.client.Mempool(ctx)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.TransactionIdentifier{
+		{Hash: EthCommon.HexToHash("0x01").Hex()},
+		{Hash: EthCommon.HexToHash("0x02").Hex()},
+	}, identifiers)
+	testSuite.Equal("0xfilter", testSuite.client.pendingFilter.id)
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempoolTransaction_NativeTransfer() {
+	ctx := context.Background()
+	testSuite.mockGetTransactionByHash(ctx, `{
+		"blockHash": null,
+		"blockNumber": null,
+		"from": "`+mempoolSender+`",
+		"gas": "0x5208",
+		"gasPrice": "0x3b9aca00",
+		"hash": "`+mempoolTxHash+`",
+		"input": "0x",
+		"nonce": "0x5",
+		"to": "`+mempoolReceiver+`",
+		"type": "0x0",
+		"value": "0xde0b6b3a7640000"
+	}`)
+
+	tx, err := testSuite.client.MempoolTransaction(ctx, mempoolTxHash)
+	testSuite.NoError(err)
+	testSuite.Equal(mempoolTxHash, tx.TransactionIdentifier.Hash)
+	testSuite.Len(tx.Operations, 3)
+
+	testSuite.Equal(FeeOpType, tx.Operations[0].Type)
+	testSuite.Equal(PendingStatus, *tx.Operations[0].Status)
+	testSuite.Equal(mempoolSender, tx.Operations[0].Account.Address)
+	testSuite.Equal("-21000000000000", tx.Operations[0].Amount.Value)
+
+	testSuite.Equal(CallOpType, tx.Operations[1].Type)
+	testSuite.Equal(mempoolSender, tx.Operations[1].Account.Address)
+	testSuite.Equal("-1000000000000000000", tx.Operations[1].Amount.Value)
+
+	testSuite.Equal(CallOpType, tx.Operations[2].Type)
+	testSuite.Equal(mempoolReceiver, tx.Operations[2].Account.Address)
+	testSuite.Equal("1000000000000000000", tx.Operations[2].Amount.Value)
+	testSuite.Equal(int64(1), tx.Operations[2].RelatedOperations[0].Index)
+
+	testSuite.Equal("0x5", tx.Metadata["nonce"])
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempoolTransaction_ERC20Transfer() {
+	ctx := context.Background()
+	tokenAddress := "0x4200000000000000000000000000000000000042"
+	input := erc20TransferSelector +
+		"000000000000000000000000" + mempoolReceiver[2:] +
+		"00000000000000000000000000000000000000000000000000000000000003e8"
+	testSuite.mockGetTransactionByHash(ctx, `{
+		"blockHash": null,
+		"from": "`+mempoolSender+`",
+		"gas": "0xc350",
+		"gasPrice": "0x1",
+		"hash": "`+mempoolTxHash+`",
+		"input": "`+input+`",
+		"nonce": "0x6",
+		"to": "`+tokenAddress+`",
+		"type": "0x2",
+		"value": "0x0"
+	}`)
+	testSuite.mockJSONRPC.On(
+		"CallContext", ctx, mock.Anything, "eth_blockNumber",
+	).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*hexutil.Uint64)) = 100
+	}).Once()
+	currency := &RosettaTypes.Currency{
+		Symbol:   "OP",
+		Decimals: 18,
+		Metadata: map[string]interface{}{ContractAddressKey: tokenAddress},
+	}
+	testSuite.mockCurrencyFetcher.On("FetchCurrency", ctx, uint64(100), tokenAddress).Return(currency, nil).Once()
+
+	tx, err := testSuite.client.MempoolTransaction(ctx, mempoolTxHash)
+	testSuite.NoError(err)
+	testSuite.Len(tx.Operations, 3)
+	testSuite.Equal(FeeOpType, tx.Operations[0].Type)
+	testSuite.Equal(ERC20TransferOpType, tx.Operations[1].Type)
+	testSuite.Equal(mempoolSender, tx.Operations[1].Account.Address)
+	testSuite.Equal("-1000", tx.Operations[1].Amount.Value)
+	testSuite.Equal(currency, tx.Operations[1].Amount.Currency)
+	testSuite.Equal(ERC20TransferOpType, tx.Operations[2].Type)
+	testSuite.Equal(mempoolReceiver, tx.Operations[2].Account.Address)
+	testSuite.Equal("1000", tx.Operations[2].Amount.Value)
+	testSuite.Equal(PendingStatus, *tx.Operations[2].Status)
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempoolTransaction_NotFound() {
+	ctx := context.Background()
+	testSuite.mockGetTransactionByHash(ctx, "null")
+
+	tx, err := testSuite.client.MempoolTransaction(ctx, mempoolTxHash)
+	testSuite.Nil(tx)
+	testSuite.ErrorIs(err, ErrTransactionNotFound)
+}
+
+func (testSuite *ClientMempoolTestSuite) TestMempoolTransaction_AlreadyIncluded() {
+	ctx := context.Background()
+	testSuite.mockGetTransactionByHash(ctx, `{
+		"blockHash": "0x8b1e2b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718",
+		"blockNumber": "0x10",
+		"from": "`+mempoolSender+`",
+		"gas": "0x5208",
+		"gasPrice": "0x1",
+		"hash": "`+mempoolTxHash+`",
+		"input": "0x",
+		"nonce": "0x5",
+		"to": "`+mempoolReceiver+`",
+		"type": "0x0",
+		"value": "0x1"
+	}`)
+
+	tx, err := testSuite.client.MempoolTransaction(ctx, mempoolTxHash)
+	testSuite.Nil(tx)
+	testSuite.ErrorIs(err, ErrTransactionNotFound)
+}
+
+func (testSuite *ClientMempoolTestSuite) TestPendingTransaction() {
+	ctx := context.Background()
+	testSuite.mockGetTransactionByHash(ctx, `{
+		"blockHash": null,
+		"blockNumber": null,
+		"chainId": "0xa",
+		"from": "`+mempoolSender+`",
+		"gas": "0x5208",
+		"gasPrice": "0x3b9aca00",
+		"maxFeePerGas": "0x3b9aca00",
+		"maxPriorityFeePerGas": "0x5f5e100",
+		"hash": "`+mempoolTxHash+`",
+		"input": "0x",
+		"nonce": "0x5",
+		"to": "`+mempoolReceiver+`",
+		"type": "0x2",
+		"value": "0x1",
+		"accessList": [],
+		"v": "0x0",
+		"r": "0x1",
+		"s": "0x1"
+	}`)
+
+	tx, from, err := testSuite.client.PendingTransaction(ctx, mempoolTxHash)
+	testSuite.NoError(err)
+	testSuite.Equal(mempoolSender, from.Hex())
+	testSuite.Equal(uint64(5), tx.Nonce())
+	testSuite.Equal(int64(100000000), tx.GasTipCap().Int64())
+	testSuite.Equal(int64(1000000000), tx.GasFeeCap().Int64())
+}
+
+func (testSuite *ClientMempoolTestSuite) TestPendingTransaction_NotFound() {
+	ctx := context.Background()
+	testSuite.mockGetTransactionByHash(ctx, "null")
+
+	tx, _, err := testSuite.client.PendingTransaction(ctx, mempoolTxHash)
+	testSuite.Nil(tx)
+	testSuite.ErrorIs(err, ErrTransactionNotFound)
+}
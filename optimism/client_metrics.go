@@ -0,0 +1,94 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+	"github.com/inphi/optimism-rosetta/metrics"
+)
+
+// instrumentedJSONRPC records the latency and failures of the calls of a JSONRPC client
+type instrumentedJSONRPC struct {
+	JSONRPC
+}
+
+func instrumentJSONRPC(c JSONRPC) JSONRPC {
+	return &instrumentedJSONRPC{JSONRPC: c}
+}
+
+func (c *instrumentedJSONRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	start := time.Now()
+	err := c.JSONRPC.CallContext(ctx, result, method, args...)
+	metrics.RPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.RPCFailures.WithLabelValues(method).Inc()
+	}
+	return err
+}
+
+func (c *instrumentedJSONRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	if len(b) == 0 {
+		return c.JSONRPC.BatchCallContext(ctx, b)
+	}
+
+	method := b[0].Method
+	for _, elem := range b[1:] {
+		if elem.Method != method {
+			method = "batch"
+			break
+		}
+	}
+
+	start := time.Now()
+	err := c.JSONRPC.BatchCallContext(ctx, b)
+	metrics.RPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.RPCFailures.WithLabelValues(method).Inc()
+		return err
+	}
+	for _, elem := range b {
+		if elem.Error != nil {
+			metrics.RPCFailures.WithLabelValues(elem.Method).Inc()
+		}
+	}
+	return nil
+}
+
+// acquireTraceSemaphore acquires the trace semaphore, recording how many callers wait for it and for how long.
+func (ec *Client) acquireTraceSemaphore(ctx context.Context) error {
+	metrics.TraceSemaphoreQueue.Inc()
+	defer metrics.TraceSemaphoreQueue.Dec()
+
+	start := time.Now()
+	err := ec.traceSemaphore.Acquire(ctx, semaphoreTraceWeight)
+	metrics.TraceSemaphoreWait.Observe(time.Since(start).Seconds())
+	return err
+}
+
+// HeadLag returns the number of blocks between the latest and the safe head of the node.
+func (ec *Client) HeadLag(ctx context.Context) (int64, error) {
+	latest, err := ec.blockHeaderByTag(ctx, "latest")
+	if err != nil {
+		return 0, err
+	}
+	safe, err := ec.safeBlockHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return latest.Number.Int64() - safe.Number.Int64(), nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/inphi/optimism-rosetta/metrics"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInstrumentedJSONRPC(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := instrumentJSONRPC(mockJSONRPC)
+	failures := func(method string) float64 {
+		return testutil.ToFloat64(metrics.RPCFailures.WithLabelValues(method))
+	}
+
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_chainId").Return(nil).Once()
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_syncing").Return(errors.New("boom")).Once()
+	assert.NoError(t, c.CallContext(ctx, nil, "eth_chainId"))
+	assert.Error(t, c.CallContext(ctx, nil, "eth_syncing"))
+	assert.Equal(t, 0.0, failures("eth_chainId"))
+	assert.Equal(t, 1.0, failures("eth_syncing"))
+
+	// Failing elements of a batch are counted against their own method
+	batch := []rpc.BatchElem{
+		{Method: "eth_getTransactionReceipt"},
+		{Method: "eth_getBalance"},
+	}
+	mockJSONRPC.On("BatchCallContext", ctx, batch).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).([]rpc.BatchElem)[1].Error = errors.New("missing trie node")
+	}).Once()
+	before := failures("eth_getBalance")
+	assert.NoError(t, c.BatchCallContext(ctx, batch))
+	assert.Equal(t, before+1, failures("eth_getBalance"))
+	assert.Equal(t, 0.0, failures("eth_getTransactionReceipt"))
+
+	mockJSONRPC.AssertExpectations(t)
+}
+
+func TestHeadLag(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	c := &Client{c: mockJSONRPC}
+	mockHeader := func(tag string, number int64) {
+		mockJSONRPC.On(
+			"CallContext", ctx, mock.Anything, "eth_getBlockByNumber", tag, false,
+		).Return(nil).Run(func(args mock.Arguments) {
+			header := args.Get(1).(**rpcHeader)
+			*header = &rpcHeader{Header: EthTypes.Header{Number: big.NewInt(number)}}
+		}).Once()
+	}
+
+	mockHeader("latest", 120)
+	mockHeader("safe", 100)
+	lag, err := c.HeadLag(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), lag)
+
+	mockJSONRPC.AssertExpectations(t)
+}
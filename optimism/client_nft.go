@@ -0,0 +1,192 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	OptimismArtifacts "github.com/inphi/optimism-rosetta/optimism/utilities/artifacts"
+)
+
+// TopicsInErc721Transfer is the number of topics of an ERC721 Transfer log, whose token ID is indexed.
+const TopicsInErc721Transfer = 4
+
+var (
+	erc721TransferTopic        = OptimismArtifacts.ERC721ABI.Events["Transfer"].ID
+	erc1155TransferSingleTopic = OptimismArtifacts.ERC1155ABI.Events["TransferSingle"].ID
+	erc1155TransferBatchTopic  = OptimismArtifacts.ERC1155ABI.Events["TransferBatch"].ID
+)
+
+// NFTCurrency returns the currency of a single ERC721 or ERC1155 token.
+// Every token ID is a currency of its own, so that its balance can be tracked.
+func NFTCurrency(symbol string, contractAddress EthCommon.Address, tokenID *big.Int) *RosettaTypes.Currency {
+	return &RosettaTypes.Currency{
+		Symbol:   symbol,
+		Decimals: 0,
+		Metadata: map[string]interface{}{
+			ContractAddressKey: contractAddress.Hex(),
+			TokenIDKey:         tokenID.String(),
+		},
+	}
+}
+
+// supportsNFT returns whether NFT operations should be emitted for a contract.
+func (ec *Client) supportsNFT(contractAddress EthCommon.Address) bool {
+	if !ec.enableNFTOps {
+		return false
+	}
+	if len(ec.nftContracts) == 0 {
+		return true
+	}
+	_, ok := ec.nftContracts[strings.ToLower(contractAddress.Hex())]
+	return ok
+}
+
+// NFTOps constructs [ERC721TransferOpType] or [ERC1155TransferOpType] operations from an ERC721 Transfer
+// or ERC1155 TransferSingle/TransferBatch log. It returns nil for any other log.
+// Mints and burns only have an operation for the side that is not the zero address.
+func NFTOps(log *EthTypes.Log, startIndex int64) ([]*RosettaTypes.Operation, error) {
+	if len(log.Topics) != TopicsInErc721Transfer {
+		return nil, nil
+	}
+	from := EthCommon.BytesToAddress(log.Topics[1].Bytes())
+	to := EthCommon.BytesToAddress(log.Topics[2].Bytes())
+
+	switch log.Topics[0] {
+	case erc721TransferTopic:
+		tokenID := log.Topics[3].Big()
+		currency := NFTCurrency(UnknownERC721Symbol, log.Address, tokenID)
+		return nftTransferOps(ERC721TransferOpType, currency, from, to, big.NewInt(1), startIndex), nil
+	case erc1155TransferSingleTopic, erc1155TransferBatchTopic:
+		// The operator is indexed first, so from and to are shifted by one
+		from = EthCommon.BytesToAddress(log.Topics[2].Bytes())
+		to = EthCommon.BytesToAddress(log.Topics[3].Bytes())
+
+		var ids, values []*big.Int
+		if log.Topics[0] == erc1155TransferSingleTopic {
+			args, err := OptimismArtifacts.ERC1155ABI.Events["TransferSingle"].Inputs.NonIndexed().Unpack(log.Data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to decode TransferSingle log", err)
+			}
+			ids, values = []*big.Int{args[0].(*big.Int)}, []*big.Int{args[1].(*big.Int)}
+		} else {
+			args, err := OptimismArtifacts.ERC1155ABI.Events["TransferBatch"].Inputs.NonIndexed().Unpack(log.Data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to decode TransferBatch log", err)
+			}
+			ids, values = args[0].([]*big.Int), args[1].([]*big.Int)
+			if len(ids) != len(values) {
+				return nil, fmt.Errorf("TransferBatch log has %d ids but %d values", len(ids), len(values))
+			}
+		}
+
+		var ops []*RosettaTypes.Operation
+		for i := range ids {
+			currency := NFTCurrency(UnknownERC1155Symbol, log.Address, ids[i])
+			ops = append(ops, nftTransferOps(ERC1155TransferOpType, currency, from, to, values[i], startIndex+int64(len(ops)))...)
+		}
+		return ops, nil
+	default:
+		return nil, nil
+	}
+}
+
+func nftTransferOps(
+	opType string,
+	currency *RosettaTypes.Currency,
+	from EthCommon.Address,
+	to EthCommon.Address,
+	amount *big.Int,
+	startIndex int64,
+) []*RosettaTypes.Operation {
+	var ops []*RosettaTypes.Operation
+	var relatedOps []*RosettaTypes.OperationIdentifier
+	if from != (EthCommon.Address{}) {
+		ops = append(ops, GenerateOp(startIndex, nil, opType, SuccessStatus, from.Hex(), Amount(new(big.Int).Neg(amount), currency), nil))
+		relatedOps = []*RosettaTypes.OperationIdentifier{{Index: startIndex}}
+	}
+	if to != (EthCommon.Address{}) {
+		toIndex := startIndex + int64(len(ops))
+		ops = append(ops, GenerateOp(toIndex, relatedOps, opType, SuccessStatus, to.Hex(), Amount(amount, currency), nil))
+	}
+	return ops
+}
+
+// nftBalance returns the balance of an ERC721 or ERC1155 token currency: whether the account owns the token,
+// from ownerOf, or how many of the token it holds, from balanceOf(address,uint256).
+func (ec *Client) nftBalance(
+	ctx context.Context,
+	accountAddress string,
+	blockNum string,
+	currency *RosettaTypes.Currency,
+) (string, error) {
+	contractAddress := fmt.Sprintf("%s", currency.Metadata[ContractAddressKey])
+	tokenID, ok := new(big.Int).SetString(fmt.Sprintf("%v", currency.Metadata[TokenIDKey]), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid token id %v", currency.Metadata[TokenIDKey])
+	}
+	account := EthCommon.HexToAddress(accountAddress)
+
+	var data []byte
+	var err error
+	switch currency.Symbol {
+	case UnknownERC721Symbol:
+		data, err = OptimismArtifacts.ERC721ABI.Pack("ownerOf", tokenID)
+	case UnknownERC1155Symbol:
+		data, err = OptimismArtifacts.ERC1155ABI.Pack("balanceOf", account, tokenID)
+	default:
+		return "", fmt.Errorf("unsupported token id currency %s", currency.Symbol)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	callParams := map[string]string{
+		"to":   contractAddress,
+		"data": hexutil.Encode(data),
+	}
+	var resp string
+	if err := ec.c.CallContext(ctx, &resp, "eth_call", callParams, blockNum); err != nil {
+		// ownerOf reverts for tokens that do not exist (yet), which nobody owns
+		if currency.Symbol == UnknownERC721Symbol && strings.Contains(err.Error(), "execution reverted") {
+			return "0", nil
+		}
+		return "", stateError(err)
+	}
+	// "0x" is returned when retrieving balances of historical state that has been pruned by non-archival nodes
+	if resp == "0x" {
+		return "", ErrStateUnavailable
+	}
+
+	result, err := decodeHexData(resp)
+	if err != nil {
+		return "", err
+	}
+	if currency.Symbol == UnknownERC721Symbol {
+		if EthCommon.BigToAddress(result) == account {
+			return "1", nil
+		}
+		return "0", nil
+	}
+
+	return result.String(), nil
+}
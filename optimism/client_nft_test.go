@@ -0,0 +1,207 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+	"github.com/inphi/optimism-rosetta/optimism/utilities/artifacts"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	nftContract = EthCommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	nftOperator = EthCommon.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	nftAlice    = EthCommon.HexToAddress("0x095E7BAea6a6c7c4c2DfeB977eFac326aF552d87")
+	nftBob      = EthCommon.HexToAddress("0xb0b0000000000000000000000000000000000001")
+)
+
+// ClientNFTTestSuite tests NFT operations and balances.
+type ClientNFTTestSuite struct {
+	suite.Suite
+
+	mockJSONRPC *mocks.JSONRPC
+	client      *Client
+}
+
+// TestClientNFT runs the ClientNFTTestSuite.
+func TestClientNFT(t *testing.T) {
+	suite.Run(t, new(ClientNFTTestSuite))
+}
+
+// SetupTest sets up the test suite.
+func (testSuite *ClientNFTTestSuite) SetupTest() {
+	testSuite.mockJSONRPC = &mocks.JSONRPC{}
+	testSuite.client = &Client{
+		c:            testSuite.mockJSONRPC,
+		enableNFTOps: true,
+	}
+}
+
+func topic(address EthCommon.Address) EthCommon.Hash {
+	return EthCommon.BytesToHash(address.Bytes())
+}
+
+// TestERC721Transfer tests that ERC721 transfers, mints and burns are parsed.
+func (testSuite *ClientNFTTestSuite) TestERC721Transfer() {
+	tokenID := big.NewInt(42)
+	currency := NFTCurrency(UnknownERC721Symbol, nftContract, tokenID)
+	transferLog := func(from, to EthCommon.Address) *EthTypes.Log {
+		return &EthTypes.Log{
+			Address: nftContract,
+			Topics:  []EthCommon.Hash{erc721TransferTopic, topic(from), topic(to), EthCommon.BigToHash(tokenID)},
+		}
+	}
+
+	ops, err := NFTOps(transferLog(nftAlice, nftBob), 3)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Operation{
+		GenerateOp(3, nil, ERC721TransferOpType, SuccessStatus, nftAlice.Hex(), Amount(big.NewInt(-1), currency), nil),
+		GenerateOp(4, []*RosettaTypes.OperationIdentifier{{Index: 3}}, ERC721TransferOpType, SuccessStatus, nftBob.Hex(), Amount(big.NewInt(1), currency), nil),
+	}, ops)
+	testSuite.Equal(map[string]interface{}{
+		ContractAddressKey: nftContract.Hex(),
+		TokenIDKey:         "42",
+	}, ops[0].Amount.Currency.Metadata)
+
+	ops, err = NFTOps(transferLog(EthCommon.Address{}, nftBob), 0)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Operation{
+		GenerateOp(0, nil, ERC721TransferOpType, SuccessStatus, nftBob.Hex(), Amount(big.NewInt(1), currency), nil),
+	}, ops)
+
+	ops, err = NFTOps(transferLog(nftAlice, EthCommon.Address{}), 0)
+	testSuite.NoError(err)
+	testSuite.Equal([]*RosettaTypes.Operation{
+		GenerateOp(0, nil, ERC721TransferOpType, SuccessStatus, nftAlice.Hex(), Amount(big.NewInt(-1), currency), nil),
+	}, ops)
+
+	// ERC20 transfers have one less topic
+	erc20Log := transferLog(nftAlice, nftBob)
+	erc20Log.Topics = erc20Log.Topics[:3]
+	ops, err = NFTOps(erc20Log, 0)
+	testSuite.NoError(err)
+	testSuite.Nil(ops)
+}
+
+// TestERC1155Transfer tests that ERC1155 single and batch transfers are parsed.
+func (testSuite *ClientNFTTestSuite) TestERC1155Transfer() {
+	topics := func(eventTopic EthCommon.Hash) []EthCommon.Hash {
+		return []EthCommon.Hash{eventTopic, topic(nftOperator), topic(nftAlice), topic(nftBob)}
+	}
+
+	singleData, err := artifacts.ERC1155ABI.Events["TransferSingle"].Inputs.NonIndexed().Pack(big.NewInt(7), big.NewInt(5))
+	testSuite.NoError(err)
+	ops, err := NFTOps(&EthTypes.Log{Address: nftContract, Topics: topics(erc1155TransferSingleTopic), Data: singleData}, 1)
+	testSuite.NoError(err)
+	currency := NFTCurrency(UnknownERC1155Symbol, nftContract, big.NewInt(7))
+	testSuite.Equal([]*RosettaTypes.Operation{
+		GenerateOp(1, nil, ERC1155TransferOpType, SuccessStatus, nftAlice.Hex(), Amount(big.NewInt(-5), currency), nil),
+		GenerateOp(2, []*RosettaTypes.OperationIdentifier{{Index: 1}}, ERC1155TransferOpType, SuccessStatus, nftBob.Hex(), Amount(big.NewInt(5), currency), nil),
+	}, ops)
+
+	batchData, err := artifacts.ERC1155ABI.Events["TransferBatch"].Inputs.NonIndexed().Pack(
+		[]*big.Int{big.NewInt(7), big.NewInt(8)},
+		[]*big.Int{big.NewInt(5), big.NewInt(1)},
+	)
+	testSuite.NoError(err)
+	ops, err = NFTOps(&EthTypes.Log{Address: nftContract, Topics: topics(erc1155TransferBatchTopic), Data: batchData}, 0)
+	testSuite.NoError(err)
+	testSuite.Len(ops, 4)
+	testSuite.Equal("-5", ops[0].Amount.Value)
+	testSuite.Equal("7", ops[1].Amount.Currency.Metadata[TokenIDKey])
+	testSuite.Equal(int64(2), ops[2].OperationIdentifier.Index)
+	testSuite.Equal("1", ops[3].Amount.Value)
+	testSuite.Equal("8", ops[3].Amount.Currency.Metadata[TokenIDKey])
+	testSuite.Equal([]*RosettaTypes.OperationIdentifier{{Index: 2}}, ops[3].RelatedOperations)
+
+	_, err = NFTOps(&EthTypes.Log{Address: nftContract, Topics: topics(erc1155TransferBatchTopic), Data: []byte{0x01}}, 0)
+	testSuite.Error(err)
+}
+
+// TestSupportsNFT tests that NFT operations are gated and filtered by contract.
+func (testSuite *ClientNFTTestSuite) TestSupportsNFT() {
+	testSuite.True(testSuite.client.supportsNFT(nftContract))
+
+	testSuite.client.nftContracts = map[string]bool{"0x5fbdb2315678afecb367f032d93f642f64180aa3": true}
+	testSuite.True(testSuite.client.supportsNFT(nftContract))
+	testSuite.False(testSuite.client.supportsNFT(nftOperator))
+
+	testSuite.client.enableNFTOps = false
+	testSuite.False(testSuite.client.supportsNFT(nftContract))
+}
+
+func (testSuite *ClientNFTTestSuite) mockCall(data []byte, result string, err error) {
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"eth_call",
+		map[string]string{
+			"to":   nftContract.Hex(),
+			"data": hexutil.Encode(data),
+		},
+		"0x10",
+	).Return(err).Run(func(args mock.Arguments) {
+		*args.Get(1).(*string) = result
+	}).Once()
+}
+
+// TestNFTBalance tests that NFT balances are looked up with ownerOf and balanceOf(address,uint256).
+func (testSuite *ClientNFTTestSuite) TestNFTBalance() {
+	ctx := context.Background()
+	tokenID := big.NewInt(42)
+	ownerOf, err := artifacts.ERC721ABI.Pack("ownerOf", tokenID)
+	testSuite.NoError(err)
+
+	erc721 := NFTCurrency(UnknownERC721Symbol, nftContract, tokenID)
+	testSuite.mockCall(ownerOf, EthCommon.BytesToHash(nftAlice.Bytes()).Hex(), nil)
+	balance, err := testSuite.client.nftBalance(ctx, nftAlice.Hex(), "0x10", erc721)
+	testSuite.NoError(err)
+	testSuite.Equal("1", balance)
+
+	testSuite.mockCall(ownerOf, EthCommon.BytesToHash(nftBob.Bytes()).Hex(), nil)
+	balance, err = testSuite.client.nftBalance(ctx, nftAlice.Hex(), "0x10", erc721)
+	testSuite.NoError(err)
+	testSuite.Equal("0", balance)
+
+	testSuite.mockCall(ownerOf, "", errors.New("execution reverted: ERC721: invalid token ID"))
+	balance, err = testSuite.client.nftBalance(ctx, nftAlice.Hex(), "0x10", erc721)
+	testSuite.NoError(err)
+	testSuite.Equal("0", balance)
+
+	balanceOf, err := artifacts.ERC1155ABI.Pack("balanceOf", nftAlice, tokenID)
+	testSuite.NoError(err)
+	testSuite.mockCall(balanceOf, EthCommon.BigToHash(big.NewInt(5)).Hex(), nil)
+	balance, err = testSuite.client.nftBalance(ctx, nftAlice.Hex(), "0x10", NFTCurrency(UnknownERC1155Symbol, nftContract, tokenID))
+	testSuite.NoError(err)
+	testSuite.Equal("5", balance)
+
+	testSuite.mockCall(balanceOf, "", errors.New("execution reverted"))
+	_, err = testSuite.client.nftBalance(ctx, nftAlice.Hex(), "0x10", NFTCurrency(UnknownERC1155Symbol, nftContract, tokenID))
+	testSuite.Error(err)
+
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+}
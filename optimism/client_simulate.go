@@ -0,0 +1,151 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	L2Eth "github.com/ethereum-optimism/optimism/l2geth/eth"
+	"github.com/ethereum/go-ethereum"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// SimulationResult is the outcome of executing a transaction on top of the latest block
+// without including it. Operations are predicted from the call trace and the ERC20
+// transfers of the transaction, and do not include its fee.
+type SimulationResult struct {
+	Operations   []*RosettaTypes.Operation `json:"operations"`
+	GasUsed      uint64                    `json:"gas_used"`
+	Reverted     bool                      `json:"reverted"`
+	RevertReason string                    `json:"revert_reason,omitempty"`
+}
+
+// simulationTraceConfig is the trace config of [Client.TraceTransactions], along with
+// the config of the callTracer asking for the logs emitted by each call frame
+type simulationTraceConfig struct {
+	*L2Eth.TraceConfig
+	TracerConfig map[string]interface{} `json:"tracerConfig,omitempty"`
+}
+
+// simulatedCall is a call frame of debug_traceCall that only retains the fields
+// not already decoded by [Call]
+type simulatedCall struct {
+	Error        string           `json:"error"`
+	RevertReason string           `json:"revertReason"`
+	Logs         []*simulatedLog  `json:"logs"`
+	Calls        []*simulatedCall `json:"calls"`
+}
+
+type simulatedLog struct {
+	Address EthCommon.Address `json:"address"`
+	Topics  []EthCommon.Hash  `json:"topics"`
+	Data    hexutil.Bytes     `json:"data"`
+}
+
+// logs returns the logs emitted by the call frame and its children. Logs of
+// reverted frames are discarded, as they are by the EVM.
+func (c *simulatedCall) logs() []*EthTypes.Log {
+	if c == nil || c.Error != "" {
+		return nil
+	}
+	var logs []*EthTypes.Log
+	for _, l := range c.Logs {
+		logs = append(logs, &EthTypes.Log{Address: l.Address, Topics: l.Topics, Data: l.Data})
+	}
+	for _, child := range c.Calls {
+		logs = append(logs, child.logs()...)
+	}
+	return logs
+}
+
+// SimulateTransaction executes msg on top of the latest block with debug_traceCall and
+// predicts its operations. Nodes without the debug namespace fail the simulation.
+func (ec *Client) SimulateTransaction(ctx context.Context, msg ethereum.CallMsg) (*SimulationResult, error) {
+	if err := ec.acquireTraceSemaphore(ctx); err != nil {
+		return nil, err
+	}
+	defer ec.traceSemaphore.Release(semaphoreTraceWeight)
+
+	// The trace and the currencies of its ERC20 transfers are resolved at the same block
+	var blockNum hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &blockNum, "eth_blockNumber"); err != nil {
+		return nil, err
+	}
+
+	arg := toCallArg(msg).(map[string]interface{})
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	// Only the callTracer reports the logs of each call frame, so it is used even when
+	// blocks are traced with the custom bedrock tracer
+	config := &simulationTraceConfig{
+		TraceConfig:  ec.callTraceConfig(),
+		TracerConfig: map[string]interface{}{"withLog": true},
+	}
+
+	var raw json.RawMessage
+	if err := ec.c.CallContext(ctx, &raw, "debug_traceCall", arg, blockNum, config); err != nil {
+		return nil, fmt.Errorf("%w: unable to trace call", err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("got empty trace for simulated call")
+	}
+
+	var trace Call
+	if err := json.Unmarshal(raw, &trace); err != nil {
+		return nil, err
+	}
+	var simulated simulatedCall
+	if err := json.Unmarshal(raw, &simulated); err != nil {
+		return nil, err
+	}
+
+	ops := TraceOps(FlattenTraces(&trace, []*FlatCall{}), 0)
+	for _, log := range simulated.logs() {
+		if len(log.Topics) != TopicsInErc20Transfer || log.Topics[0] != erc20TransferTopic {
+			continue
+		}
+		if !ec.supportsToken(log.Address.Hex()) {
+			continue
+		}
+		currency := ec.erc20Currency(ctx, uint64(blockNum), log.Address)
+		ops = append(ops, Erc20Ops(log, currency, int64(len(ops)))...)
+	}
+
+	result := &SimulationResult{
+		Operations: ops,
+		GasUsed:    trace.GasUsed.Uint64(),
+		Reverted:   trace.Revert,
+	}
+	if trace.Revert {
+		result.RevertReason = simulated.RevertReason
+		if result.RevertReason == "" {
+			result.RevertReason = simulated.Error
+		}
+	}
+
+	return result, nil
+}
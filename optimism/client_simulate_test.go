@@ -0,0 +1,223 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	L2Eth "github.com/ethereum-optimism/optimism/l2geth/eth"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	simulateFrom  = common.HexToAddress("0xE550f300E477C60CE7e7172d12e5a27e9379D2e3")
+	simulateTo    = common.HexToAddress("0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984")
+	simulateToken = common.HexToAddress("0xaD6D458402F60fD3Bd25163575031ACDce07538D")
+)
+
+// ClientSimulateSuite tests [Client.SimulateTransaction].
+type ClientSimulateSuite struct {
+	suite.Suite
+
+	mockJSONRPC         *mocks.JSONRPC
+	mockCurrencyFetcher *mocks.CurrencyFetcher
+	client              *Client
+}
+
+// SetupTest sets up the test suite.
+func (testSuite *ClientSimulateSuite) SetupTest() {
+	testSuite.mockJSONRPC = &mocks.JSONRPC{}
+	testSuite.mockCurrencyFetcher = &mocks.CurrencyFetcher{}
+	testSuite.client = &Client{
+		c:               testSuite.mockJSONRPC,
+		currencyFetcher: testSuite.mockCurrencyFetcher,
+		tc:              &L2Eth.TraceConfig{},
+		traceSemaphore:  semaphore.NewWeighted(100),
+	}
+}
+
+func (testSuite *ClientSimulateSuite) TearDownTest() {
+	testSuite.mockJSONRPC.AssertExpectations(testSuite.T())
+	testSuite.mockCurrencyFetcher.AssertExpectations(testSuite.T())
+}
+
+// TestClientSimulate runs the ClientSimulateSuite.
+func TestClientSimulate(t *testing.T) {
+	suite.Run(t, new(ClientSimulateSuite))
+}
+
+func (testSuite *ClientSimulateSuite) mockTraceCall(ctx context.Context, trace string, err error) {
+	testSuite.mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_blockNumber").Return(nil).Run(
+		func(args mock.Arguments) {
+			*args.Get(1).(*hexutil.Uint64) = 16
+		},
+	).Once()
+	testSuite.mockJSONRPC.On(
+		"CallContext",
+		ctx,
+		mock.Anything,
+		"debug_traceCall",
+		mock.Anything,
+		hexutil.Uint64(16),
+		mock.MatchedBy(func(config *simulationTraceConfig) bool {
+			return *config.Tracer == "callTracer" && config.TracerConfig["withLog"] == true
+		}),
+	).Return(err).Run(
+		func(args mock.Arguments) {
+			if err == nil {
+				*args.Get(1).(*json.RawMessage) = json.RawMessage(trace)
+			}
+		},
+	).Once()
+}
+
+// TestSimulateTransaction tests that value transfers and ERC20 transfers of nested calls are predicted.
+func (testSuite *ClientSimulateSuite) TestSimulateTransaction() {
+	ctx := context.Background()
+	testSuite.mockTraceCall(ctx, `{
+		"type": "CALL",
+		"from": "`+simulateFrom.Hex()+`",
+		"to": "`+simulateTo.Hex()+`",
+		"value": "0x64",
+		"gasUsed": "0xc350",
+		"input": "0x",
+		"calls": [{
+			"type": "CALL",
+			"from": "`+simulateTo.Hex()+`",
+			"to": "`+simulateToken.Hex()+`",
+			"value": "0x0",
+			"gasUsed": "0x7530",
+			"input": "0xa9059cbb",
+			"logs": [{
+				"address": "`+simulateToken.Hex()+`",
+				"topics": [
+					"`+erc20TransferTopic.Hex()+`",
+					"`+common.BytesToHash(simulateTo.Bytes()).Hex()+`",
+					"`+common.BytesToHash(simulateFrom.Bytes()).Hex()+`"
+				],
+				"data": "0x00000000000000000000000000000000000000000000000000000000000003e8"
+			}]
+		}]
+	}`, nil)
+	currency := &RosettaTypes.Currency{
+		Symbol:   "UNI",
+		Decimals: 18,
+		Metadata: map[string]interface{}{ContractAddressKey: simulateToken.Hex()},
+	}
+	testSuite.mockCurrencyFetcher.On("FetchCurrency", ctx, uint64(16), simulateToken.Hex()).Return(currency, nil).Once()
+
+	result, err := testSuite.client.SimulateTransaction(ctx, ethereum.CallMsg{From: simulateFrom, To: &simulateTo})
+	testSuite.NoError(err)
+	testSuite.False(result.Reverted)
+	testSuite.Equal(uint64(50000), result.GasUsed)
+	testSuite.Len(result.Operations, 4)
+	testSuite.Equal(CallOpType, result.Operations[0].Type)
+	testSuite.Equal("-100", result.Operations[0].Amount.Value)
+	testSuite.Equal(CallOpType, result.Operations[1].Type)
+	testSuite.Equal(ERC20TransferOpType, result.Operations[2].Type)
+	testSuite.Equal(simulateTo.Hex(), result.Operations[2].Account.Address)
+	testSuite.Equal("-1000", result.Operations[2].Amount.Value)
+	testSuite.Equal(currency, result.Operations[2].Amount.Currency)
+	testSuite.Equal(simulateFrom.Hex(), result.Operations[3].Account.Address)
+	testSuite.Equal("1000", result.Operations[3].Amount.Value)
+}
+
+// TestSimulateTransaction_Reverted tests that the revert reason is reported and the logs of reverted calls are dropped.
+func (testSuite *ClientSimulateSuite) TestSimulateTransaction_Reverted() {
+	ctx := context.Background()
+	testSuite.mockTraceCall(ctx, `{
+		"type": "CALL",
+		"from": "`+simulateFrom.Hex()+`",
+		"to": "`+simulateToken.Hex()+`",
+		"value": "0x0",
+		"gasUsed": "0x5dc0",
+		"input": "0xa9059cbb",
+		"error": "execution reverted",
+		"revertReason": "ERC20: transfer amount exceeds balance",
+		"logs": [{
+			"address": "`+simulateToken.Hex()+`",
+			"topics": [
+				"`+erc20TransferTopic.Hex()+`",
+				"`+common.BytesToHash(simulateFrom.Bytes()).Hex()+`",
+				"`+common.BytesToHash(simulateTo.Bytes()).Hex()+`"
+			],
+			"data": "0x00000000000000000000000000000000000000000000000000000000000003e8"
+		}]
+	}`, nil)
+
+	result, err := testSuite.client.SimulateTransaction(ctx, ethereum.CallMsg{From: simulateFrom, To: &simulateToken})
+	testSuite.NoError(err)
+	testSuite.True(result.Reverted)
+	testSuite.Equal("ERC20: transfer amount exceeds balance", result.RevertReason)
+	testSuite.Equal(uint64(24000), result.GasUsed)
+	testSuite.Empty(result.Operations)
+}
+
+// TestSimulateTransaction_CustomTracer tests that ERC20 transfers are predicted when blocks
+// are traced with the custom bedrock tracer, which does not report logs.
+func (testSuite *ClientSimulateSuite) TestSimulateTransaction_CustomTracer() {
+	ctx := context.Background()
+	customTracer := "{result: function() { return {}; }}"
+	testSuite.client.customBedrockTracer = true
+	testSuite.client.tc = &L2Eth.TraceConfig{Tracer: &customTracer}
+	testSuite.mockTraceCall(ctx, `{
+		"type": "CALL",
+		"from": "`+simulateFrom.Hex()+`",
+		"to": "`+simulateToken.Hex()+`",
+		"value": "0x0",
+		"gasUsed": "0x7530",
+		"input": "0xa9059cbb",
+		"logs": [{
+			"address": "`+simulateToken.Hex()+`",
+			"topics": [
+				"`+erc20TransferTopic.Hex()+`",
+				"`+common.BytesToHash(simulateFrom.Bytes()).Hex()+`",
+				"`+common.BytesToHash(simulateTo.Bytes()).Hex()+`"
+			],
+			"data": "0x00000000000000000000000000000000000000000000000000000000000003e8"
+		}]
+	}`, nil)
+	currency := &RosettaTypes.Currency{Symbol: "UNI", Decimals: 18}
+	testSuite.mockCurrencyFetcher.On("FetchCurrency", ctx, uint64(16), simulateToken.Hex()).Return(currency, nil).Once()
+
+	result, err := testSuite.client.SimulateTransaction(ctx, ethereum.CallMsg{From: simulateFrom, To: &simulateToken})
+	testSuite.NoError(err)
+	testSuite.Len(result.Operations, 2)
+	testSuite.Equal(ERC20TransferOpType, result.Operations[0].Type)
+	testSuite.Equal("-1000", result.Operations[0].Amount.Value)
+	testSuite.Equal(ERC20TransferOpType, result.Operations[1].Type)
+	testSuite.Equal("1000", result.Operations[1].Amount.Value)
+}
+
+// TestSimulateTransaction_Unavailable tests that simulations fail when the node lacks the debug namespace.
+func (testSuite *ClientSimulateSuite) TestSimulateTransaction_Unavailable() {
+	ctx := context.Background()
+	testSuite.mockTraceCall(ctx, "", errors.New("the method debug_traceCall does not exist/is not available"))
+
+	result, err := testSuite.client.SimulateTransaction(ctx, ethereum.CallMsg{From: simulateFrom, To: &simulateTo})
+	testSuite.Nil(result)
+	testSuite.ErrorContains(err, "does not exist/is not available")
+}
@@ -82,9 +82,14 @@ func (ec *Client) Status(ctx context.Context) (
 // safeBlockHeader returns the current height from safe chain
 // the /network/status should always interact with safe chain to ensure the safety of sync
 func (ec *Client) safeBlockHeader(ctx context.Context) (*rpcHeader, error) {
+	return ec.blockHeaderByTag(ctx, "safe")
+}
+
+// blockHeaderByTag returns the header of the block labelled by tag, such as "safe" or "finalized".
+func (ec *Client) blockHeaderByTag(ctx context.Context, tag string) (*rpcHeader, error) {
 	var head *rpcHeader
 
-	err := ec.c.CallContext(ctx, &head, "eth_getBlockByNumber", "safe", false)
+	err := ec.c.CallContext(ctx, &head, "eth_getBlockByNumber", tag, false)
 	if err == nil && head == nil {
 		return nil, ethereum.NotFound
 	}
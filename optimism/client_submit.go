@@ -0,0 +1,193 @@
+// Copyright 2023 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"sync"
+
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// FirstSuccessFanout submits a transaction to each submission endpoint in order
+	// until one of them accepts it.
+	FirstSuccessFanout = "FIRST_SUCCESS"
+
+	// AllFanout submits a transaction to every submission endpoint concurrently.
+	// The submission succeeds if any endpoint accepts it.
+	AllFanout = "ALL"
+
+	// defaultSubmitEndpoint names the node endpoint when no submission endpoints are configured.
+	defaultSubmitEndpoint = "default"
+)
+
+// submitEndpoint is an RPC endpoint that transactions are sent to.
+type submitEndpoint struct {
+	name string
+	c    JSONRPC
+}
+
+// SubmitResult is the outcome of sending a transaction to a single endpoint.
+type SubmitResult struct {
+	Endpoint string `json:"endpoint"`
+	Error    string `json:"error,omitempty"`
+}
+
+// KnownAccount is the expected storage of an account in a [TransactionConditional].
+// Either the storage root or a set of storage slots is checked.
+type KnownAccount struct {
+	StorageRoot  *EthCommon.Hash
+	StorageSlots map[EthCommon.Hash]EthCommon.Hash
+}
+
+// MarshalJSON encodes the account as its storage root or as its storage slots.
+func (a KnownAccount) MarshalJSON() ([]byte, error) {
+	if a.StorageRoot != nil {
+		return json.Marshal(a.StorageRoot)
+	}
+	return json.Marshal(a.StorageSlots)
+}
+
+// UnmarshalJSON decodes either a storage root or a map of storage slots.
+func (a *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root EthCommon.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		a.StorageRoot = &root
+		return nil
+	}
+
+	var slots map[EthCommon.Hash]EthCommon.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or a map of storage slots")
+	}
+	a.StorageSlots = slots
+	return nil
+}
+
+// TransactionConditional restricts the inclusion of a transaction sent with
+// eth_sendRawTransactionConditional to the given account states and block range.
+type TransactionConditional struct {
+	KnownAccounts  map[EthCommon.Address]KnownAccount `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Big                       `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big                       `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                    `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                    `json:"timestampMax,omitempty"`
+}
+
+// redactURL strips the credentials, path and query of an endpoint URL,
+// which commonly hold API keys, so that it can be logged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Host) == 0 {
+		return "invalid endpoint"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// SendTransaction injects a signed transaction into the pending pool for execution.
+//
+// If the transaction was a contract creation use the TransactionReceipt method to get the
+// contract address after the transaction has been mined.
+func (ec *Client) SendTransaction(ctx context.Context, tx *EthTypes.Transaction) error {
+	_, err := ec.SubmitTransaction(ctx, tx, nil)
+	return err
+}
+
+// SubmitTransaction sends a signed transaction to the submission endpoints according to the
+// fan-out mode, or to the node when none are configured. The transaction is sent with
+// eth_sendRawTransactionConditional when a conditional is given.
+// The result of each endpoint that was tried is returned, even if the submission failed.
+func (ec *Client) SubmitTransaction(
+	ctx context.Context,
+	tx *EthTypes.Transaction,
+	conditional *TransactionConditional,
+) ([]*SubmitResult, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	method := "eth_sendRawTransaction"
+	args := []interface{}{hexutil.Encode(data)}
+	if conditional != nil {
+		method = "eth_sendRawTransactionConditional"
+		args = append(args, conditional)
+	}
+
+	endpoints := ec.submitters
+	if len(endpoints) == 0 {
+		endpoints = []*submitEndpoint{{name: defaultSubmitEndpoint, c: ec.c}}
+	}
+
+	send := func(endpoint *submitEndpoint) error {
+		err := endpoint.c.CallContext(ctx, nil, method, args...)
+		if err != nil {
+			log.Printf("%s of %s to %s failed: %v", method, tx.Hash().Hex(), endpoint.name, err)
+		} else {
+			log.Printf("%s of %s to %s succeeded", method, tx.Hash().Hex(), endpoint.name)
+		}
+		return err
+	}
+
+	results := make([]*SubmitResult, 0, len(endpoints))
+	errs := make([]error, len(endpoints))
+	if ec.submitFanout == AllFanout {
+		var wg sync.WaitGroup
+		for i, endpoint := range endpoints {
+			wg.Add(1)
+			go func(i int, endpoint *submitEndpoint) {
+				defer wg.Done()
+				errs[i] = send(endpoint)
+			}(i, endpoint)
+		}
+		wg.Wait()
+	} else {
+		for i, endpoint := range endpoints {
+			errs[i] = send(endpoint)
+			if errs[i] == nil {
+				// Endpoints after the first success are not tried
+				endpoints = endpoints[:i+1]
+				break
+			}
+		}
+	}
+
+	var firstErr error
+	succeeded := false
+	for i, endpoint := range endpoints {
+		result := &SubmitResult{Endpoint: endpoint.name}
+		if errs[i] != nil {
+			result.Error = errs[i].Error()
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+		} else {
+			succeeded = true
+		}
+		results = append(results, result)
+	}
+	if !succeeded {
+		return results, firstErr
+	}
+
+	return results, nil
+}
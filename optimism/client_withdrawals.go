@@ -0,0 +1,89 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Event parsers of the bridge predeploys.
+// Their ABIs are compiled into op-bindings, so constructing them can only fail if the bindings are broken.
+var (
+	messagePasserEvents  = mustFilterer(bindings.NewL2ToL1MessagePasserFilterer(predeploys.L2ToL1MessagePasserAddr, nil))
+	standardBridgeEvents = mustFilterer(bindings.NewL2StandardBridgeFilterer(predeploys.L2StandardBridgeAddr, nil))
+)
+
+func mustFilterer[T any](filterer T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return filterer
+}
+
+// WithdrawalOps constructs a [WithdrawalInitiatedOpType] operation for every MessagePassed event
+// emitted by the L2ToL1MessagePasser in the given receipt logs.
+//
+// Withdrawals through the L2StandardBridge are passed on by the L2CrossDomainMessenger, so the sender of
+// their MessagePassed event is the messenger. The WithdrawalInitiated event emitted by the bridge just before
+// is used to attribute such withdrawals to the account that initiated them.
+func WithdrawalOps(logs []*EthTypes.Log, startIndex int64) []*RosettaTypes.Operation {
+	var ops []*RosettaTypes.Operation
+	var bridgeWithdrawal *bindings.L2StandardBridgeWithdrawalInitiated
+	for _, log := range logs {
+		switch log.Address {
+		case predeploys.L2StandardBridgeAddr:
+			if withdrawal, err := standardBridgeEvents.ParseWithdrawalInitiated(*log); err == nil {
+				bridgeWithdrawal = withdrawal
+			}
+		case predeploys.L2ToL1MessagePasserAddr:
+			passed, err := messagePasserEvents.ParseMessagePassed(*log)
+			if err != nil {
+				continue
+			}
+
+			account := passed.Sender
+			metadata := map[string]interface{}{
+				"withdrawal_hash": EthCommon.Hash(passed.WithdrawalHash).Hex(),
+				"nonce":           hexutil.EncodeBig(passed.Nonce),
+				"sender":          passed.Sender.Hex(),
+				"target":          passed.Target.Hex(),
+				"value":           hexutil.EncodeBig(passed.Value),
+				"gas_limit":       hexutil.EncodeBig(passed.GasLimit),
+				"data":            hexutil.Encode(passed.Data),
+			}
+			if bridgeWithdrawal != nil {
+				account = bridgeWithdrawal.From
+				metadata["bridge"] = map[string]interface{}{
+					"l1_token": bridgeWithdrawal.L1Token.Hex(),
+					"l2_token": bridgeWithdrawal.L2Token.Hex(),
+					"from":     bridgeWithdrawal.From.Hex(),
+					"to":       bridgeWithdrawal.To.Hex(),
+					"amount":   hexutil.EncodeBig(bridgeWithdrawal.Amount),
+				}
+				bridgeWithdrawal = nil
+			}
+
+			opIndex := startIndex + int64(len(ops))
+			ops = append(ops, GenerateOp(opIndex, nil, WithdrawalInitiatedOpType, SuccessStatus, account.Hex(), nil, metadata))
+		}
+	}
+
+	return ops
+}
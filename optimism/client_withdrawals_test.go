@@ -0,0 +1,134 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"math/big"
+	"testing"
+
+	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/op-bindings/bindings"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	EthTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// packEventLog ABI encodes an event log of the given contract.
+func packEventLog(
+	t *testing.T,
+	metadata *bind.MetaData,
+	address EthCommon.Address,
+	name string,
+	topics []EthCommon.Hash,
+	args ...interface{},
+) *EthTypes.Log {
+	contractABI, err := metadata.GetAbi()
+	assert.NoError(t, err)
+	event := contractABI.Events[name]
+	data, err := event.Inputs.NonIndexed().Pack(args...)
+	assert.NoError(t, err)
+
+	return &EthTypes.Log{
+		Address: address,
+		Topics:  append([]EthCommon.Hash{event.ID}, topics...),
+		Data:    data,
+	}
+}
+
+func messagePassedLog(t *testing.T, nonce int64, sender, target EthCommon.Address, hash EthCommon.Hash) *EthTypes.Log {
+	return packEventLog(
+		t,
+		bindings.L2ToL1MessagePasserMetaData,
+		predeploys.L2ToL1MessagePasserAddr,
+		"MessagePassed",
+		[]EthCommon.Hash{
+			EthCommon.BigToHash(big.NewInt(nonce)),
+			EthCommon.BytesToHash(sender.Bytes()),
+			EthCommon.BytesToHash(target.Bytes()),
+		},
+		big.NewInt(1000),
+		big.NewInt(200000),
+		[]byte{0xca, 0xfe},
+		hash,
+	)
+}
+
+func TestWithdrawalOps(t *testing.T) {
+	user := EthCommon.HexToAddress("0x095E7BAea6a6c7c4c2DfeB977eFac326aF552d87")
+	target := EthCommon.HexToAddress("0xb0b0000000000000000000000000000000000001")
+	l1Messenger := EthCommon.HexToAddress("0x25ace71c97B33Cc4729CF772ae268934F7ab5fA1")
+	l1Token := EthCommon.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+	l2Token := EthCommon.HexToAddress("0xDA10009cBd5D07dd0CeCc66161FC93D7c9000da1")
+	hash1 := EthCommon.HexToHash("0x01")
+	hash2 := EthCommon.HexToHash("0x02")
+
+	bridgeLog := packEventLog(
+		t,
+		bindings.L2StandardBridgeMetaData,
+		predeploys.L2StandardBridgeAddr,
+		"WithdrawalInitiated",
+		[]EthCommon.Hash{
+			EthCommon.BytesToHash(l1Token.Bytes()),
+			EthCommon.BytesToHash(l2Token.Bytes()),
+			EthCommon.BytesToHash(user.Bytes()),
+		},
+		target,
+		big.NewInt(5000),
+		[]byte{},
+	)
+	// Events of the same shape emitted by other contracts must be ignored
+	spoofedLog := messagePassedLog(t, 9, user, target, hash1)
+	spoofedLog.Address = target
+
+	logs := []*EthTypes.Log{
+		messagePassedLog(t, 1, user, target, hash1),
+		spoofedLog,
+		bridgeLog,
+		messagePassedLog(t, 2, predeploys.L2CrossDomainMessengerAddr, l1Messenger, hash2),
+	}
+
+	ops := WithdrawalOps(logs, 3)
+	assert.Equal(t, []*RosettaTypes.Operation{
+		GenerateOp(3, nil, WithdrawalInitiatedOpType, SuccessStatus, user.Hex(), nil, map[string]interface{}{
+			"withdrawal_hash": hash1.Hex(),
+			"nonce":           "0x1",
+			"sender":          user.Hex(),
+			"target":          target.Hex(),
+			"value":           "0x3e8",
+			"gas_limit":       "0x30d40",
+			"data":            "0xcafe",
+		}),
+		GenerateOp(4, nil, WithdrawalInitiatedOpType, SuccessStatus, user.Hex(), nil, map[string]interface{}{
+			"withdrawal_hash": hash2.Hex(),
+			"nonce":           "0x2",
+			"sender":          predeploys.L2CrossDomainMessengerAddr.Hex(),
+			"target":          l1Messenger.Hex(),
+			"value":           "0x3e8",
+			"gas_limit":       "0x30d40",
+			"data":            "0xcafe",
+			"bridge": map[string]interface{}{
+				"l1_token": l1Token.Hex(),
+				"l2_token": l2Token.Hex(),
+				"from":     user.Hex(),
+				"to":       target.Hex(),
+				"amount":   "0x1388",
+			},
+		}),
+	}, ops)
+
+	assert.Nil(t, WithdrawalOps(nil, 0))
+}
@@ -27,6 +27,7 @@ import (
 
 	RosettaTypes "github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inphi/optimism-rosetta/metrics"
 	"github.com/inphi/optimism-rosetta/optimism/utilities/artifacts"
 )
 
@@ -92,7 +93,9 @@ func (ecf ERC20CurrencyFetcher) FetchCurrency(
 	blockNum uint64,
 	contractAddress string,
 ) (*RosettaTypes.Currency, error) {
-	if cachedCurrency, ok := ecf.currencyCache.Get(contractAddress); ok {
+	cachedCurrency, ok := ecf.currencyCache.Get(contractAddress)
+	metrics.ObserveCache(metrics.CurrencyCache, ok)
+	if ok {
 		return cachedCurrency.(*RosettaTypes.Currency), nil
 	}
 
@@ -184,3 +187,12 @@ func newERC20CurrencyFetcher(c JSONRPC) (CurrencyFetcher, error) {
 
 	return &ERC20CurrencyFetcher{cache, c}, nil
 }
+
+// TokenCurrency returns the currency of the ERC20 token at contractAddress as of the latest block.
+func (ec *Client) TokenCurrency(ctx context.Context, contractAddress string) (*RosettaTypes.Currency, error) {
+	var blockNum hexutil.Uint64
+	if err := ec.c.CallContext(ctx, &blockNum, EthBlockNumber); err != nil {
+		return nil, err
+	}
+	return ec.currencyFetcher.FetchCurrency(ctx, uint64(blockNum), contractAddress)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
 	"github.com/ethereum-optimism/optimism/l2geth/rpc"
+	EthHexutil "github.com/ethereum/go-ethereum/common/hexutil"
 	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
 	"github.com/inphi/optimism-rosetta/optimism/utilities/artifacts"
 	"github.com/stretchr/testify/assert"
@@ -182,3 +183,27 @@ func TestFetchCurrency(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenCurrency(t *testing.T) {
+	ctx := context.Background()
+	mockJSONRPC := &mocks.JSONRPC{}
+	mockCurrencyFetcher := &mocks.CurrencyFetcher{}
+	c := &Client{c: mockJSONRPC, currencyFetcher: mockCurrencyFetcher}
+	currency := &RosettaTypes.Currency{
+		Symbol:   "OP",
+		Decimals: OPDecimals,
+		Metadata: map[string]interface{}{ContractAddressKey: OPContractAddress},
+	}
+
+	mockJSONRPC.On("CallContext", ctx, mock.Anything, "eth_blockNumber").Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*EthHexutil.Uint64)) = 42
+	}).Once()
+	mockCurrencyFetcher.On("FetchCurrency", ctx, uint64(42), OPContractAddress).Return(currency, nil).Once()
+
+	result, err := c.TokenCurrency(ctx, OPContractAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, currency, result)
+
+	mockJSONRPC.AssertExpectations(t)
+	mockCurrencyFetcher.AssertExpectations(t)
+}
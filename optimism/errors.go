@@ -22,4 +22,7 @@ var (
 	ErrCallParametersInvalid = errors.New("call parameters invalid")
 	ErrCallOutputMarshal     = errors.New("call output marshal")
 	ErrCallMethodInvalid     = errors.New("call method invalid")
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrStateUnavailable      = errors.New("state unavailable")
+	ErrBlockCacheDisabled    = errors.New("block cache disabled")
 )
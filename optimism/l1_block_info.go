@@ -0,0 +1,121 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// L1BlockAddr is the L1Block predeploy, which receives the L1 attributes deposit at the start of every bedrock block.
+var L1BlockAddr = EthCommon.HexToAddress("0x4200000000000000000000000000000000000015")
+
+var (
+	// The L1 attributes deposit is ABI encoded before Ecotone and tightly packed afterwards.
+	l1InfoBedrockSelector = crypto.Keccak256([]byte("setL1BlockValues(uint64,uint64,uint256,bytes32,uint64,bytes32,uint256,uint256)"))[:4]
+	l1InfoEcotoneSelector = crypto.Keccak256([]byte("setL1BlockValuesEcotone()"))[:4]
+	l1InfoIsthmusSelector = crypto.Keccak256([]byte("setL1BlockValuesIsthmus()"))[:4]
+)
+
+const (
+	l1InfoBedrockLen = 4 + 32*8
+	l1InfoEcotoneLen = 4 + 4 + 4 + 8 + 8 + 8 + 32 + 32 + 32 + 32
+	l1InfoIsthmusLen = l1InfoEcotoneLen + 4 + 8
+)
+
+// ErrUnknownL1InfoFormat is returned when the L1 attributes deposit calldata is not recognized.
+var ErrUnknownL1InfoFormat = errors.New("unknown L1 attributes format")
+
+// L1BlockInfo is the L1 origin of an L2 block, as set on the L1Block predeploy by the L1 attributes deposit.
+// Fields that do not exist in the format of the deposit are omitted.
+type L1BlockInfo struct {
+	Number         hexutil.Uint64 `json:"l1_block_number"`
+	Time           hexutil.Uint64 `json:"l1_block_timestamp"`
+	BlockHash      EthCommon.Hash `json:"l1_block_hash"`
+	BaseFee        *hexutil.Big   `json:"l1_base_fee"`
+	BlobBaseFee    *hexutil.Big   `json:"l1_blob_base_fee,omitempty"`
+	SequenceNumber hexutil.Uint64 `json:"sequence_number"`
+	BatcherHash    EthCommon.Hash `json:"batcher_hash"`
+
+	// Pre-Ecotone fee parameters
+	L1FeeOverhead *hexutil.Big `json:"l1_fee_overhead,omitempty"`
+	L1FeeScalar   *hexutil.Big `json:"l1_fee_scalar,omitempty"`
+
+	// Ecotone fee parameters
+	BaseFeeScalar     *hexutil.Uint64 `json:"base_fee_scalar,omitempty"`
+	BlobBaseFeeScalar *hexutil.Uint64 `json:"blob_base_fee_scalar,omitempty"`
+
+	// Isthmus fee parameters
+	OperatorFeeScalar   *hexutil.Uint64 `json:"operator_fee_scalar,omitempty"`
+	OperatorFeeConstant *hexutil.Uint64 `json:"operator_fee_constant,omitempty"`
+}
+
+// DecodeL1BlockInfo decodes the calldata of an L1 attributes deposit.
+func DecodeL1BlockInfo(data []byte) (*L1BlockInfo, error) {
+	switch {
+	case len(data) == l1InfoBedrockLen && bytes.HasPrefix(data, l1InfoBedrockSelector):
+		return decodeBedrockL1BlockInfo(data[4:]), nil
+	case len(data) == l1InfoEcotoneLen && bytes.HasPrefix(data, l1InfoEcotoneSelector):
+		return decodeEcotoneL1BlockInfo(data[4:]), nil
+	case len(data) == l1InfoIsthmusLen && bytes.HasPrefix(data, l1InfoIsthmusSelector):
+		info := decodeEcotoneL1BlockInfo(data[4:l1InfoEcotoneLen])
+		operatorFeeScalar := hexutil.Uint64(binary.BigEndian.Uint32(data[l1InfoEcotoneLen:]))
+		operatorFeeConstant := hexutil.Uint64(binary.BigEndian.Uint64(data[l1InfoEcotoneLen+4:]))
+		info.OperatorFeeScalar = &operatorFeeScalar
+		info.OperatorFeeConstant = &operatorFeeConstant
+		return info, nil
+	default:
+		return nil, fmt.Errorf("%w: %d bytes of calldata", ErrUnknownL1InfoFormat, len(data))
+	}
+}
+
+// decodeBedrockL1BlockInfo decodes the ABI encoded arguments of setL1BlockValues.
+func decodeBedrockL1BlockInfo(args []byte) *L1BlockInfo {
+	word := func(i int) []byte { return args[i*32 : (i+1)*32] }
+	return &L1BlockInfo{
+		Number:         hexutil.Uint64(new(big.Int).SetBytes(word(0)).Uint64()),
+		Time:           hexutil.Uint64(new(big.Int).SetBytes(word(1)).Uint64()),
+		BaseFee:        (*hexutil.Big)(new(big.Int).SetBytes(word(2))),
+		BlockHash:      EthCommon.BytesToHash(word(3)),
+		SequenceNumber: hexutil.Uint64(new(big.Int).SetBytes(word(4)).Uint64()),
+		BatcherHash:    EthCommon.BytesToHash(word(5)),
+		L1FeeOverhead:  (*hexutil.Big)(new(big.Int).SetBytes(word(6))),
+		L1FeeScalar:    (*hexutil.Big)(new(big.Int).SetBytes(word(7))),
+	}
+}
+
+// decodeEcotoneL1BlockInfo decodes the packed arguments of setL1BlockValuesEcotone.
+func decodeEcotoneL1BlockInfo(args []byte) *L1BlockInfo {
+	baseFeeScalar := hexutil.Uint64(binary.BigEndian.Uint32(args[0:4]))
+	blobBaseFeeScalar := hexutil.Uint64(binary.BigEndian.Uint32(args[4:8]))
+	return &L1BlockInfo{
+		BaseFeeScalar:     &baseFeeScalar,
+		BlobBaseFeeScalar: &blobBaseFeeScalar,
+		SequenceNumber:    hexutil.Uint64(binary.BigEndian.Uint64(args[8:16])),
+		Time:              hexutil.Uint64(binary.BigEndian.Uint64(args[16:24])),
+		Number:            hexutil.Uint64(binary.BigEndian.Uint64(args[24:32])),
+		BaseFee:           (*hexutil.Big)(new(big.Int).SetBytes(args[32:64])),
+		BlobBaseFee:       (*hexutil.Big)(new(big.Int).SetBytes(args[64:96])),
+		BlockHash:         EthCommon.BytesToHash(args[96:128]),
+		BatcherHash:       EthCommon.BytesToHash(args[128:160]),
+	}
+}
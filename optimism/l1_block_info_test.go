@@ -0,0 +1,122 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	EthCommon "github.com/ethereum/go-ethereum/common"
+	EthHexutil "github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// packEcotoneL1BlockInfo tightly packs the arguments of setL1BlockValuesEcotone.
+func packEcotoneL1BlockInfo(selector []byte) []byte {
+	data := append([]byte{}, selector...)
+	data = binary.BigEndian.AppendUint32(data, 1368)     // base fee scalar
+	data = binary.BigEndian.AppendUint32(data, 810949)   // blob base fee scalar
+	data = binary.BigEndian.AppendUint64(data, 4)        // sequence number
+	data = binary.BigEndian.AppendUint64(data, 1710374)  // timestamp
+	data = binary.BigEndian.AppendUint64(data, 19424563) // number
+	data = append(data, EthCommon.LeftPadBytes(big.NewInt(25_000_000_000).Bytes(), 32)...)
+	data = append(data, EthCommon.LeftPadBytes(big.NewInt(1).Bytes(), 32)...)
+	data = append(data, EthCommon.HexToHash("0x1ed96835176d084c845bd2c09456d60401d74861b690bdabac97f6724f4b4bdf").Bytes()...)
+	data = append(data, EthCommon.HexToHash("0x7431310e026b69bfc676c0013e12a1a11411eec9").Bytes()...)
+	return data
+}
+
+func TestDecodeL1BlockInfo(t *testing.T) {
+	uint64Ptr := func(v uint64) *EthHexutil.Uint64 { return (*EthHexutil.Uint64)(&v) }
+	ecotoneInfo := func() *L1BlockInfo {
+		return &L1BlockInfo{
+			Number:            19424563,
+			Time:              1710374,
+			BlockHash:         EthCommon.HexToHash("0x1ed96835176d084c845bd2c09456d60401d74861b690bdabac97f6724f4b4bdf"),
+			BaseFee:           (*EthHexutil.Big)(big.NewInt(25_000_000_000)),
+			BlobBaseFee:       (*EthHexutil.Big)(big.NewInt(1)),
+			SequenceNumber:    4,
+			BatcherHash:       EthCommon.HexToHash("0x7431310e026b69bfc676c0013e12a1a11411eec9"),
+			BaseFeeScalar:     uint64Ptr(1368),
+			BlobBaseFeeScalar: uint64Ptr(810949),
+		}
+	}
+	isthmusInfo := ecotoneInfo()
+	isthmusInfo.OperatorFeeScalar = uint64Ptr(7)
+	isthmusInfo.OperatorFeeConstant = uint64Ptr(1_000_000)
+
+	tests := map[string]struct {
+		data     []byte
+		expected *L1BlockInfo
+		err      error
+	}{
+		"bedrock": {
+			// Goerli block 5003318
+			data: EthCommon.Hex2Bytes("015d8eb900000000000000000000000000000000000000000000000000000000008097790000000000000000000000000000000000000000000000000000000063dd1a98000000000000000000000000000000000000000000000000000000000004ee2f1ed96835176d084c845bd2c09456d60401d74861b690bdabac97f6724f4b4bdf00000000000000000000000000000000000000000000000000000000000000020000000000000000000000007431310e026b69bfc676c0013e12a1a11411eec9000000000000000000000000000000000000000000000000000000000000083400000000000000000000000000000000000000000000000000000000000f4240"),
+			expected: &L1BlockInfo{
+				Number:         8427385,
+				Time:           1675434648,
+				BlockHash:      EthCommon.HexToHash("0x1ed96835176d084c845bd2c09456d60401d74861b690bdabac97f6724f4b4bdf"),
+				BaseFee:        (*EthHexutil.Big)(big.NewInt(323119)),
+				SequenceNumber: 2,
+				BatcherHash:    EthCommon.HexToHash("0x7431310e026b69bfc676c0013e12a1a11411eec9"),
+				L1FeeOverhead:  (*EthHexutil.Big)(big.NewInt(2100)),
+				L1FeeScalar:    (*EthHexutil.Big)(big.NewInt(1000000)),
+			},
+		},
+		"ecotone": {
+			data:     packEcotoneL1BlockInfo(l1InfoEcotoneSelector),
+			expected: ecotoneInfo(),
+		},
+		"isthmus": {
+			data: binary.BigEndian.AppendUint64(
+				binary.BigEndian.AppendUint32(packEcotoneL1BlockInfo(l1InfoIsthmusSelector), 7),
+				1_000_000,
+			),
+			expected: isthmusInfo,
+		},
+		"ecotone selector with isthmus length": {
+			data: binary.BigEndian.AppendUint64(
+				binary.BigEndian.AppendUint32(packEcotoneL1BlockInfo(l1InfoEcotoneSelector), 7),
+				1_000_000,
+			),
+			err: ErrUnknownL1InfoFormat,
+		},
+		"unknown selector": {
+			data: packEcotoneL1BlockInfo([]byte{0xde, 0xad, 0xbe, 0xef}),
+			err:  ErrUnknownL1InfoFormat,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			info, err := DecodeL1BlockInfo(test.data)
+			if test.err != nil {
+				assert.True(t, errors.Is(err, test.err))
+				assert.Nil(t, info)
+				return
+			}
+			assert.NoError(t, err)
+			// Compare through JSON since that is how the info is exposed as metadata
+			expected, err := MarshalJSONMap(test.expected)
+			assert.NoError(t, err)
+			actual, err := MarshalJSONMap(info)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// MemoryTraceCacheBackend only keeps the traces of a [TraceCache] in memory.
+	MemoryTraceCacheBackend = "MEMORY"
+	// DiskTraceCacheBackend also keeps the traces of a [TraceCache] on disk, across restarts.
+	DiskTraceCacheBackend = "DISK"
+	// RedisTraceCacheBackend also keeps the traces of a [TraceCache] in a Redis-compatible server
+	// shared by several replicas.
+	RedisTraceCacheBackend = "REDIS"
+
+	redisTraceCachePrefix = "rosetta:trace:"
+)
+
+var (
+	diskTraceCacheCountKey = []byte("count")
+	diskTraceCacheSeqKey   = []byte("seq")
+	diskTraceCacheTraceKey = []byte("t/")
+	diskTraceCacheOrderKey = []byte("o/")
+)
+
+// TraceCacheBackend stores the encoded traces of a [TraceCache] outside of its memory.
+type TraceCacheBackend interface {
+	// Get returns the trace stored at key, and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a trace at key.
+	Set(ctx context.Context, key string, value []byte) error
+	Close() error
+}
+
+// diskTraceCacheBackend stores traces in leveldb. Each trace is also indexed by the order
+// in which it was stored, so that the oldest traces are evicted first.
+type diskTraceCacheBackend struct {
+	db   *leveldb.DB
+	size int
+
+	// mu serializes writes, keeping the count and sequence consistent
+	mu    sync.Mutex
+	count uint64
+	seq   uint64
+}
+
+// OpenDiskTraceCacheBackend opens a backend keeping at most size traces at path, creating it if needed.
+func OpenDiskTraceCacheBackend(path string, size int) (TraceCacheBackend, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open trace cache %s", err, path)
+	}
+	b := &diskTraceCacheBackend{db: db, size: size}
+	if b.count, err = b.getUint64(diskTraceCacheCountKey); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if b.seq, err = b.getUint64(diskTraceCacheSeqKey); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *diskTraceCacheBackend) getUint64(key []byte) (uint64, error) {
+	value, err := b.db.Get(key, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func (b *diskTraceCacheBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := b.db.Get(append(append([]byte{}, diskTraceCacheTraceKey...), key...), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (b *diskTraceCacheBackend) Set(ctx context.Context, key string, value []byte) error {
+	traceKey := append(append([]byte{}, diskTraceCacheTraceKey...), key...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok, err := b.db.Has(traceKey, nil); err != nil || ok {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(traceKey, value)
+	batch.Put(append(append([]byte{}, diskTraceCacheOrderKey...), encodeUint64(b.seq)...), []byte(key))
+	count := b.count + 1
+	if count > uint64(b.size) {
+		evicted, err := b.evict(batch, count-uint64(b.size))
+		if err != nil {
+			return err
+		}
+		count -= evicted
+	}
+	batch.Put(diskTraceCacheCountKey, encodeUint64(count))
+	batch.Put(diskTraceCacheSeqKey, encodeUint64(b.seq+1))
+	if err := b.db.Write(batch, nil); err != nil {
+		return err
+	}
+	b.count = count
+	b.seq++
+	return nil
+}
+
+// evict adds the deletion of the n oldest traces to batch
+func (b *diskTraceCacheBackend) evict(batch *leveldb.Batch, n uint64) (uint64, error) {
+	var evicted uint64
+	iter := b.db.NewIterator(util.BytesPrefix(diskTraceCacheOrderKey), nil)
+	defer iter.Release()
+	for evicted < n && iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+		batch.Delete(append(append([]byte{}, diskTraceCacheTraceKey...), iter.Value()...))
+		evicted++
+	}
+	return evicted, iter.Error()
+}
+
+func (b *diskTraceCacheBackend) Close() error {
+	return b.db.Close()
+}
+
+// redisClient is the subset of the go-redis client used by the Redis backend
+type redisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Close() error
+}
+
+type redisTraceCacheBackend struct {
+	client redisClient
+	ttl    time.Duration
+}
+
+// NewRedisTraceCacheBackend returns a backend storing traces in the Redis-compatible server at url.
+// Traces expire after ttl, or are left to the eviction policy of the server if it is zero.
+func NewRedisTraceCacheBackend(url string, ttl time.Duration) (TraceCacheBackend, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid redis url", err)
+	}
+	return &redisTraceCacheBackend{client: redis.NewClient(opts), ttl: ttl}, nil
+}
+
+func (b *redisTraceCacheBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := b.client.Get(ctx, redisTraceCachePrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (b *redisTraceCacheBackend) Set(ctx context.Context, key string, value []byte) error {
+	return b.client.Set(ctx, redisTraceCachePrefix+key, value, b.ttl).Err()
+}
+
+func (b *redisTraceCacheBackend) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// localRedis stands in for a Redis server shared by several replicas
+type localRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newLocalRedis() *localRedis {
+	return &localRedis{values: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+func (r *localRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return redis.NewStringResult("", r.err)
+	}
+	value, ok := r.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (r *localRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return redis.NewStatusResult("", r.err)
+	}
+	r.values[key] = string(value.([]byte))
+	r.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (r *localRedis) Close() error {
+	return nil
+}
+
+func TestDiskTraceCacheBackend(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	backend, err := OpenDiskTraceCacheBackend(path, 2)
+	require.NoError(t, err)
+
+	_, ok, err := backend.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, backend.Set(ctx, "a", []byte("trace a")))
+	assert.NoError(t, backend.Set(ctx, "b", []byte("trace b")))
+	require.NoError(t, backend.Close())
+
+	// Traces are kept across restarts
+	backend, err = OpenDiskTraceCacheBackend(path, 2)
+	require.NoError(t, err)
+	defer backend.Close()
+	value, ok, err := backend.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("trace a"), value)
+
+	// The oldest trace is evicted once the backend is full
+	assert.NoError(t, backend.Set(ctx, "c", []byte("trace c")))
+	_, ok, err = backend.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	for _, key := range []string{"b", "c"} {
+		value, ok, err := backend.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("trace "+key), value)
+	}
+
+	// Storing a trace again does not evict another one
+	assert.NoError(t, backend.Set(ctx, "c", []byte("trace c")))
+	_, ok, err = backend.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRedisTraceCacheBackend(t *testing.T) {
+	ctx := context.Background()
+	server := newLocalRedis()
+	backend := &redisTraceCacheBackend{client: server, ttl: time.Hour}
+
+	_, ok, err := backend.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, backend.Set(ctx, "a", []byte("trace a")))
+	assert.Equal(t, "trace a", server.values["rosetta:trace:a"])
+	assert.Equal(t, time.Hour, server.ttls["rosetta:trace:a"])
+
+	value, ok, err := backend.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("trace a"), value)
+
+	server.err = errors.New("connection refused")
+	_, _, err = backend.Get(ctx, "a")
+	assert.Error(t, err)
+	assert.Error(t, backend.Set(ctx, "a", []byte("trace a")))
+}
+
+func TestNewRedisTraceCacheBackend(t *testing.T) {
+	backend, err := NewRedisTraceCacheBackend("redis://localhost:6379/1", 0)
+	assert.NoError(t, err)
+	assert.NoError(t, backend.Close())
+
+	_, err = NewRedisTraceCacheBackend("localhost:6379", 0)
+	assert.Error(t, err)
+}
@@ -16,7 +16,10 @@ package optimism
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"sync"
 	"time"
@@ -24,6 +27,7 @@ import (
 	"github.com/ethereum-optimism/optimism/l2geth/common"
 	"github.com/ethereum-optimism/optimism/l2geth/eth"
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/inphi/optimism-rosetta/metrics"
 )
 
 const defaultTracerPath = "optimism/call_tracer.js"
@@ -53,12 +57,17 @@ func loadTraceConfig(opt tracerSpec, timeout time.Duration) (*eth.TraceConfig, e
 
 type traceCacheEntry struct {
 	pending chan struct{}
-	result  *Call
+	result  interface{}
 	err     error
 }
 
+// TraceCache deduplicates and caches the transaction and block traces requested from the
+// node. Traces are kept in memory, and also in an optional [TraceCacheBackend] that persists
+// them or shares them between replicas.
 type TraceCache interface {
 	FetchTransaction(ctx context.Context, txhash common.Hash) (*Call, error)
+	FetchBlock(ctx context.Context, blockHash common.Hash, tc *eth.TraceConfig) ([]*Call, error)
+	Close() error
 }
 
 type traceCache struct {
@@ -66,10 +75,18 @@ type traceCache struct {
 	tc            *eth.TraceConfig
 	tracerTimeout time.Duration
 	cache         *lru.Cache
+	backend       TraceCacheBackend
 	m             sync.Mutex
 }
 
-func NewTraceCache(client JSONRPC, opt tracerSpec, tracerTimeout time.Duration, cacheSize int) (TraceCache, error) {
+// NewTraceCache creates a TraceCache keeping cacheSize traces in memory. The backend may be nil.
+func NewTraceCache(
+	client JSONRPC,
+	opt tracerSpec,
+	tracerTimeout time.Duration,
+	cacheSize int,
+	backend TraceCacheBackend,
+) (TraceCache, error) {
 	cache, _ := lru.New(cacheSize)
 	tc, err := loadTraceConfig(opt, tracerTimeout)
 	if err != nil {
@@ -81,22 +98,90 @@ func NewTraceCache(client JSONRPC, opt tracerSpec, tracerTimeout time.Duration,
 		tc:            tc,
 		tracerTimeout: tracerTimeout,
 		cache:         cache,
+		backend:       backend,
 	}, nil
 }
 
 func (t *traceCache) FetchTransaction(ctx context.Context, txhash common.Hash) (*Call, error) {
+	key := txhash.Hex()
+	result, err := t.fetch(ctx, key, func(ctx context.Context) (interface{}, error) {
+		backendKey := traceCacheKey("tx", t.tc, txhash)
+		if data, ok := t.backendGet(ctx, backendKey); ok {
+			var result Call
+			if err := json.Unmarshal(data, &result); err == nil {
+				return &result, nil
+			}
+		}
+
+		result := new(Call)
+		if err := t.client.CallContext(ctx, result, "debug_traceTransaction", txhash.Hex(), t.tc); err != nil {
+			return nil, err
+		}
+		if t.backend != nil {
+			if data, err := json.Marshal(result); err == nil {
+				t.backendSet(ctx, backendKey, data)
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Call), nil
+}
+
+// FetchBlock returns the traces of the transactions of a block, traced with tc.
+func (t *traceCache) FetchBlock(ctx context.Context, blockHash common.Hash, tc *eth.TraceConfig) ([]*Call, error) {
+	key := traceCacheKey("block", tc, blockHash)
+	result, err := t.fetch(ctx, key, func(ctx context.Context) (interface{}, error) {
+		if data, ok := t.backendGet(ctx, key); ok {
+			if calls, err := decodeBlockTraces(data); err == nil {
+				return calls, nil
+			}
+		}
+
+		var raw json.RawMessage
+		if err := t.client.CallContext(ctx, &raw, "debug_traceBlockByHash", blockHash, tc); err != nil {
+			return nil, err
+		}
+		calls, err := decodeBlockTraces(raw)
+		if err != nil {
+			return nil, err
+		}
+		t.backendSet(ctx, key, raw)
+		return calls, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*Call), nil
+}
+
+// Close closes the backend of the cache.
+func (t *traceCache) Close() error {
+	if t.backend == nil {
+		return nil
+	}
+	return t.backend.Close()
+}
+
+// fetch returns the cached result at key, calling request at most once for concurrent
+// fetches of a key that is not cached yet
+func (t *traceCache) fetch(ctx context.Context, key string, request func(context.Context) (interface{}, error)) (interface{}, error) {
 	t.m.Lock()
 
 	var entry *traceCacheEntry
-	if lruEntry, ok := t.cache.Get(txhash.Hex()); ok {
+	lruEntry, ok := t.cache.Get(key)
+	metrics.ObserveCache(metrics.TraceCache, ok)
+	if ok {
 		entry = lruEntry.(*traceCacheEntry)
 	}
 
 	if entry == nil {
-		entry = &traceCacheEntry{make(chan struct{}), new(Call), nil}
-		t.cache.Add(txhash.Hex(), entry)
+		entry = &traceCacheEntry{make(chan struct{}), nil, nil}
+		t.cache.Add(key, entry)
 
-		go t.requestTrace(txhash, entry)
+		go t.requestTrace(key, entry, request)
 	}
 
 	t.m.Unlock()
@@ -110,19 +195,67 @@ func (t *traceCache) FetchTransaction(ctx context.Context, txhash common.Hash) (
 	return entry.result, entry.err
 }
 
-func (t *traceCache) requestTrace(txhash common.Hash, entry *traceCacheEntry) {
+func (t *traceCache) requestTrace(key string, entry *traceCacheEntry, request func(context.Context) (interface{}, error)) {
 	// tracer evm execution timeout + some additional time for I/O
 	tracerTimeout := t.tracerTimeout + time.Second
 	callCtx, cancel := context.WithTimeout(context.Background(), tracerTimeout)
 	defer cancel()
-	entry.err = t.client.CallContext(callCtx, entry.result, "debug_traceTransaction", txhash.Hex(), t.tc)
+	entry.result, entry.err = request(callCtx)
 
 	close(entry.pending)
 
 	if entry.err != nil {
 		// It's fine if FetchTransaction calls read the old errant cache entry before it's removed
 		t.m.Lock()
-		t.cache.Remove(txhash.String())
+		t.cache.Remove(key)
 		t.m.Unlock()
 	}
 }
+
+// backendGet returns the trace stored in the backend at key, if any. An unavailable
+// backend only costs a trace from the node.
+func (t *traceCache) backendGet(ctx context.Context, key string) ([]byte, bool) {
+	if t.backend == nil {
+		return nil, false
+	}
+	data, ok, err := t.backend.Get(ctx, key)
+	if err != nil {
+		log.Printf("unable to read trace %s from the trace cache backend: %v", key, err)
+		return nil, false
+	}
+	metrics.ObserveCache(metrics.TraceCacheBackend, ok)
+	return data, ok
+}
+
+func (t *traceCache) backendSet(ctx context.Context, key string, data []byte) {
+	if t.backend == nil {
+		return
+	}
+	if err := t.backend.Set(ctx, key, data); err != nil {
+		log.Printf("unable to write trace %s to the trace cache backend: %v", key, err)
+	}
+}
+
+// traceCacheKey returns the key of a trace in the backend. It includes the tracer, as
+// replicas sharing a backend may trace with different tracers.
+func traceCacheKey(kind string, tc *eth.TraceConfig, hash common.Hash) string {
+	var tracer string
+	if tc != nil && tc.Tracer != nil {
+		tracer = *tc.Tracer
+	}
+	tracerHash := sha256.Sum256([]byte(tracer))
+	return fmt.Sprintf("%s/%x/%s", kind, tracerHash[:8], hash.Hex())
+}
+
+// decodeBlockTraces decodes the debug_traceBlockByHash traces of the transactions of a block
+func decodeBlockTraces(data []byte) ([]*Call, error) {
+	var calls []*rpcCall
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil, err
+	}
+	results := make([]*Call, len(calls))
+	for i, call := range calls {
+		results[i] = call.Result
+	}
+	return results, nil
+}
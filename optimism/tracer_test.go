@@ -16,11 +16,14 @@ package optimism
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/l2geth/common"
+	"github.com/ethereum-optimism/optimism/l2geth/eth"
 	mocks "github.com/inphi/optimism-rosetta/mocks/optimism"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -31,7 +34,7 @@ func TestTracerFetch_HappyPath(t *testing.T) {
 
 	mockClient := &mocks.JSONRPC{}
 	tspec := tracerSpec{TracerPath: "call_tracer.js"}
-	cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10)
+	cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10, nil)
 	assert.NoError(t, err)
 
 	tc, err := loadTraceConfig(tspec, time.Second*1)
@@ -65,7 +68,7 @@ func TestTracerFetch_ExpiredContext(t *testing.T) {
 
 	mockClient := &mocks.JSONRPC{}
 	tspec := tracerSpec{TracerPath: "call_tracer.js"}
-	cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10)
+	cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10, nil)
 	assert.NoError(t, err)
 
 	expect := Call{Type: "ekans"}
@@ -103,7 +106,7 @@ func TestTracerFetchErroneousCacheEntry(t *testing.T) {
 
 	mockClient := &mocks.JSONRPC{}
 	tspec := tracerSpec{TracerPath: "call_tracer.js"}
-	cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10)
+	cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10, nil)
 	assert.NoError(t, err)
 
 	expectedError := errors.New("error")
@@ -124,3 +127,119 @@ func TestTracerFetchErroneousCacheEntry(t *testing.T) {
 	_, ok := cache.(*traceCache).cache.Peek(common.Hash{}.String())
 	assert.False(t, ok)
 }
+
+func TestTracerFetch_SharedBackend(t *testing.T) {
+	ctx := context.Background()
+	server := newLocalRedis()
+	tspec := tracerSpec{TracerPath: "call_tracer.js"}
+	hash := common.HexToHash("0x5e77a04531c7c107af1882d76cbff9486d0a9aa53701c30888509d4f5f2b003a")
+
+	// The transaction is only traced by the first of the replicas sharing a backend
+	mockClient := &mocks.JSONRPC{}
+	mockClient.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"debug_traceTransaction",
+		hash.Hex(),
+		mock.Anything,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*Call)
+			*r = Call{Type: "CALL", Value: big.NewInt(100), GasUsed: big.NewInt(21000), ErrorMessage: "reverted", Revert: true}
+		},
+	).Once()
+
+	for i := 0; i < 2; i++ {
+		cache, err := NewTraceCache(mockClient, tspec, time.Second*1, 10, &redisTraceCacheBackend{client: server})
+		assert.NoError(t, err)
+		call, err := cache.FetchTransaction(ctx, hash)
+		assert.NoError(t, err)
+		assert.Equal(t, "CALL", call.Type)
+		assert.Zero(t, call.Value.Cmp(big.NewInt(100)))
+		assert.Zero(t, call.GasUsed.Cmp(big.NewInt(21000)))
+		assert.True(t, call.Revert)
+		assert.Equal(t, "reverted", call.ErrorMessage)
+	}
+	mockClient.AssertExpectations(t)
+
+	// Replicas with another tracer trace the transaction again
+	otherClient := &mocks.JSONRPC{}
+	otherClient.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"debug_traceTransaction",
+		hash.Hex(),
+		mock.Anything,
+	).Return(
+		nil,
+	).Once()
+	cache, err := NewTraceCache(otherClient, tracerSpec{UseGethTracer: true}, time.Second*1, 10, &redisTraceCacheBackend{client: server})
+	assert.NoError(t, err)
+	_, err = cache.FetchTransaction(ctx, hash)
+	assert.NoError(t, err)
+	otherClient.AssertExpectations(t)
+
+	// An unavailable backend falls back to tracing
+	server.err = errors.New("connection refused")
+	cache, err = NewTraceCache(otherClient, tracerSpec{UseGethTracer: true}, time.Second*1, 10, &redisTraceCacheBackend{client: server})
+	assert.NoError(t, err)
+	otherClient.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"debug_traceTransaction",
+		hash.Hex(),
+		mock.Anything,
+	).Return(
+		nil,
+	).Once()
+	_, err = cache.FetchTransaction(ctx, hash)
+	assert.NoError(t, err)
+	otherClient.AssertExpectations(t)
+}
+
+func TestTracerFetchBlock(t *testing.T) {
+	ctx := context.Background()
+	server := newLocalRedis()
+	tspec := tracerSpec{TracerPath: "call_tracer.js"}
+
+	tracer := "callTracer"
+	tc := &eth.TraceConfig{Tracer: &tracer}
+	blockHash := common.HexToHash("0x4503cbd671b3ca292e9f54998b2d566b705a32a178fc467f311c79b43e8e1774")
+	mockClient := &mocks.JSONRPC{}
+	mockClient.On(
+		"CallContext",
+		mock.Anything,
+		mock.Anything,
+		"debug_traceBlockByHash",
+		blockHash,
+		tc,
+	).Return(
+		nil,
+	).Run(
+		func(args mock.Arguments) {
+			r := args.Get(1).(*json.RawMessage)
+			*r = json.RawMessage(`[{"result":{"type":"CALL","value":"0x64","gasUsed":"0x5208"}},{"result":{"type":"CREATE","error":"out of gas"}}]`)
+		},
+	).Once()
+
+	// The block is traced once, then read from memory and from the backend
+	first, err := NewTraceCache(mockClient, tspec, time.Second*1, 10, &redisTraceCacheBackend{client: server})
+	assert.NoError(t, err)
+	second, err := NewTraceCache(mockClient, tspec, time.Second*1, 10, &redisTraceCacheBackend{client: server})
+	assert.NoError(t, err)
+	for _, cache := range []TraceCache{first, first, second} {
+		calls, err := cache.FetchBlock(ctx, blockHash, tc)
+		assert.NoError(t, err)
+		assert.Len(t, calls, 2)
+		assert.Equal(t, "CALL", calls[0].Type)
+		assert.Zero(t, calls[0].Value.Cmp(big.NewInt(100)))
+		assert.Equal(t, "CREATE", calls[1].Type)
+		assert.True(t, calls[1].Revert)
+	}
+	mockClient.AssertExpectations(t)
+}
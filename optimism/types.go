@@ -33,6 +33,10 @@ import (
 const (
 	// MintOpType is a [RosettaTypes.Operation] type for an Optimism Deposit or "mint" transaction.
 	MintOpType = "MINT"
+	// WithdrawalInitiatedOpType is a [RosettaTypes.Operation] type for an L2 to L1 withdrawal
+	// passed to the L2ToL1MessagePasser. It carries no amount, since the ETH sent along is already
+	// represented by the traced call to the message passer.
+	WithdrawalInitiatedOpType = "WITHDRAWAL_INITIATED"
 	// An erroneous STOP Type not defined in rosetta-geth-sdk
 	StopOpType = "STOP"
 )
@@ -45,6 +49,9 @@ const (
 
 	UnknownERC721Symbol   = "ERC721_UNKNOWN"
 	UnknownERC721Decimals = 0
+
+	UnknownERC1155Symbol   = "ERC1155_UNKNOWN"
+	UnknownERC1155Decimals = 0
 )
 
 const (
@@ -80,6 +87,9 @@ var (
 	// Once the contract has received a certain amount of fees,
 	// the ETH can be permissionlessly withdrawn to an immutable address on L1.
 	L1FeeVault = EthCommon.HexToAddress("0x420000000000000000000000000000000000001a")
+
+	// The OperatorFeeVault predeploy receives the operator fees introduced by the Isthmus hardfork.
+	OperatorFeeVault = EthCommon.HexToAddress("0x420000000000000000000000000000000000001b")
 )
 
 const (
@@ -131,6 +141,18 @@ const (
 	// ERC20TransferOpType is used to represent token transfer operations
 	ERC20TransferOpType = "ERC20_TRANSFER"
 
+	// ERC721TransferOpType is used to represent ERC721 token transfer operations
+	ERC721TransferOpType = "ERC721_TRANSFER"
+
+	// ERC1155TransferOpType is used to represent ERC1155 token transfer operations
+	ERC1155TransferOpType = "ERC1155_TRANSFER"
+
+	// ERC20BridgeMintOpType is used to represent token mints by the L2StandardBridge that finalize a deposit
+	ERC20BridgeMintOpType = "ERC20_BRIDGE_MINT"
+
+	// ERC20BridgeBurnOpType is used to represent token burns by the L2StandardBridge that initiate a withdrawal
+	ERC20BridgeBurnOpType = "ERC20_BRIDGE_BURN"
+
 	// CallOpType is used to represent CALL trace operations.
 	CallOpType = "CALL"
 
@@ -160,6 +182,13 @@ const (
 	// DelegateVotesOpType is used to represent OZ ERC20Votes votes delegation
 	DelegateVotesOpType = "DELEGATE_VOTES"
 
+	// ERC20ApproveOpType is used to represent ERC20 allowance approvals
+	ERC20ApproveOpType = "ERC20_APPROVE"
+
+	// ERC20TransferFromOpType is used to represent ERC20 transfers
+	// made by a spender out of the allowance of the token owner
+	ERC20TransferFromOpType = "ERC20_TRANSFER_FROM"
+
 	// SuccessStatus is the status of any
 	// Ethereum operation considered successful.
 	SuccessStatus = "SUCCESS"
@@ -168,6 +197,10 @@ const (
 	// Ethereum operation considered unsuccessful.
 	FailureStatus = "FAILURE"
 
+	// PendingStatus is the status of an operation
+	// estimated from a transaction that is still in the mempool.
+	PendingStatus = "PENDING"
+
 	// HistoricalBalanceSupported is whether
 	// historical balance is supported.
 	HistoricalBalanceSupported = true
@@ -189,6 +222,10 @@ const (
 	// ContractAddressKey is the key used to denote the contract address
 	// for a token, provided via Currency metadata.
 	ContractAddressKey string = "token_address"
+
+	// TokenIDKey is the key used to denote the token ID of an ERC721 or ERC1155 token,
+	// provided via Currency metadata.
+	TokenIDKey string = "token_id"
 )
 
 // RPC Methods
@@ -204,6 +241,24 @@ const (
 
 	// EthEstimateGas is the RPC method used to estimate gas.
 	EthEstimateGas = "eth_estimateGas"
+
+	// EthGetTransactionByHash is the RPC method used to fetch a transaction by hash.
+	EthGetTransactionByHash = "eth_getTransactionByHash"
+
+	// EthBlockNumber is the RPC method used to fetch the latest block number.
+	EthBlockNumber = "eth_blockNumber"
+
+	// EthNewPendingTransactionFilter is the RPC method used to install a pending transaction filter.
+	EthNewPendingTransactionFilter = "eth_newPendingTransactionFilter"
+
+	// EthGetFilterChanges is the RPC method used to poll a filter.
+	EthGetFilterChanges = "eth_getFilterChanges"
+
+	// EthUninstallFilter is the RPC method used to remove a filter.
+	EthUninstallFilter = "eth_uninstallFilter"
+
+	// TxPoolContent is the RPC method used to fetch the contents of the transaction pool.
+	TxPoolContent = "txpool_content"
 )
 
 var (
@@ -269,7 +324,15 @@ var (
 		StaticCallOpType,
 		DestructOpType,
 		DelegateVotesOpType,
+		ERC20ApproveOpType,
+		ERC20TransferFromOpType,
 		ERC20TransferOpType,
+		MintOpType,
+		WithdrawalInitiatedOpType,
+		ERC20BridgeMintOpType,
+		ERC20BridgeBurnOpType,
+		ERC721TransferOpType,
+		ERC1155TransferOpType,
 	}
 
 	// OperationStatuses are all supported operation statuses.
@@ -282,6 +345,10 @@ var (
 			Status:     FailureStatus,
 			Successful: false,
 		},
+		{
+			Status:     PendingStatus,
+			Successful: false,
+		},
 	}
 
 	// CallMethods are all supported call methods.
@@ -343,6 +410,15 @@ type L2Receipt struct {
 	L1GasUsed  *hexutil.Uint64 `json:"l1GasUsed,omitempty"`
 	L1Fee      *hexutil.Uint64 `json:"l1Fee,omitempty"`
 	FeeScalar  *big.Float      `json:"l1FeeScalar,omitempty"` // always nil after Ecotone hardfork
+
+	// Ecotone: the L1 fee is computed from the L1 base fee and blob base fee, each with its own scalar
+	L1BaseFeeScalar     *hexutil.Uint64 `json:"l1BaseFeeScalar,omitempty"`
+	L1BlobBaseFee       *hexutil.Big    `json:"l1BlobBaseFee,omitempty"`
+	L1BlobBaseFeeScalar *hexutil.Uint64 `json:"l1BlobBaseFeeScalar,omitempty"`
+
+	// Isthmus: non-deposit transactions are charged an operator fee, paid to the OperatorFeeVault
+	OperatorFeeScalar   *hexutil.Uint64 `json:"operatorFeeScalar,omitempty"`
+	OperatorFeeConstant *hexutil.Uint64 `json:"operatorFeeConstant,omitempty"`
 }
 
 // CallType returns a boolean indicating
@@ -102,6 +102,30 @@ func (t *Call) UnmarshalJSON(input []byte) error {
 	return nil
 }
 
+// MarshalJSON encodes a Call in the tracer output format read by [Call.UnmarshalJSON].
+func (t *Call) MarshalJSON() ([]byte, error) {
+	type CustomTrace struct {
+		Type         string            `json:"type"`
+		From         EthCommon.Address `json:"from"`
+		To           EthCommon.Address `json:"to"`
+		Value        *EthHexUtil.Big   `json:"value,omitempty"`
+		GasUsed      *EthHexUtil.Big   `json:"gasUsed,omitempty"`
+		Input        string            `json:"input"`
+		ErrorMessage string            `json:"error,omitempty"`
+		Calls        []*Call           `json:"calls,omitempty"`
+	}
+	return json.Marshal(&CustomTrace{
+		Type:         t.Type,
+		From:         t.From,
+		To:           t.To,
+		Value:        (*EthHexUtil.Big)(t.Value),
+		GasUsed:      (*EthHexUtil.Big)(t.GasUsed),
+		Input:        t.Input,
+		ErrorMessage: t.ErrorMessage,
+		Calls:        t.Calls,
+	})
+}
+
 // flattenTraces recursively flattens all traces.
 func flattenTraces(data *Call, flattened []*FlatCall) []*FlatCall {
 	//nolint:gocritic
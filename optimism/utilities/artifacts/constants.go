@@ -25,8 +25,21 @@ import (
 //go:embed abi/ERC20.abi
 var erc20ABIString string
 
+//go:embed abi/ERC721.abi
+var erc721ABIString string
+
+//go:embed abi/ERC1155.abi
+var erc1155ABIString string
+
+//go:embed abi/Multicall3.abi
+var multicall3ABIString string
+
 var (
-	ERC20ABI = mustParse(erc20ABIString)
+	ERC20ABI   = mustParse(erc20ABIString)
+	ERC721ABI  = mustParse(erc721ABIString)
+	ERC1155ABI = mustParse(erc1155ABIString)
+
+	Multicall3ABI = mustParse(multicall3ABIString)
 )
 
 func mustParse(str string) abi.ABI {
@@ -16,8 +16,10 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/inphi/optimism-rosetta/configuration"
+	"github.com/inphi/optimism-rosetta/optimism"
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
@@ -54,6 +56,9 @@ func (s *AccountAPIService) AccountBalance(
 		request.BlockIdentifier,
 		request.Currencies,
 	)
+	if errors.Is(err, optimism.ErrStateUnavailable) {
+		return nil, wrapErr(ErrStateUnavailable, err)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrGeth, err)
 	}
@@ -16,6 +16,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/inphi/optimism-rosetta/configuration"
@@ -111,3 +112,37 @@ func TestAccountBalance_Online(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestAccountBalance_StateUnavailable(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewAccountAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	account := &types.AccountIdentifier{
+		Address: "hello",
+	}
+	block := types.ConstructPartialBlockIdentifier(&types.BlockIdentifier{
+		Index: 1000,
+		Hash:  "block 1000",
+	})
+
+	mockClient.On(
+		"Balance",
+		ctx,
+		account,
+		block,
+		[]*types.Currency(nil),
+	).Return(nil, fmt.Errorf("err encountered for currency mock; %w", optimism.ErrStateUnavailable)).Once()
+
+	bal, err := servicer.AccountBalance(ctx, &types.AccountBalanceRequest{
+		AccountIdentifier: account,
+		BlockIdentifier:   block,
+	})
+	assert.Nil(t, bal)
+	assert.Equal(t, ErrStateUnavailable.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
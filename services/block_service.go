@@ -50,7 +50,14 @@ func (s *BlockAPIService) Block(
 		return nil, ErrUnavailableOffline
 	}
 
-	block, err := s.client.Block(ctx, request.BlockIdentifier)
+	var block *types.Block
+	var otherTxs []*types.TransactionIdentifier
+	var err error
+	if s.config.OtherTransactionsThreshold > 0 {
+		block, otherTxs, err = s.client.BlockWithOtherTransactions(ctx, request.BlockIdentifier)
+	} else {
+		block, err = s.client.Block(ctx, request.BlockIdentifier)
+	}
 	if errors.Is(err, optimism.ErrBlockOrphaned) {
 		return nil, wrapErr(ErrBlockOrphaned, err)
 	}
@@ -59,7 +66,8 @@ func (s *BlockAPIService) Block(
 	}
 
 	return &types.BlockResponse{
-		Block: block,
+		Block:             block,
+		OtherTransactions: otherTxs,
 	}, nil
 }
 
@@ -68,5 +76,22 @@ func (s *BlockAPIService) BlockTransaction(
 	ctx context.Context,
 	request *types.BlockTransactionRequest,
 ) (*types.BlockTransactionResponse, *types.Error) {
-	return nil, wrapErr(ErrUnimplemented, nil)
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	tx, err := s.client.BlockTransaction(ctx, request.BlockIdentifier, request.TransactionIdentifier)
+	if errors.Is(err, optimism.ErrBlockOrphaned) {
+		return nil, wrapErr(ErrBlockOrphaned, err)
+	}
+	if errors.Is(err, optimism.ErrTransactionNotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	return &types.BlockTransactionResponse{
+		Transaction: tx,
+	}, nil
 }
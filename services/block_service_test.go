@@ -41,8 +41,8 @@ func TestBlockService_Offline(t *testing.T) {
 
 	blockTransaction, err := servicer.BlockTransaction(ctx, &types.BlockTransactionRequest{})
 	assert.Nil(t, blockTransaction)
-	assert.Equal(t, ErrUnimplemented.Code, err.Code)
-	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+	assert.Equal(t, ErrUnavailableOffline.Message, err.Message)
 
 	mockClient.AssertExpectations(t)
 }
@@ -105,3 +105,90 @@ func TestBlockService_Online(t *testing.T) {
 
 	mockClient.AssertExpectations(t)
 }
+
+func TestBlockService_OtherTransactions(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:                       configuration.Online,
+		OtherTransactionsThreshold: 1,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{
+			Index: 100,
+			Hash:  "block 100",
+		},
+		Transactions: []*types.Transaction{},
+	}
+	otherTxs := []*types.TransactionIdentifier{
+		{Hash: "tx 1"},
+		{Hash: "tx 2"},
+	}
+
+	pbIdentifier := types.ConstructPartialBlockIdentifier(block.BlockIdentifier)
+	mockClient.On("BlockWithOtherTransactions", ctx, pbIdentifier).Return(block, otherTxs, nil).Once()
+	b, err := servicer.Block(ctx, &types.BlockRequest{
+		BlockIdentifier: pbIdentifier,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.BlockResponse{
+		Block:             block,
+		OtherTransactions: otherTxs,
+	}, b)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBlockService_BlockTransaction(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewBlockAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	blockIdentifier := &types.BlockIdentifier{
+		Index: 100,
+		Hash:  "block 100",
+	}
+	tx := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{
+			Hash: "tx 1",
+		},
+	}
+
+	t.Run("found", func(t *testing.T) {
+		mockClient.On("BlockTransaction", ctx, blockIdentifier, tx.TransactionIdentifier).Return(tx, nil).Once()
+		resp, err := servicer.BlockTransaction(ctx, &types.BlockTransactionRequest{
+			BlockIdentifier:       blockIdentifier,
+			TransactionIdentifier: tx.TransactionIdentifier,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.BlockTransactionResponse{Transaction: tx}, resp)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		missing := &types.TransactionIdentifier{Hash: "tx 2"}
+		mockClient.On("BlockTransaction", ctx, blockIdentifier, missing).Return(nil, optimism.ErrTransactionNotFound).Once()
+		resp, err := servicer.BlockTransaction(ctx, &types.BlockTransactionRequest{
+			BlockIdentifier:       blockIdentifier,
+			TransactionIdentifier: missing,
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+	})
+
+	t.Run("orphaned block", func(t *testing.T) {
+		mockClient.On("BlockTransaction", ctx, blockIdentifier, tx.TransactionIdentifier).Return(nil, optimism.ErrBlockOrphaned).Once()
+		resp, err := servicer.BlockTransaction(ctx, &types.BlockTransactionRequest{
+			BlockIdentifier:       blockIdentifier,
+			TransactionIdentifier: tx.TransactionIdentifier,
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, ErrBlockOrphaned.Code, err.Code)
+	})
+
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,331 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// MethodSignatureKey and MethodArgsKey hold the decoded method of a contract call
+	// in the operation metadata returned by /construction/parse
+	MethodSignatureKey = "method_signature"
+	MethodArgsKey      = "method_args"
+)
+
+var bigIntType = reflect.TypeOf(&big.Int{})
+
+// abiRegistry returns the configured ABI registry, which is nil if there is none.
+func (s *ConstructionAPIService) abiRegistry() *configuration.ABIRegistry {
+	if s.config == nil {
+		return nil
+	}
+	return s.config.ABIRegistry
+}
+
+// argName is the name of the i-th argument of a method or tuple, which may be unnamed.
+func argName(name string, i int) string {
+	if len(name) == 0 {
+		return fmt.Sprintf("arg%d", i)
+	}
+	return name
+}
+
+// orderedArgs returns the JSON values of a method or tuple in the order of its arguments.
+// The values are either a list in that order or an object keyed by argument name.
+func orderedArgs(names []string, value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		if len(names) > 0 {
+			return nil, fmt.Errorf("expected %d arguments", len(names))
+		}
+		return nil, nil
+	case []string:
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return orderedArgs(names, values)
+	case []interface{}:
+		if len(v) != len(names) {
+			return nil, fmt.Errorf("expected %d arguments but got %d", len(names), len(v))
+		}
+		return v, nil
+	case map[string]interface{}:
+		if len(v) != len(names) {
+			return nil, fmt.Errorf("expected %d arguments but got %d", len(names), len(v))
+		}
+		values := make([]interface{}, len(names))
+		for i, name := range names {
+			arg, ok := v[argName(name, i)]
+			if !ok {
+				return nil, fmt.Errorf("missing argument %s", argName(name, i))
+			}
+			values[i] = arg
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("arguments must be a list or an object, got %T", value)
+	}
+}
+
+// encodeABIArgs packs JSON method arguments with the inputs of an ABI method.
+// Tuple and array arguments may be nested to any depth.
+func encodeABIArgs(method *abi.Method, methodArgs interface{}) ([]byte, error) {
+	names := make([]string, len(method.Inputs))
+	for i, input := range method.Inputs {
+		names[i] = input.Name
+	}
+	values, err := orderedArgs(names, methodArgs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid method_args for %s", err, method.Sig)
+	}
+
+	packed := make([]interface{}, len(values))
+	for i, input := range method.Inputs {
+		v, err := abiValueFromJSON(input.Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid argument %s of %s", err, argName(input.Name, i), method.Sig)
+		}
+		packed[i] = v.Interface()
+	}
+
+	return method.Inputs.Pack(packed...)
+}
+
+// jsonBigInt parses an integer given as a decimal or 0x-prefixed hex string, or as a JSON number.
+func jsonBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an integer", v)
+		}
+		return n, nil
+	case float64:
+		n, accuracy := big.NewFloat(v).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("expected an integer, got %T", value)
+	}
+}
+
+// jsonBytes decodes a 0x-prefixed hex string.
+func jsonBytes(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a hex string, got %T", value)
+	}
+	return hexutil.Decode(s)
+}
+
+// jsonList returns the elements of a JSON array.
+func jsonList(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, nil
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+}
+
+// abiValueFromJSON converts a JSON value to the Go value that go-ethereum packs for typ.
+//
+//nolint:gocognit
+func abiValueFromJSON(typ abi.Type, value interface{}) (reflect.Value, error) {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := jsonBigInt(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if typ.T == abi.UintTy && (n.Sign() < 0 || n.BitLen() > typ.Size) {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s", n, typ)
+		}
+		if typ.T == abi.IntTy {
+			limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+			if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+				return reflect.Value{}, fmt.Errorf("%s overflows %s", n, typ)
+			}
+		}
+		switch {
+		case typ.GetType() == bigIntType:
+			return reflect.ValueOf(n), nil
+		case typ.T == abi.UintTy:
+			return reflect.ValueOf(n.Uint64()).Convert(typ.GetType()), nil
+		default:
+			return reflect.ValueOf(n.Int64()).Convert(typ.GetType()), nil
+		}
+
+	case abi.BoolTy:
+		switch v := value.(type) {
+		case bool:
+			return reflect.ValueOf(v), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(b), nil
+		default:
+			return reflect.Value{}, fmt.Errorf("expected a bool, got %T", value)
+		}
+
+	case abi.AddressTy:
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("%v is not a valid address", value)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.StringTy:
+		s, ok := value.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expected a string, got %T", value)
+		}
+		return reflect.ValueOf(s), nil
+
+	case abi.BytesTy:
+		b, err := jsonBytes(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.FixedBytesTy:
+		b, err := jsonBytes(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(b) != typ.Size {
+			return reflect.Value{}, fmt.Errorf("received %d bytes for %s", len(b), typ)
+		}
+		array := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(array, reflect.ValueOf(b))
+		return array, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		list, err := jsonList(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		var out reflect.Value
+		if typ.T == abi.SliceTy {
+			out = reflect.MakeSlice(typ.GetType(), len(list), len(list))
+		} else {
+			if len(list) != typ.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d elements for %s but got %d", typ.Size, typ, len(list))
+			}
+			out = reflect.New(typ.GetType()).Elem()
+		}
+		for i, elem := range list {
+			v, err := abiValueFromJSON(*typ.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%w: at index %d", err, i)
+			}
+			out.Index(i).Set(v)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		values, err := orderedArgs(typ.TupleRawNames, value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(typ.GetType()).Elem()
+		for i, elem := range typ.TupleElems {
+			v, err := abiValueFromJSON(*elem, values[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%w: in %s", err, argName(typ.TupleRawNames[i], i))
+			}
+			out.Field(i).Set(v)
+		}
+		return out, nil
+
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+// decodeABIArgs unpacks the arguments of a contract call into JSON values keyed by argument name.
+func decodeABIArgs(method *abi.Method, data []byte) (map[string]interface{}, error) {
+	if len(data) < 4 {
+		return nil, errors.New("calldata is missing the method selector")
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode arguments of %s", err, method.Sig)
+	}
+
+	args := make(map[string]interface{}, len(values))
+	for i, input := range method.Inputs {
+		args[argName(input.Name, i)] = abiValueToJSON(input.Type, reflect.ValueOf(values[i]))
+	}
+	return args, nil
+}
+
+// abiValueToJSON converts an unpacked value to its JSON representation, the inverse of [abiValueFromJSON].
+// Integers are decimal strings and byte arrays are hex strings.
+func abiValueToJSON(typ abi.Type, value reflect.Value) interface{} {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		return fmt.Sprint(value.Interface())
+	case abi.BoolTy:
+		return value.Bool()
+	case abi.AddressTy:
+		return value.Interface().(common.Address).Hex()
+	case abi.StringTy:
+		return value.String()
+	case abi.BytesTy:
+		return hexutil.Encode(value.Bytes())
+	case abi.FixedBytesTy:
+		b := make([]byte, value.Len())
+		reflect.Copy(reflect.ValueOf(b), value)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		list := make([]interface{}, value.Len())
+		for i := range list {
+			list[i] = abiValueToJSON(*typ.Elem, value.Index(i))
+		}
+		return list
+	case abi.TupleTy:
+		fields := make(map[string]interface{}, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			fields[argName(typ.TupleRawNames[i], i)] = abiValueToJSON(*elem, value.Field(i))
+		}
+		return fields
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	registryABI = `[
+		{"type":"function","name":"fill","outputs":[],"inputs":[
+			{"name":"order","type":"tuple","components":[
+				{"name":"maker","type":"address"},
+				{"name":"amounts","type":"uint256[]"},
+				{"name":"salt","type":"bytes32"}
+			]},
+			{"name":"flags","type":"bool[2]"},
+			{"name":"data","type":"bytes"}
+		]},
+		{"type":"function","name":"sum","outputs":[],"inputs":[{"name":"values","type":"uint256[]"}]},
+		{"type":"function","name":"transfer","outputs":[],"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"setFee","outputs":[],"inputs":[{"name":"","type":"uint8"},{"name":"","type":"int16"}]}
+	]`
+	fillSig      = "fill((address,uint256[],bytes32),bool[2],bytes)"
+	fillArgsJSON = `{
+		"order": {
+			"maker": "0xD10a72Cf054650931365Cc44D912a4FD75257058",
+			"amounts": ["1000", "0x10"],
+			"salt": "0x0000000000000000000000000000000000000000000000000000000000000001"
+		},
+		"flags": [true, false],
+		"data": "0xdeadbeef"
+	}`
+)
+
+func testABIRegistry(t *testing.T) *configuration.ABIRegistry {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, tokenContractAddress+".json"), []byte(registryABI), 0o600))
+	registry, err := configuration.LoadABIRegistry(dir)
+	assert.NoError(t, err)
+	return registry
+}
+
+func fillArgs(t *testing.T) interface{} {
+	var args interface{}
+	assert.NoError(t, json.Unmarshal([]byte(fillArgsJSON), &args))
+	return args
+}
+
+func TestConstructContractCallData_ABIRegistry(t *testing.T) {
+	registry := testABIRegistry(t)
+
+	data, err := constructContractCallData(registry, tokenContractAddress, "sum(uint256[])", []interface{}{
+		[]interface{}{"1", 2.0},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0194db8e"+
+		"0000000000000000000000000000000000000000000000000000000000000020"+
+		"0000000000000000000000000000000000000000000000000000000000000002"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"0000000000000000000000000000000000000000000000000000000000000002", hexutil.Encode(data))
+
+	// Flat arguments are encoded as without the registry
+	flatArgs := []interface{}{"0xD10a72Cf054650931365Cc44D912a4FD75257058", "1000"}
+	withRegistry, err := constructContractCallData(registry, tokenContractAddress, "transfer(address,uint256)", flatArgs)
+	assert.NoError(t, err)
+	withoutRegistry, err := constructContractCallData(nil, tokenContractAddress, "transfer(address,uint256)", flatArgs)
+	assert.NoError(t, err)
+	assert.Equal(t, withoutRegistry, withRegistry)
+
+	// Nested arguments can be given by position or by name
+	byName, err := constructContractCallData(registry, tokenContractAddress, fillSig, fillArgs(t))
+	assert.NoError(t, err)
+	byPosition, err := constructContractCallData(registry, tokenContractAddress, fillSig, []interface{}{
+		[]interface{}{
+			"0xD10a72Cf054650931365Cc44D912a4FD75257058",
+			[]interface{}{"1000", "16"},
+			"0x0000000000000000000000000000000000000000000000000000000000000001",
+		},
+		[]interface{}{"true", false},
+		"0xdeadbeef",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, byName, byPosition)
+
+	// Pre-encoded arguments are appended as is
+	encoded, err := constructContractCallData(registry, tokenContractAddress, fillSig, hexutil.Encode(byName[4:]))
+	assert.NoError(t, err)
+	assert.Equal(t, byName, encoded)
+
+	// Unnamed arguments
+	data, err = constructContractCallData(registry, tokenContractAddress, "setFee(uint8,int16)", map[string]interface{}{
+		"arg0": "255",
+		"arg1": "-2",
+	})
+	assert.NoError(t, err)
+	method, ok := registry.Method(tokenContractAddress, data)
+	assert.True(t, ok)
+	args, err := decodeABIArgs(method, data)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"arg0": "255", "arg1": "-2"}, args)
+}
+
+func TestConstructContractCallData_ABIRegistryErrors(t *testing.T) {
+	registry := testABIRegistry(t)
+
+	tests := map[string]struct {
+		methodSig string
+		args      interface{}
+		err       string
+	}{
+		"wrong argument count": {
+			methodSig: "sum(uint256[])",
+			args:      []interface{}{},
+			err:       "expected 1 arguments but got 0: invalid method_args for sum(uint256[])",
+		},
+		"missing named argument": {
+			methodSig: "transfer(address,uint256)",
+			args:      map[string]interface{}{"to": "0xD10a72Cf054650931365Cc44D912a4FD75257058", "value": "1"},
+			err:       "missing argument amount: invalid method_args for transfer(address,uint256)",
+		},
+		"uint overflow": {
+			methodSig: "setFee(uint8,int16)",
+			args:      []interface{}{"256", "0"},
+			err:       "256 overflows uint8: invalid argument arg0 of setFee(uint8,int16)",
+		},
+		"negative uint": {
+			methodSig: "sum(uint256[])",
+			args:      []interface{}{[]interface{}{"-1"}},
+			err:       "-1 overflows uint256: at index 0: invalid argument values of sum(uint256[])",
+		},
+		"int underflow": {
+			methodSig: "setFee(uint8,int16)",
+			args:      []interface{}{"0", "-32769"},
+			err:       "-32769 overflows int16: invalid argument arg1 of setFee(uint8,int16)",
+		},
+		"invalid address in tuple": {
+			methodSig: fillSig,
+			args: []interface{}{
+				[]interface{}{"0x1234", []interface{}{}, "0x0000000000000000000000000000000000000000000000000000000000000001"},
+				[]interface{}{true, false},
+				"0x",
+			},
+			err: "0x1234 is not a valid address: in maker: invalid argument order of " + fillSig,
+		},
+		"fixed array length": {
+			methodSig: fillSig,
+			args: []interface{}{
+				[]interface{}{"0xD10a72Cf054650931365Cc44D912a4FD75257058", []interface{}{}, "0x0000000000000000000000000000000000000000000000000000000000000001"},
+				[]interface{}{true},
+				"0x",
+			},
+			err: "expected 2 elements for bool[2] but got 1: invalid argument flags of " + fillSig,
+		},
+		"fixed bytes length": {
+			methodSig: fillSig,
+			args: []interface{}{
+				[]interface{}{"0xD10a72Cf054650931365Cc44D912a4FD75257058", []interface{}{}, "0x01"},
+				[]interface{}{true, true},
+				"0x",
+			},
+			err: "received 1 bytes for bytes32: in salt: invalid argument order of " + fillSig,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := constructContractCallData(registry, tokenContractAddress, test.methodSig, test.args)
+			assert.Nil(t, data)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestPreprocess_ABIRegistry(t *testing.T) {
+	registry := testABIRegistry(t)
+	service := NewConstructionAPIService(&configuration.Configuration{ABIRegistry: registry}, &mocks.Client{})
+
+	resp, err := service.ConstructionPreprocess(context.Background(), &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        rosettaOperations(fromAddress, tokenContractAddress, big.NewInt(0), optimism.Currency, optimism.CallOpType),
+		Metadata: map[string]interface{}{
+			"method_signature": fillSig,
+			"method_args":      fillArgs(t),
+		},
+	})
+	assert.Nil(t, err)
+
+	expected, encErr := constructContractCallData(registry, tokenContractAddress, fillSig, fillArgs(t))
+	assert.NoError(t, encErr)
+	assert.Equal(t, hexutil.Encode(expected), resp.Options["data"])
+	assert.Equal(t, fillSig, resp.Options["method_signature"])
+}
+
+func TestConstructionParse_ABIRegistry(t *testing.T) {
+	registry := testABIRegistry(t)
+	service := NewConstructionAPIService(&configuration.Configuration{ABIRegistry: registry}, &mocks.Client{})
+
+	data, err := constructContractCallData(registry, tokenContractAddress, fillSig, fillArgs(t))
+	assert.NoError(t, err)
+	unsignedTx, err := json.Marshal(&transaction{
+		From:     fromAddress,
+		To:       tokenContractAddress,
+		Value:    big.NewInt(0),
+		Data:     data,
+		Nonce:    1,
+		GasPrice: big.NewInt(1000),
+		GasLimit: 100000,
+		ChainID:  big.NewInt(int64(chainID)),
+	})
+	assert.NoError(t, err)
+
+	resp, rErr := service.ConstructionParse(context.Background(), &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Transaction:       string(unsignedTx),
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, optimism.CallOpType, resp.Operations[1].Type)
+	assert.Nil(t, resp.Operations[0].Metadata)
+	assert.Equal(t, map[string]interface{}{
+		MethodSignatureKey: fillSig,
+		MethodArgsKey: map[string]interface{}{
+			"order": map[string]interface{}{
+				"maker":   "0xD10a72Cf054650931365Cc44D912a4FD75257058",
+				"amounts": []interface{}{"1000", "16"},
+				"salt":    "0x0000000000000000000000000000000000000000000000000000000000000001",
+			},
+			"flags": []interface{}{true, false},
+			"data":  "0xdeadbeef",
+		},
+	}, resp.Operations[1].Metadata)
+
+	// Calls to unknown methods are not decoded
+	unsignedTx, err = json.Marshal(&transaction{
+		From:     fromAddress,
+		To:       tokenContractAddress,
+		Value:    big.NewInt(0),
+		Data:     []byte{0x12, 0x34, 0x56, 0x78},
+		GasPrice: big.NewInt(1000),
+		ChainID:  big.NewInt(int64(chainID)),
+	})
+	assert.NoError(t, err)
+	resp, rErr = service.ConstructionParse(context.Background(), &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Transaction:       string(unsignedTx),
+	})
+	assert.Nil(t, rErr)
+	assert.Nil(t, resp.Operations[1].Metadata)
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// BytecodeKey is the key in the metadata of a CREATE operation
+	// holding the init code of the deployed contract
+	BytecodeKey = "bytecode"
+
+	// ConstructorSignatureKey is the key in the metadata of a CREATE operation
+	// holding the constructor signature, e.g. "constructor(address,uint256)"
+	ConstructorSignatureKey = "constructor_signature"
+
+	// ConstructorArgsKey is the key in the metadata of a CREATE operation
+	// holding the constructor arguments, encoded like method_args
+	ConstructorArgsKey = "constructor_args"
+)
+
+// isCreateIntent checks if the operations describe a contract deployment,
+// i.e. a single CREATE operation debiting the deployer of the endowment
+func isCreateIntent(operations []*types.Operation) bool {
+	return len(operations) == 1 && operations[0].Type == optimism.CreateOpType
+}
+
+// matchCreateOperation matches the operation of a contract deployment intent
+func matchCreateOperation(operations []*types.Operation) (*types.Operation, error) {
+	descriptions := &parser.Descriptions{
+		OperationDescriptions: []*parser.OperationDescription{
+			{
+				Type: optimism.CreateOpType,
+				Account: &parser.AccountDescription{
+					Exists: true,
+				},
+				Amount: &parser.AmountDescription{
+					Exists:   true,
+					Sign:     parser.NegativeOrZeroAmountSign,
+					Currency: optimism.Currency,
+				},
+			},
+		},
+		ErrUnmatched: true,
+	}
+
+	matches, err := parser.MatchOperations(descriptions, operations)
+	if err != nil {
+		return nil, err
+	}
+
+	createOp, _ := matches[0].First()
+	return createOp, nil
+}
+
+// constructCreationData constructs the data field of a contract deployment
+// from the metadata of its CREATE operation: the init code of the contract,
+// followed by the ABI encoded constructor arguments if a signature is given
+func constructCreationData(metadata map[string]interface{}) ([]byte, error) {
+	v, ok := metadata[BytecodeKey].(string)
+	if !ok {
+		return nil, errors.New("bytecode is not provided")
+	}
+	bytecode, err := hexutil.Decode(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode bytecode", err)
+	}
+	if len(bytecode) == 0 {
+		return nil, errors.New("bytecode is empty")
+	}
+
+	constructorArgs := metadata[ConstructorArgsKey]
+	constructorSig, ok := metadata[ConstructorSignatureKey].(string)
+	if !ok {
+		if constructorArgs != nil {
+			return nil, errors.New("constructor args are provided without a constructor signature")
+		}
+		return bytecode, nil
+	}
+
+	return encodeMethodArgs(bytecode, constructorSig, constructorArgs)
+}
+
+// preprocessCreate builds the options of a contract deployment intent
+func preprocessCreate(request *types.ConstructionPreprocessRequest) (*types.ConstructionPreprocessResponse, *types.Error) {
+	createOp, err := matchCreateOperation(request.Operations)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	checkFrom, ok := optimism.ChecksumAddress(createOp.Account.Address)
+	if !ok {
+		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", createOp.Account.Address))
+	}
+
+	data, err := constructCreationData(createOp.Metadata)
+	if err != nil {
+		return nil, wrapErr(ErrUnclearIntent, err)
+	}
+
+	value, ok := new(big.Int).SetString(createOp.Amount.Value, 10)
+	if !ok {
+		return nil, wrapErr(ErrUnclearIntent, fmt.Errorf("%s is not a valid amount", createOp.Amount.Value))
+	}
+	preprocessOutputOptions := &options{
+		From:   checkFrom,
+		Value:  value.Neg(value),
+		Data:   data,
+		Create: true,
+	}
+	if err := parseOptionOverrides(request.Metadata, preprocessOutputOptions); err != nil {
+		return nil, err
+	}
+	if preprocessOutputOptions.Cancel {
+		cancelOptions(preprocessOutputOptions)
+	}
+
+	marshaled, err := marshalJSONMap(preprocessOutputOptions)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPreprocessResponse{
+		Options: marshaled,
+	}, nil
+}
+
+// validateCreateRequest validates if the contract deployment in metadata
+// matches the CREATE operation of the intent
+//
+//nolint:gocritic
+func validateCreateRequest(createOp *types.Operation, metadata metadata) error {
+	if len(metadata.To) > 0 {
+		return errors.New("contract deployment must not have a destination address")
+	}
+
+	data, err := constructCreationData(createOp.Metadata)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, metadata.Data) {
+		return errors.New("invalid data value")
+	}
+
+	value := new(big.Int)
+	if metadata.Value != nil {
+		value.Neg(metadata.Value)
+	}
+	if value.String() != createOp.Amount.Value {
+		return errors.New("mismatch transfer value")
+	}
+
+	return nil
+}
+
+// parseCreate parses a contract deployment, reporting the address of the
+// deployed contract, which is derived from the sender and nonce, in metadata
+func parseCreate(tx *transaction, signed bool) (*types.ConstructionParseResponse, *types.Error) {
+	checkFrom, ok := optimism.ChecksumAddress(tx.From)
+	if !ok {
+		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", tx.From))
+	}
+
+	metadata := &parseMetadata{
+		Nonce:           tx.Nonce,
+		GasPrice:        tx.GasPrice,
+		GasTipCap:       tx.GasTipCap,
+		GasFeeCap:       tx.GasFeeCap,
+		GasLimit:        tx.GasLimit,
+		ChainID:         tx.ChainID,
+		ContractAddress: contractAddress(checkFrom, tx.Nonce),
+		TxType:          tx.TxType,
+		AccessList:      tx.AccessList,
+	}
+	metaMap, err := marshalJSONMap(metadata)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	signers := []*types.AccountIdentifier{}
+	if signed {
+		signers = append(signers, &types.AccountIdentifier{Address: checkFrom})
+	}
+
+	return &types.ConstructionParseResponse{
+		Operations:               createOperations(checkFrom, tx.Value, tx.Data),
+		AccountIdentifierSigners: signers,
+		Metadata:                 metaMap,
+	}, nil
+}
+
+// createOperations returns the CREATE operation of a parsed contract deployment.
+// Its bytecode is the whole init code, including any constructor arguments.
+func createOperations(fromAddress string, amount *big.Int, data []byte) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{
+				Index: 0,
+			},
+			Type: optimism.CreateOpType,
+			Account: &types.AccountIdentifier{
+				Address: fromAddress,
+			},
+			Amount: &types.Amount{
+				Value:    new(big.Int).Neg(amount).String(),
+				Currency: optimism.Currency,
+			},
+			Metadata: map[string]interface{}{
+				BytecodeKey: hexutil.Encode(data),
+			},
+		},
+	}
+}
+
+// contractAddress returns the address of the contract deployed by sender at nonce
+func contractAddress(sender string, nonce uint64) string {
+	return crypto.CreateAddress(common.HexToAddress(sender), nonce).Hex()
+}
@@ -0,0 +1,261 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	createBytecode = "0x6080604052348015600f57600080fd5b50603f80601d6000396000f3fe"
+	createArgs     = "00000000000000000000000057b414a0332b5cab885a451c2a28a07d1e9b8a8d" +
+		"0000000000000000000000000000000000000000000000000000000000000064"
+)
+
+func createOperation(from string, value string, metadata map[string]interface{}) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                optimism.CreateOpType,
+			Account:             &types.AccountIdentifier{Address: from},
+			Amount:              &types.Amount{Value: value, Currency: optimism.Currency},
+			Metadata:            metadata,
+		},
+	}
+}
+
+func TestConstructCreationData(t *testing.T) {
+	data, err := constructCreationData(map[string]interface{}{
+		BytecodeKey:             createBytecode,
+		ConstructorSignatureKey: "constructor(address,uint256)",
+		ConstructorArgsKey:      []interface{}{"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d", "100"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, createBytecode+createArgs, hexutil.Encode(data))
+
+	data, err = constructCreationData(map[string]interface{}{
+		BytecodeKey:             createBytecode,
+		ConstructorSignatureKey: "constructor(address,uint256)",
+		ConstructorArgsKey:      "0x" + createArgs,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, createBytecode+createArgs, hexutil.Encode(data))
+
+	data, err = constructCreationData(map[string]interface{}{BytecodeKey: createBytecode})
+	assert.NoError(t, err)
+	assert.Equal(t, createBytecode, hexutil.Encode(data))
+
+	_, err = constructCreationData(map[string]interface{}{})
+	assert.EqualError(t, err, "bytecode is not provided")
+
+	_, err = constructCreationData(map[string]interface{}{BytecodeKey: "0x"})
+	assert.EqualError(t, err, "bytecode is empty")
+
+	_, err = constructCreationData(map[string]interface{}{
+		BytecodeKey:        createBytecode,
+		ConstructorArgsKey: []interface{}{"100"},
+	})
+	assert.EqualError(t, err, "constructor args are provided without a constructor signature")
+}
+
+func TestConstructionCreate(t *testing.T) {
+	networkIdentifier = &types.NetworkIdentifier{
+		Network:    optimism.TestnetNetwork,
+		Blockchain: optimism.Blockchain,
+	}
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	nonce := uint64(5)
+	ops := createOperation(from.Hex(), "-1000", map[string]interface{}{
+		BytecodeKey:             createBytecode,
+		ConstructorSignatureKey: "constructor(address,uint256)",
+		ConstructorArgsKey:      []interface{}{"0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d", "100"},
+	})
+	data := hexutil.MustDecode(createBytecode + createArgs)
+
+	// Test Preprocess
+	preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, map[string]interface{}{
+		"from":   from.Hex(),
+		"to":     "",
+		"value":  "0x3e8",
+		"data":   hexutil.Encode(data),
+		"create": true,
+	}, preprocessResponse.Options)
+
+	// Test Metadata
+	mockClient.On("PendingNonceAt", ctx, from).Return(nonce, nil).Once()
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	mockClient.On("BaseFee", ctx).Return(nil, nil)
+	mockClient.On("EstimateGas", ctx, ethereum.CallMsg{
+		From:  from,
+		Data:  data,
+		Value: big.NewInt(1000),
+	}).Return(uint64(120000), nil).Once()
+	metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, &types.ConstructionMetadataResponse{
+		Metadata: map[string]interface{}{
+			"nonce":            "0x5",
+			"gas_price":        "0x3b9aca00",
+			"gas_limit":        "0x1d4c0",
+			"data":             hexutil.Encode(data),
+			"value":            "0x3e8",
+			"l2_execution_fee": hexutil.EncodeUint64(120000 * 1000000000),
+		},
+		SuggestedFee: []*types.Amount{
+			{
+				Value:    "120000000000000",
+				Currency: optimism.Currency,
+			},
+		},
+	}, metadataResponse)
+
+	// Test Payloads
+	payloadsResponse, rErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, rErr)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	// Test Parse Unsigned
+	parseResponse, rErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	expectedContract := crypto.CreateAddress(from, nonce).Hex()
+	parsedOps := createOperation(from.Hex(), "-1000", map[string]interface{}{
+		BytecodeKey: hexutil.Encode(data),
+	})
+	assert.Equal(t, parsedOps, parseResponse.Operations)
+	assert.Empty(t, parseResponse.AccountIdentifierSigners)
+	assert.Equal(t, expectedContract, parseResponse.Metadata["contract_address"])
+
+	// Test Combine
+	signature, err := crypto.Sign(payloadsResponse.Payloads[0].Bytes, key)
+	assert.NoError(t, err)
+	combineResponse, rErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				SigningPayload: payloadsResponse.Payloads[0],
+				SignatureType:  types.EcdsaRecovery,
+				Bytes:          signature,
+			},
+		},
+	})
+	assert.Nil(t, rErr)
+
+	// Test Parse Signed
+	parseResponse, rErr = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, parsedOps, parseResponse.Operations)
+	assert.Equal(t, []*types.AccountIdentifier{{Address: from.Hex()}}, parseResponse.AccountIdentifierSigners)
+	assert.Equal(t, expectedContract, parseResponse.Metadata["contract_address"])
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionPayloadsCreateMismatch(t *testing.T) {
+	servicer := NewConstructionAPIService(&configuration.Configuration{Params: params.TestnetChainConfig}, &mocks.Client{})
+	from := common.HexToAddress("0xe3a5B4d7f79d64088C8d4ef153A7DDe2B2d47309").Hex()
+	ops := createOperation(from, "0", map[string]interface{}{BytecodeKey: createBytecode})
+	metadata := func(to string, data string, value string) map[string]interface{} {
+		return map[string]interface{}{
+			"nonce":     "0x0",
+			"gas_price": "0x1",
+			"gas_limit": "0x1d4c0",
+			"to":        to,
+			"data":      data,
+			"value":     value,
+		}
+	}
+
+	tests := map[string]struct {
+		metadata map[string]interface{}
+		context  string
+	}{
+		"destination address": {
+			metadata: metadata("0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d", createBytecode, "0x0"),
+			context:  "contract deployment must not have a destination address",
+		},
+		"bytecode": {
+			metadata: metadata("", createBytecode+createArgs, "0x0"),
+			context:  "invalid data value",
+		},
+		"value": {
+			metadata: metadata("", createBytecode, "0x1"),
+			context:  "mismatch transfer value",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := servicer.ConstructionPayloads(context.Background(), &types.ConstructionPayloadsRequest{
+				Operations: ops,
+				Metadata:   test.metadata,
+			})
+			assert.Nil(t, resp)
+			assert.Equal(t, templateError(ErrBadRequest, test.context), err)
+		})
+	}
+
+	// CREATE operations cannot credit the deployer
+	resp, err := servicer.ConstructionPayloads(context.Background(), &types.ConstructionPayloadsRequest{
+		Operations: createOperation(from, "1", map[string]interface{}{BytecodeKey: createBytecode}),
+		Metadata:   metadata("", createBytecode, "0x0"),
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, ErrUnclearIntent.Code, err.Code)
+}
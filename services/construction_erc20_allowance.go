@@ -0,0 +1,182 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// SpenderKey is the key in the metadata of the debited operation of an
+	// ERC20_TRANSFER_FROM intent that represents the spender sending the
+	// transaction on behalf of the token owner
+	SpenderKey = "spender"
+)
+
+var (
+	erc20ApproveMethodID      = crypto.Keccak256([]byte("approve(address,uint256)"))[:4]
+	erc20TransferFromMethodID = crypto.Keccak256([]byte("transferFrom(address,address,uint256)"))[:4]
+	erc20AllowanceMethodID    = crypto.Keccak256([]byte("allowance(address,address)"))[:4]
+)
+
+// constructERC20ApproveData constructs the data field of an ERC20 approve
+// call, granting spender an allowance of value
+func constructERC20ApproveData(spender string, value *big.Int) []byte {
+	var data []byte
+	data = append(data, erc20ApproveMethodID...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(value.Bytes(), 32)...)
+	return data
+}
+
+// constructERC20TransferFromData constructs the data field of an ERC20
+// transferFrom call, moving value from the owner to the recipient
+func constructERC20TransferFromData(owner string, to string, value *big.Int) []byte {
+	var data []byte
+	data = append(data, erc20TransferFromMethodID...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(to).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(value.Bytes(), 32)...)
+	return data
+}
+
+// erc20ApproveArgs returns the arguments for an ERC20 approve,
+// including the spender address and the allowance
+func erc20ApproveArgs(data []byte) (common.Address, *big.Int, error) {
+	if len(data) != 4+32+32 {
+		return common.Address{}, nil, errors.New("invalid approve data")
+	}
+	if !dataHasFunc(data, erc20ApproveMethodID) {
+		return common.Address{}, nil, errors.New("invalid approve method id")
+	}
+	spender := common.BytesToAddress(data[4:36])
+	amount := new(big.Int).SetBytes(data[36:])
+	return spender, amount, nil
+}
+
+// erc20TransferFromArgs returns the arguments for an ERC20 transferFrom,
+// including the owner and destination addresses and value
+func erc20TransferFromArgs(data []byte) (common.Address, common.Address, *big.Int, error) {
+	if len(data) != 4+32+32+32 {
+		return common.Address{}, common.Address{}, nil, errors.New("invalid transferFrom data")
+	}
+	if !dataHasFunc(data, erc20TransferFromMethodID) {
+		return common.Address{}, common.Address{}, nil, errors.New("invalid transferFrom method id")
+	}
+	owner := common.BytesToAddress(data[4:36])
+	toAdd := common.BytesToAddress(data[36:68])
+	amount := new(big.Int).SetBytes(data[68:])
+	return owner, toAdd, amount, nil
+}
+
+// getSpender retrieves and validates the spender of an ERC20_TRANSFER_FROM
+// operation
+func getSpender(op *types.Operation) (string, error) {
+	v, exists := op.Metadata[SpenderKey]
+	if !exists {
+		return "", errors.New("missing spender address")
+	}
+
+	spender, ok := v.(string)
+	if !ok {
+		return "", errors.New("spender address is not a string")
+	}
+
+	checkSpender, ok := optimism.ChecksumAddress(spender)
+	if !ok {
+		return "", fmt.Errorf("%s is not a valid address", spender)
+	}
+
+	return checkSpender, nil
+}
+
+// calculateAllowance returns the amount of token that spender is allowed
+// to transfer on behalf of owner
+func (s *ConstructionAPIService) calculateAllowance(
+	ctx context.Context,
+	token string,
+	owner string,
+	spender string,
+) (*big.Int, error) {
+	var data []byte
+	data = append(data, erc20AllowanceMethodID...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32)...)
+
+	tokenAddress := common.HexToAddress(token)
+	result, err := s.client.CallContract(ctx, ethereum.CallMsg{
+		To:   &tokenAddress,
+		Data: data,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 32 { //nolint:gomnd
+		return nil, fmt.Errorf("invalid allowance result %x", result)
+	}
+
+	return new(big.Int).SetBytes(result), nil
+}
+
+// allowanceCurrency returns the currency of the token called by an ERC20
+// allowance transaction. Unsigned transactions carry the currency of their
+// intent, while the currency of signed ones is looked up from the token.
+func (s *ConstructionAPIService) allowanceCurrency(
+	ctx context.Context,
+	tx *transaction,
+) (*types.Currency, *types.Error) {
+	if tx.Currency != nil {
+		tokenAddress, err := getTokenContractAddress(tx.Currency)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidTokenContractAddress, err)
+		}
+		if !strings.EqualFold(tokenAddress, tx.To) {
+			return nil, wrapErr(
+				ErrUnableToParseTransaction,
+				fmt.Errorf("currency of token %s does not match the called contract %s", tokenAddress, tx.To),
+			)
+		}
+		return tx.Currency, nil
+	}
+
+	if s.config.Mode != configuration.Online {
+		return nil, wrapErr(
+			ErrUnavailableOffline,
+			fmt.Errorf("the currency of token %s requires geth", tx.To),
+		)
+	}
+	checkTo, ok := optimism.ChecksumAddress(tx.To)
+	if !ok {
+		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", tx.To))
+	}
+	currency, err := s.client.TokenCurrency(ctx, checkTo)
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	return currency, nil
+}
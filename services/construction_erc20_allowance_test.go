@@ -0,0 +1,286 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var tokenCurrency = &types.Currency{
+	Symbol:   "USDC",
+	Decimals: 6,
+	Metadata: map[string]interface{}{
+		TokenContractAddressKey: tokenContractAddress,
+	},
+}
+
+func allowanceOperations(opType string, from string, to string, value int64, spender string) []*types.Operation {
+	ops := rosettaOperations(from, to, big.NewInt(value), tokenCurrency, opType)
+	if len(spender) > 0 {
+		ops[0].Metadata = map[string]interface{}{SpenderKey: spender}
+	}
+	return ops
+}
+
+func allowanceCall(owner string, spender string) ethereum.CallMsg {
+	token := common.HexToAddress(tokenContractAddress)
+	var data []byte
+	data = append(data, erc20AllowanceMethodID...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32)...)
+	return ethereum.CallMsg{To: &token, Data: data}
+}
+
+func TestERC20AllowanceArgs(t *testing.T) {
+	spender, amount, err := erc20ApproveArgs(constructERC20ApproveData(toAddress, big.NewInt(100)))
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(toAddress), spender)
+	assert.Equal(t, big.NewInt(100), amount)
+
+	owner, to, amount, err := erc20TransferFromArgs(
+		constructERC20TransferFromData(fromAddress, toAddress, big.NewInt(100)),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress(fromAddress), owner)
+	assert.Equal(t, common.HexToAddress(toAddress), to)
+	assert.Equal(t, big.NewInt(100), amount)
+
+	_, _, err = erc20ApproveArgs(constructERC20TransferData(toAddress, big.NewInt(100)))
+	assert.EqualError(t, err, "invalid approve method id")
+
+	_, _, _, err = erc20TransferFromArgs(constructERC20ApproveData(toAddress, big.NewInt(100)))
+	assert.EqualError(t, err, "invalid transferFrom data")
+}
+
+func TestMatchAllowanceOperations(t *testing.T) {
+	_, _, err := matchOperations(allowanceOperations(optimism.ERC20ApproveOpType, fromAddress, toAddress, 100, ""))
+	assert.NoError(t, err)
+
+	_, _, err = matchOperations(rosettaOperations(
+		fromAddress, toAddress, big.NewInt(100), optimism.Currency, optimism.ERC20ApproveOpType,
+	))
+	assert.EqualError(t, err, "ERC20_APPROVE requires a token currency")
+
+	_, _, err = matchOperations(allowanceOperations(optimism.ERC20TransferFromOpType, fromAddress, toAddress, 100, ""))
+	assert.Error(t, err)
+
+	_, _, err = matchOperations(allowanceOperations(
+		optimism.ERC20TransferFromOpType, fromAddress, toAddress, 100, tokenContractAddress,
+	))
+	assert.NoError(t, err)
+}
+
+func TestConstructionERC20Approve(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	ops := allowanceOperations(optimism.ERC20ApproveOpType, owner.Hex(), toAddress, 100, "")
+	data := constructERC20ApproveData(toAddress, big.NewInt(100))
+
+	// Test Preprocess
+	preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, forceMarshalMap(t, &options{
+		From:         owner.Hex(),
+		To:           toAddress,
+		TokenAddress: tokenContractAddress,
+		Data:         data,
+		Value:        big.NewInt(0),
+		Owner:        owner.Hex(),
+		Spender:      toAddress,
+	}), preprocessResponse.Options)
+
+	// Test Metadata
+	token := common.HexToAddress(tokenContractAddress)
+	mockClient.On("PendingNonceAt", ctx, owner).Return(uint64(0), nil).Once()
+	mockClient.On("CallContract", ctx, allowanceCall(owner.Hex(), toAddress), (*big.Int)(nil)).
+		Return(common.LeftPadBytes(big.NewInt(20).Bytes(), 32), nil).Once()
+	mockClient.On("EstimateGas", ctx, ethereum.CallMsg{From: owner, To: &token, Data: data}).
+		Return(transferGasLimitERC20, nil).Once()
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	mockClient.On("BaseFee", ctx).Return(nil, nil).Once()
+	metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, "0x14", metadataResponse.Metadata["allowance"])
+
+	// Test Payloads
+	payloadsResponse, rErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, owner.Hex(), payloadsResponse.Payloads[0].AccountIdentifier.Address)
+
+	// Test Parse Unsigned
+	parseResponse, rErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, ops, parseResponse.Operations)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionERC20TransferFrom(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	spender := crypto.PubkeyToAddress(key.PublicKey)
+	ops := allowanceOperations(optimism.ERC20TransferFromOpType, fromAddress, toAddress, 100, spender.Hex())
+	data := constructERC20TransferFromData(fromAddress, toAddress, big.NewInt(100))
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+
+	// Test Preprocess
+	preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, forceMarshalMap(t, &options{
+		From:         spender.Hex(),
+		To:           toAddress,
+		TokenAddress: tokenContractAddress,
+		Data:         data,
+		Value:        big.NewInt(0),
+		Owner:        fromAddress,
+		Spender:      spender.Hex(),
+	}), preprocessResponse.Options)
+
+	// Test Metadata with an insufficient allowance
+	mockClient.On("PendingNonceAt", ctx, spender).Return(uint64(0), nil).Twice()
+	mockClient.On("CallContract", ctx, allowanceCall(fromAddress, spender.Hex()), (*big.Int)(nil)).
+		Return(common.LeftPadBytes(big.NewInt(99).Bytes(), 32), nil).Once()
+	metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, metadataResponse)
+	assert.Equal(t, templateError(ErrInsufficientAllowance, "allowance 99 is less than transfer value 100"), rErr)
+
+	// Test Metadata
+	token := common.HexToAddress(tokenContractAddress)
+	mockClient.On("CallContract", ctx, allowanceCall(fromAddress, spender.Hex()), (*big.Int)(nil)).
+		Return(common.LeftPadBytes(big.NewInt(100).Bytes(), 32), nil).Once()
+	mockClient.On("EstimateGas", ctx, ethereum.CallMsg{From: spender, To: &token, Data: data}).
+		Return(transferGasLimitERC20, nil).Once()
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	mockClient.On("BaseFee", ctx).Return(nil, nil).Once()
+	metadataResponse, rErr = servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, "0x64", metadataResponse.Metadata["allowance"])
+
+	// Test Payloads
+	payloadsResponse, rErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, spender.Hex(), payloadsResponse.Payloads[0].AccountIdentifier.Address)
+
+	// Test Combine
+	signature, err := crypto.Sign(payloadsResponse.Payloads[0].Bytes, key)
+	assert.NoError(t, err)
+	combineResponse, rErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				SigningPayload: payloadsResponse.Payloads[0],
+				SignatureType:  types.EcdsaRecovery,
+				Bytes:          signature,
+			},
+		},
+	})
+	assert.Nil(t, rErr)
+
+	// Test Parse Signed, which looks up the currency of the token
+	mockClient.On("TokenCurrency", ctx, tokenContractAddress).Return(tokenCurrency, nil).Once()
+	parseResponse, rErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, ops, parseResponse.Operations)
+	assert.Equal(t, []*types.AccountIdentifier{{Address: spender.Hex()}}, parseResponse.AccountIdentifierSigners)
+
+	// Test Parse Signed offline, where the currency of the token is unknown
+	offlineServicer := NewConstructionAPIService(&configuration.Configuration{
+		Mode:    configuration.Offline,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}, nil)
+	parseResponse, rErr = offlineServicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, parseResponse)
+	assert.Equal(t, templateError(ErrUnavailableOffline, "the currency of token "+tokenContractAddress+" requires geth"), rErr)
+
+	// Test Parse Unsigned offline, which reports the currency of the intent
+	parseResponse, rErr = offlineServicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, ops, parseResponse.Operations)
+
+	mockClient.AssertExpectations(t)
+}
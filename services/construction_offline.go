@@ -0,0 +1,202 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// parseChainIDOverride sets the chain_id of opts that is provided in the
+// metadata of a /construction/preprocess request
+func parseChainIDOverride(metadata map[string]interface{}, opts *options) *types.Error {
+	v, ok := metadata["chain_id"]
+	if !ok {
+		return nil
+	}
+	stringObj, ok := v.(string)
+	if !ok {
+		return wrapErr(ErrInvalidChainID, fmt.Errorf("%v is not a valid chain_id string", v))
+	}
+	chainID, ok := new(big.Int).SetString(stringObj, 10) //nolint:gomnd
+	if !ok || chainID.Sign() <= 0 {
+		return wrapErr(ErrInvalidChainID, fmt.Errorf("%s is not a valid chain_id", stringObj))
+	}
+	opts.ChainID = chainID
+	return nil
+}
+
+// validateChainID checks that a chain ID supplied by the caller is the one
+// of the configured network, so that a transaction is never signed for
+// another chain
+func (s *ConstructionAPIService) validateChainID(chainID *big.Int) *types.Error {
+	if chainID == nil || s.config.Params == nil || s.config.Params.ChainID == nil {
+		return nil
+	}
+	if chainID.Cmp(s.config.Params.ChainID) != 0 {
+		return wrapErr(
+			ErrInvalidChainID,
+			fmt.Errorf("chain_id %s does not match the network chain ID %s", chainID, s.config.Params.ChainID),
+		)
+	}
+	return nil
+}
+
+// offlineMetadata implements /construction/metadata without access to geth.
+// The nonce, chain ID and fees must be supplied in the preprocess metadata,
+// as must the gas limit of anything but a plain ETH transfer, whose
+// intrinsic gas is calculated locally.
+func (s *ConstructionAPIService) offlineMetadata(
+	input *options,
+	checkTo string,
+) (*types.ConstructionMetadataResponse, *types.Error) {
+	switch {
+	case len(input.ReplaceTxHash) > 0:
+		return nil, wrapErr(ErrUnavailableOffline, errors.New("replace_tx_hash requires the pending transaction"))
+	case input.Simulate:
+		return nil, wrapErr(ErrUnavailableOffline, errors.New("simulate requires geth"))
+	case input.CreateAccessList:
+		return nil, wrapErr(ErrUnavailableOffline, errors.New("create_access_list requires geth"))
+	}
+
+	if input.Nonce == nil {
+		return nil, wrapErr(ErrMissingOfflineMetadata, errors.New("nonce is not provided"))
+	}
+	if !input.Nonce.IsUint64() {
+		return nil, wrapErr(ErrInvalidNonce, fmt.Errorf("%s is not a valid nonce", input.Nonce))
+	}
+	if input.ChainID == nil {
+		return nil, wrapErr(ErrMissingOfflineMetadata, errors.New("chain_id is not provided"))
+	}
+
+	to := checkTo
+	for _, contract := range []string{input.TokenAddress, input.ContractAddress} {
+		if len(contract) == 0 {
+			continue
+		}
+		checkContract, ok := optimism.ChecksumAddress(contract)
+		if !ok {
+			return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", contract))
+		}
+		// Override the destination address to be the contract address
+		to = checkContract
+	}
+
+	intrinsicGas, err := core.IntrinsicGas(input.Data, input.AccessList, input.Create, true, true)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidGasLimit, err)
+	}
+
+	// Anything but a plain transfer executes code whose gas cannot be estimated offline
+	gasLimit := intrinsicGas
+	if input.GasLimit != nil {
+		if !input.GasLimit.IsUint64() || input.GasLimit.Uint64() < intrinsicGas {
+			return nil, wrapErr(
+				ErrInvalidGasLimit,
+				fmt.Errorf("gas_limit %s is below the intrinsic gas %d", input.GasLimit, intrinsicGas),
+			)
+		}
+		gasLimit = input.GasLimit.Uint64()
+	} else if input.Create || len(input.Data) > 0 {
+		return nil, wrapErr(
+			ErrMissingOfflineMetadata,
+			errors.New("gas_limit is not provided for a transaction that is not a plain transfer"),
+		)
+	}
+
+	gasPrice, gasTipCap, gasFeeCap, fErr := offlineFees(input)
+	if fErr != nil {
+		return nil, fErr
+	}
+
+	l2ExecutionFee := new(big.Int).SetUint64(gasLimit)
+	if gasFeeCap != nil {
+		l2ExecutionFee.Mul(l2ExecutionFee, gasFeeCap)
+	} else {
+		l2ExecutionFee.Mul(l2ExecutionFee, gasPrice)
+	}
+
+	metadata := &metadata{
+		Nonce:           input.Nonce.Uint64(),
+		GasPrice:        gasPrice,
+		GasTipCap:       gasTipCap,
+		GasFeeCap:       gasFeeCap,
+		GasLimit:        gasLimit,
+		Data:            input.Data,
+		Value:           input.Value,
+		To:              to,
+		MethodSignature: input.MethodSignature,
+		MethodArgs:      input.MethodArgs,
+		L2ExecutionFee:  l2ExecutionFee,
+		TxType:          input.TxType,
+		AccessList:      input.AccessList,
+		ChainID:         input.ChainID,
+	}
+
+	metadataMap, mErr := marshalJSONMap(metadata)
+	if mErr != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, mErr)
+	}
+
+	// The L1 data fee depends on the L1 base fee, so only the L2 execution
+	// fee is suggested offline
+	return &types.ConstructionMetadataResponse{
+		Metadata: metadataMap,
+		SuggestedFee: []*types.Amount{
+			optimism.Amount(new(big.Int).Set(l2ExecutionFee), optimism.Currency),
+		},
+	}, nil
+}
+
+// offlineFees returns the gas price and fee caps supplied for a transaction
+// constructed offline. The gas price of an EIP-1559 transaction defaults to
+// its fee cap, since it is always provided in the metadata.
+func offlineFees(input *options) (*big.Int, *big.Int, *big.Int, *types.Error) {
+	switch {
+	case input.TxType == LegacyTxType || input.TxType == AccessListTxType:
+		if input.GasPrice == nil {
+			return nil, nil, nil, wrapErr(
+				ErrMissingOfflineMetadata,
+				fmt.Errorf("gas_price is not provided for a %s transaction", input.TxType),
+			)
+		}
+		return input.GasPrice, nil, nil, nil
+	case input.GasTipCap != nil && input.GasFeeCap != nil:
+		if input.GasFeeCap.Cmp(input.GasTipCap) < 0 {
+			return nil, nil, nil, wrapErr(
+				ErrInvalidGasFeeCap,
+				fmt.Errorf("gas_fee_cap %s is less than gas_tip_cap %s", input.GasFeeCap, input.GasTipCap),
+			)
+		}
+		gasPrice := input.GasPrice
+		if gasPrice == nil {
+			gasPrice = input.GasFeeCap
+		}
+		return gasPrice, input.GasTipCap, input.GasFeeCap, nil
+	case input.TxType == "" && input.GasPrice != nil && input.GasTipCap == nil && input.GasFeeCap == nil:
+		return input.GasPrice, nil, nil, nil
+	default:
+		return nil, nil, nil, wrapErr(
+			ErrMissingOfflineMetadata,
+			errors.New("gas_tip_cap and gas_fee_cap, or gas_price, are not provided"),
+		)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstructionOffline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Offline,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	// The mock has no expectations, so any call to geth fails the test
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+	chainID := params.TestnetChainConfig.ChainID.String()
+
+	t.Run("transfer with intrinsic gas", func(t *testing.T) {
+		preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        transferOperations(fromAddress, toAddress, "1000"),
+			Metadata: map[string]interface{}{
+				"nonce":       "7",
+				"chain_id":    chainID,
+				"gas_tip_cap": "10",
+				"gas_fee_cap": "300",
+			},
+		})
+		assert.Nil(t, rErr)
+		assert.Equal(t, hexutil.EncodeBig(params.TestnetChainConfig.ChainID), preprocessResponse.Options["chain_id"])
+
+		metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           preprocessResponse.Options,
+		})
+		assert.Nil(t, rErr)
+		assert.Equal(t, "0x7", metadataResponse.Metadata["nonce"])
+		assert.Equal(t, "0x5208", metadataResponse.Metadata["gas_limit"])
+		assert.Equal(t, "0xa", metadataResponse.Metadata["gas_tip_cap"])
+		assert.Equal(t, "0x12c", metadataResponse.Metadata["gas_fee_cap"])
+		assert.Equal(t, "0x12c", metadataResponse.Metadata["gas_price"])
+		assert.NotContains(t, metadataResponse.Metadata, "l1_data_fee")
+		assert.Equal(t, []*types.Amount{{Value: "6300000", Currency: optimism.Currency}}, metadataResponse.SuggestedFee)
+
+		payloadsResponse, rErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        transferOperations(fromAddress, toAddress, "1000"),
+			Metadata:          metadataResponse.Metadata,
+		})
+		assert.Nil(t, rErr)
+		assert.Len(t, payloadsResponse.Payloads, 1)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("access list transfer", func(t *testing.T) {
+		metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options: map[string]interface{}{
+				"from":        fromAddress,
+				"to":          toAddress,
+				"value":       "0x3e8",
+				"nonce":       "0x7",
+				"chain_id":    hexutil.EncodeBig(params.TestnetChainConfig.ChainID),
+				"gas_price":   "0x64",
+				"tx_type":     AccessListTxType,
+				"access_list": accessListRaw,
+			},
+		})
+		assert.Nil(t, rErr)
+		// The intrinsic gas includes the access list
+		assert.Equal(t, "0x62d4", metadataResponse.Metadata["gas_limit"])
+		assert.NotContains(t, metadataResponse.Metadata, "gas_fee_cap")
+		assert.Equal(t, "2530000", metadataResponse.SuggestedFee[0].Value)
+	})
+}
+
+func TestMetadata_OfflineRefused(t *testing.T) {
+	servicer := NewConstructionAPIService(&configuration.Configuration{
+		Mode:   configuration.Offline,
+		Params: params.TestnetChainConfig,
+	}, &mocks.Client{})
+	chainIDHex := hexutil.EncodeBig(params.TestnetChainConfig.ChainID)
+
+	transfer := func(overrides map[string]interface{}) map[string]interface{} {
+		options := map[string]interface{}{
+			"from":      fromAddress,
+			"to":        toAddress,
+			"value":     "0x3e8",
+			"nonce":     "0x7",
+			"chain_id":  chainIDHex,
+			"gas_price": "0x64",
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(options, k)
+			} else {
+				options[k] = v
+			}
+		}
+		return options
+	}
+
+	tests := map[string]struct {
+		options map[string]interface{}
+		err     *types.Error
+	}{
+		"missing nonce": {
+			options: transfer(map[string]interface{}{"nonce": nil}),
+			err:     ErrMissingOfflineMetadata,
+		},
+		"missing chain_id": {
+			options: transfer(map[string]interface{}{"chain_id": nil}),
+			err:     ErrMissingOfflineMetadata,
+		},
+		"chain_id of another network": {
+			options: transfer(map[string]interface{}{"chain_id": "0xa"}),
+			err:     ErrInvalidChainID,
+		},
+		"missing fees": {
+			options: transfer(map[string]interface{}{"gas_price": nil}),
+			err:     ErrMissingOfflineMetadata,
+		},
+		"legacy without gas_price": {
+			options: transfer(map[string]interface{}{
+				"gas_price": nil, "tx_type": LegacyTxType,
+			}),
+			err: ErrMissingOfflineMetadata,
+		},
+		"fee cap below tip cap": {
+			options: transfer(map[string]interface{}{
+				"gas_price": nil, "gas_tip_cap": "0x2", "gas_fee_cap": "0x1",
+			}),
+			err: ErrInvalidGasFeeCap,
+		},
+		"contract call without gas_limit": {
+			options: transfer(map[string]interface{}{
+				"to": tokenContractAddress, "token_address": tokenContractAddress, "data": transferData,
+			}),
+			err: ErrMissingOfflineMetadata,
+		},
+		"gas_limit below intrinsic gas": {
+			options: transfer(map[string]interface{}{"gas_limit": "0x5000"}),
+			err:     ErrInvalidGasLimit,
+		},
+		"replacement": {
+			options: transfer(map[string]interface{}{
+				"replace_tx_hash": "0x6c1ea7ac63c7e7db2a4c67bcc4dde3af1aa0d7e6bef7c0f7dcb4c1ca5dd9e1d1",
+			}),
+			err: ErrUnavailableOffline,
+		},
+		"simulation": {
+			options: transfer(map[string]interface{}{"simulate": true}),
+			err:     ErrUnavailableOffline,
+		},
+		"generated access list": {
+			options: transfer(map[string]interface{}{
+				"tx_type": AccessListTxType, "create_access_list": true,
+			}),
+			err: ErrUnavailableOffline,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := servicer.ConstructionMetadata(context.Background(), &types.ConstructionMetadataRequest{
+				NetworkIdentifier: networkIdentifier,
+				Options:           test.options,
+			})
+			assert.Nil(t, resp)
+			assert.Equal(t, test.err.Code, err.Code)
+		})
+	}
+}
+
+func TestPreprocessChainIDOverride(t *testing.T) {
+	servicer := NewConstructionAPIService(&configuration.Configuration{}, &mocks.Client{})
+
+	for _, chainID := range []interface{}{"0x1", "-1", "0", 10} {
+		resp, err := servicer.ConstructionPreprocess(context.Background(), &types.ConstructionPreprocessRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        transferOperations(fromAddress, toAddress, "1000"),
+			Metadata:          map[string]interface{}{"chain_id": chainID},
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, ErrInvalidChainID.Code, err.Code)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// replacementPriceBump is the minimum increase, in percent, of the fees of a
+// transaction replacing a pending one. It matches the default price bump of
+// the geth transaction pool.
+const replacementPriceBump = 10
+
+// parseReplacementOverrides sets the pending transaction that is replaced by
+// the transaction being constructed, as provided in the metadata of a
+// /construction/preprocess request
+func parseReplacementOverrides(metadata map[string]interface{}, opts *options) *types.Error {
+	if v, ok := metadata["replace_tx_hash"]; ok {
+		hash, ok := v.(string)
+		if !ok || len(hash) != 2+2*common.HashLength || !strings.HasPrefix(hash, "0x") {
+			return wrapErr(ErrInvalidReplacement, fmt.Errorf("%v is not a valid replace_tx_hash", v))
+		}
+		opts.ReplaceTxHash = hash
+	}
+
+	if v, ok := metadata["cancel"]; ok {
+		cancel, ok := v.(bool)
+		if !ok {
+			return wrapErr(ErrInvalidReplacement, fmt.Errorf("%v is not a valid cancel bool", v))
+		}
+		opts.Cancel = cancel
+	}
+
+	if len(opts.ReplaceTxHash) == 0 {
+		if opts.Cancel {
+			return wrapErr(ErrInvalidReplacement, errors.New("cancel requires a replace_tx_hash"))
+		}
+		return nil
+	}
+	// The nonce of the replaced transaction is reused
+	if opts.Nonce != nil {
+		return wrapErr(ErrInvalidReplacement, errors.New("nonce cannot be provided along with a replace_tx_hash"))
+	}
+
+	return nil
+}
+
+// cancelOptions turns opts into a 0-value self-transfer, which
+// cancels the pending transaction it replaces
+func cancelOptions(opts *options) {
+	opts.To = opts.From
+	opts.Value = big.NewInt(0)
+	opts.Data = nil
+	opts.TokenAddress = ""
+	opts.ContractAddress = ""
+	opts.MethodSignature = ""
+	opts.MethodArgs = nil
+	opts.Create = false
+	opts.Owner = ""
+	opts.Spender = ""
+}
+
+// pendingReplacement returns the pending transaction replaced by the transaction
+// of from described by opts, or nil if opts do not replace a transaction
+func (s *ConstructionAPIService) pendingReplacement(
+	ctx context.Context,
+	opts *options,
+	from string,
+) (*ethTypes.Transaction, *types.Error) {
+	if len(opts.ReplaceTxHash) == 0 {
+		return nil, nil
+	}
+
+	replaced, sender, err := s.client.PendingTransaction(ctx, opts.ReplaceTxHash)
+	if errors.Is(err, optimism.ErrTransactionNotFound) {
+		return nil, wrapErr(ErrInvalidReplacement, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+	if sender != common.HexToAddress(from) {
+		return nil, wrapErr(
+			ErrInvalidReplacement,
+			fmt.Errorf("%s was sent by %s instead of %s", opts.ReplaceTxHash, sender.Hex(), from),
+		)
+	}
+
+	return replaced, nil
+}
+
+// validateSpeedUp checks that a transaction speeding up the replaced
+// transaction repeats its intent
+//
+//nolint:goerr113
+func validateSpeedUp(replaced *ethTypes.Transaction, to string, data []byte, value *big.Int) error {
+	var replacedTo string
+	if replaced.To() != nil {
+		replacedTo = replaced.To().Hex()
+	}
+	if !strings.EqualFold(replacedTo, to) {
+		return fmt.Errorf("destination address %s does not match %s of the replaced transaction", to, replacedTo)
+	}
+	if !bytes.Equal(replaced.Data(), data) {
+		return errors.New("data does not match the replaced transaction")
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	if replaced.Value().Cmp(value) != 0 {
+		return fmt.Errorf("value %s does not match %s of the replaced transaction", value, replaced.Value())
+	}
+	return nil
+}
+
+// replacementFee returns the larger of fee and the minimum
+// replacement fee for a transaction paying replacedFee
+func replacementFee(fee *big.Int, replacedFee *big.Int) *big.Int {
+	minFee := new(big.Int).Mul(replacedFee, big.NewInt(100+replacementPriceBump))
+	minFee.Add(minFee, big.NewInt(99)) //nolint:gomnd
+	minFee.Div(minFee, big.NewInt(100))
+	if fee == nil {
+		return minFee
+	}
+	return bigIntMax(fee, minFee)
+}
+
+// validateCancel checks that the metadata of a cancellation is
+// a 0-value self-transfer of from
+//
+//nolint:gocritic
+func validateCancel(from string, metadata metadata) error {
+	if !strings.EqualFold(metadata.To, from) {
+		return errors.New("cancellation must be a self-transfer")
+	}
+	if hasData(metadata.Data) {
+		return errors.New("cancellation must not have data")
+	}
+	if metadata.Value != nil && metadata.Value.Sign() != 0 {
+		return errors.New("cancellation must not transfer value")
+	}
+	return nil
+}
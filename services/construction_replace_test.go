@@ -0,0 +1,264 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var replacedTxHash = "0x4d7c6f3b0bb4c7bd1b2fb12fd0a3e4e4a0b1e8c1b6e27df1c1d5e3c8a7f6b5e4"
+
+func replacedTransaction(to string, value int64) *ethTypes.Transaction {
+	toAddress := common.HexToAddress(to)
+	return ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(chainID)),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1000),
+		GasFeeCap: big.NewInt(2000),
+		Gas:       30000,
+		To:        &toAddress,
+		Value:     big.NewInt(value),
+	})
+}
+
+func TestReplacementFee(t *testing.T) {
+	assert.Equal(t, big.NewInt(110), replacementFee(nil, big.NewInt(100)))
+	assert.Equal(t, big.NewInt(112), replacementFee(big.NewInt(1), big.NewInt(101)))
+	assert.Equal(t, big.NewInt(500), replacementFee(big.NewInt(500), big.NewInt(100)))
+}
+
+func TestPreprocessReplacement(t *testing.T) {
+	servicer := NewConstructionAPIService(&configuration.Configuration{}, &mocks.Client{})
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		metadata map[string]interface{}
+		context  string
+	}{
+		"invalid hash": {
+			metadata: map[string]interface{}{"replace_tx_hash": "0x1234"},
+			context:  "0x1234 is not a valid replace_tx_hash",
+		},
+		"invalid cancel": {
+			metadata: map[string]interface{}{"replace_tx_hash": replacedTxHash, "cancel": "true"},
+			context:  "true is not a valid cancel bool",
+		},
+		"cancel without hash": {
+			metadata: map[string]interface{}{"cancel": true},
+			context:  "cancel requires a replace_tx_hash",
+		},
+		"nonce with hash": {
+			metadata: map[string]interface{}{"replace_tx_hash": replacedTxHash, "nonce": "7"},
+			context:  "nonce cannot be provided along with a replace_tx_hash",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+				NetworkIdentifier: networkIdentifier,
+				Operations:        templateOperations(1000, optimism.Currency, false),
+				Metadata:          test.metadata,
+			})
+			assert.Nil(t, resp)
+			assert.Equal(t, templateError(ErrInvalidReplacement, test.context), err)
+		})
+	}
+
+	// A cancellation discards the token transfer of the intent
+	resp, err := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        templateOperations(1000, tokenCurrency, true),
+		Metadata:          map[string]interface{}{"replace_tx_hash": replacedTxHash, "cancel": true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, forceMarshalMap(t, &options{
+		From:          fromAddress,
+		To:            fromAddress,
+		Value:         big.NewInt(0),
+		ReplaceTxHash: replacedTxHash,
+		Cancel:        true,
+	}), resp.Options)
+}
+
+func TestConstructionSpeedUp(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	ctx := context.Background()
+	from := common.HexToAddress(fromAddress)
+	ops := templateOperations(1000, optimism.Currency, false)
+
+	servicer := NewConstructionAPIService(cfg, &mocks.Client{})
+	preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          map[string]interface{}{"replace_tx_hash": replacedTxHash},
+	})
+	assert.Nil(t, rErr)
+
+	t.Run("bumped fees", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingTransaction", ctx, replacedTxHash).
+			Return(replacedTransaction(toAddress, 1000), from, nil).Once()
+		mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+		mockClient.On("BaseFee", ctx).Return(big.NewInt(200), nil).Once()
+		mockClient.On("SuggestGasTipCap", ctx).Return(big.NewInt(10), nil).Once()
+
+		metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           preprocessResponse.Options,
+		})
+		assert.Nil(t, err)
+		// The nonce and gas limit of the replaced transaction are kept
+		assert.Equal(t, "0x7", metadataResponse.Metadata["nonce"])
+		assert.Equal(t, "0x7530", metadataResponse.Metadata["gas_limit"])
+		assert.Equal(t, "0x44c", metadataResponse.Metadata["gas_tip_cap"])
+		assert.Equal(t, "0x898", metadataResponse.Metadata["gas_fee_cap"])
+		mockClient.AssertExpectations(t)
+
+		payloadsResponse, err := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+			NetworkIdentifier: networkIdentifier,
+			Operations:        ops,
+			Metadata:          metadataResponse.Metadata,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, payloadsResponse.Payloads, 1)
+	})
+
+	t.Run("different intent", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingTransaction", ctx, replacedTxHash).
+			Return(replacedTransaction(toAddress, 999), from, nil).Once()
+		mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+		mockClient.On("BaseFee", ctx).Return(nil, nil).Once()
+
+		metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           preprocessResponse.Options,
+		})
+		assert.Nil(t, metadataResponse)
+		assert.Equal(t, templateError(ErrInvalidReplacement, "value 1000 does not match 999 of the replaced transaction"), err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("different sender", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingTransaction", ctx, replacedTxHash).
+			Return(replacedTransaction(toAddress, 1000), common.HexToAddress(toAddress), nil).Once()
+
+		metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           preprocessResponse.Options,
+		})
+		assert.Nil(t, metadataResponse)
+		assert.Equal(t, ErrInvalidReplacement.Code, err.Code)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("not pending", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingTransaction", ctx, replacedTxHash).
+			Return(nil, common.Address{}, optimism.ErrTransactionNotFound).Once()
+
+		metadataResponse, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           preprocessResponse.Options,
+		})
+		assert.Nil(t, metadataResponse)
+		assert.Equal(t, ErrInvalidReplacement.Code, err.Code)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestConstructionCancel(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+	from := common.HexToAddress(fromAddress)
+	ops := templateOperations(1000, optimism.Currency, false)
+
+	preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          map[string]interface{}{"replace_tx_hash": replacedTxHash, "cancel": true},
+	})
+	assert.Nil(t, rErr)
+
+	mockClient.On("PendingTransaction", ctx, replacedTxHash).
+		Return(replacedTransaction(toAddress, 1000), from, nil).Once()
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	mockClient.On("BaseFee", ctx).Return(big.NewInt(200), nil).Once()
+	mockClient.On("SuggestGasTipCap", ctx).Return(big.NewInt(10), nil).Once()
+	metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, "0x7", metadataResponse.Metadata["nonce"])
+	assert.Equal(t, "0x5208", metadataResponse.Metadata["gas_limit"])
+	assert.Equal(t, fromAddress, metadataResponse.Metadata["to"])
+	assert.Equal(t, true, metadataResponse.Metadata["cancel"])
+
+	payloadsResponse, rErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, rErr)
+
+	parseResponse, rErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, rosettaOperations(fromAddress, fromAddress, big.NewInt(0), optimism.Currency, optimism.CallOpType),
+		parseResponse.Operations)
+
+	// The intent of a cancellation must not be smuggled into its metadata
+	metadataResponse.Metadata["value"] = "0x3e8"
+	_, rErr = servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Equal(t, templateError(ErrBadRequest, "cancellation must not transfer value"), rErr)
+
+	mockClient.AssertExpectations(t)
+}
@@ -101,6 +101,10 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 	ctx context.Context,
 	request *types.ConstructionPreprocessRequest,
 ) (*types.ConstructionPreprocessResponse, *types.Error) {
+	if isCreateIntent(request.Operations) {
+		return preprocessCreate(request)
+	}
+
 	fromOp, toOp, err := matchOperations(request.Operations)
 	if err != nil {
 		return nil, wrapErr(ErrUnclearIntent, err)
@@ -128,146 +132,199 @@ func (s *ConstructionAPIService) ConstructionPreprocess(
 		Value: value,
 	}
 
+	if err := parseOptionOverrides(request.Metadata, preprocessOutputOptions); err != nil {
+		return nil, err
+	}
+
+	currency := fromOp.Amount.Currency
+	opType := fromOp.Type
+	if _, ok := request.Metadata["method_signature"]; !ok && !isNativeCurrency(currency) {
+		tokenContractAddress, err := getTokenContractAddress(currency)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidTokenContractAddress, err)
+		}
+
+		preprocessOutputOptions.TokenAddress = tokenContractAddress
+		switch opType {
+		case optimism.DelegateVotesOpType:
+			preprocessOutputOptions.Data = constructERC20VotesDelegateData(checkTo)
+			preprocessOutputOptions.Value = big.NewInt(0)
+		case optimism.ERC20ApproveOpType:
+			preprocessOutputOptions.Data = constructERC20ApproveData(checkTo, value)
+			preprocessOutputOptions.Value = big.NewInt(0)
+			preprocessOutputOptions.Owner = checkFrom
+			preprocessOutputOptions.Spender = checkTo
+		case optimism.ERC20TransferFromOpType:
+			spender, err := getSpender(fromOp)
+			if err != nil {
+				return nil, wrapErr(ErrInvalidAddress, err)
+			}
+			preprocessOutputOptions.Data = constructERC20TransferFromData(checkFrom, checkTo, value)
+			preprocessOutputOptions.Value = big.NewInt(0)
+			// The spender sends the transaction on behalf of the owner
+			preprocessOutputOptions.From = spender
+			preprocessOutputOptions.Owner = checkFrom
+			preprocessOutputOptions.Spender = spender
+		default:
+			preprocessOutputOptions.Data = constructERC20TransferData(checkTo, value)
+			preprocessOutputOptions.Value = big.NewInt(0) // value is 0 when sending ERC20
+		}
+	}
+
+	if v, ok := request.Metadata["method_signature"]; ok {
+		methodSigStringObj := v.(string)
+		if !ok {
+			return nil, wrapErr(
+				ErrInvalidSignature,
+				fmt.Errorf("%s is not a valid signature string", v),
+			)
+		}
+		data, err := constructContractCallData(
+			s.abiRegistry(), checkTo, methodSigStringObj, request.Metadata["method_args"],
+		)
+		if err != nil {
+			return nil, wrapErr(ErrFetchFunctionSignatureMethodID, err)
+		}
+		preprocessOutputOptions.ContractAddress = checkTo
+		preprocessOutputOptions.Data = data
+		preprocessOutputOptions.MethodSignature = methodSigStringObj
+		preprocessOutputOptions.MethodArgs = request.Metadata["method_args"]
+	}
+
+	// A cancellation replaces the intent with a 0-value self-transfer
+	if preprocessOutputOptions.Cancel {
+		cancelOptions(preprocessOutputOptions)
+	}
+
+	marshaled, err := marshalJSONMap(preprocessOutputOptions)
+	if err != nil {
+		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	}
+
+	return &types.ConstructionPreprocessResponse{
+		Options: marshaled,
+	}, nil
+}
+
+// parseOptionOverrides sets the nonce and gas parameters of opts that
+// are overridden in the metadata of a /construction/preprocess request
+func parseOptionOverrides(metadata map[string]interface{}, opts *options) *types.Error {
 	// Override nonce
-	if v, ok := request.Metadata["nonce"]; ok {
+	if v, ok := metadata["nonce"]; ok {
 		stringObj, ok := v.(string)
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidNonce,
 				fmt.Errorf("%s is not a valid nonce string", v),
 			)
 		}
 		bigObj, ok := new(big.Int).SetString(stringObj, 10) //nolint:gomnd
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidNonce,
 				fmt.Errorf("%s is not a valid nonce", v),
 			)
 		}
-		preprocessOutputOptions.Nonce = bigObj
+		opts.Nonce = bigObj
 	}
 
 	// Override gas_price
-	if v, ok := request.Metadata["gas_price"]; ok {
+	if v, ok := metadata["gas_price"]; ok {
 		stringObj, ok := v.(string)
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasPrice,
 				fmt.Errorf("%s is not a valid gas_price string", v),
 			)
 		}
 		bigObj, ok := new(big.Int).SetString(stringObj, 10) //nolint:gomnd
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasPrice,
 				fmt.Errorf("%s is not a valid gas_price", v),
 			)
 		}
-		preprocessOutputOptions.GasPrice = bigObj
+		opts.GasPrice = bigObj
 	}
 
 	// Override gas_tip_cap
-	if v, ok := request.Metadata["gas_tip_cap"]; ok {
+	if v, ok := metadata["gas_tip_cap"]; ok {
 		stringObj, ok := v.(string)
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasTipCap,
 				fmt.Errorf("%s is not a valid gas_tip_cap string", v),
 			)
 		}
 		bigObj, ok := new(big.Int).SetString(stringObj, 10) //nolint:gomnd
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasTipCap,
 				fmt.Errorf("%s is not a valid gas_tip_cap", v),
 			)
 		}
-		preprocessOutputOptions.GasTipCap = bigObj
+		opts.GasTipCap = bigObj
 	}
 
 	// Override gas_fee_cap
-	if v, ok := request.Metadata["gas_fee_cap"]; ok {
+	if v, ok := metadata["gas_fee_cap"]; ok {
 		stringObj, ok := v.(string)
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasFeeCap,
 				fmt.Errorf("%s is not a valid gas_fee_cap string", v),
 			)
 		}
 		bigObj, ok := new(big.Int).SetString(stringObj, 10) //nolint:gomnd
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasFeeCap,
 				fmt.Errorf("%s is not a valid gas_fee_cap", v),
 			)
 		}
-		preprocessOutputOptions.GasFeeCap = bigObj
+		opts.GasFeeCap = bigObj
 	}
 
 	// Override gas_limit
-	if v, ok := request.Metadata["gas_limit"]; ok {
+	if v, ok := metadata["gas_limit"]; ok {
 		stringObj, ok := v.(string)
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasLimit,
 				fmt.Errorf("expected gas_limit value to be string, instead got: %T", v),
 			)
 		}
 		bigObj, ok := new(big.Int).SetString(stringObj, 10) //nolint:gomnd
 		if !ok {
-			return nil, wrapErr(
+			return wrapErr(
 				ErrInvalidGasLimit,
 				fmt.Errorf("%s is not a valid gas_limit", v),
 			)
 		}
-		preprocessOutputOptions.GasLimit = bigObj
+		opts.GasLimit = bigObj
 	}
 
-	currency := fromOp.Amount.Currency
-	opType := fromOp.Type
-	if _, ok := request.Metadata["method_signature"]; !ok && !isNativeCurrency(currency) {
-		tokenContractAddress, err := getTokenContractAddress(currency)
-		if err != nil {
-			return nil, wrapErr(ErrInvalidTokenContractAddress, err)
-		}
-
-		preprocessOutputOptions.TokenAddress = tokenContractAddress
-		switch opType {
-		case optimism.DelegateVotesOpType:
-			preprocessOutputOptions.Data = constructERC20VotesDelegateData(checkTo)
-			preprocessOutputOptions.Value = big.NewInt(0)
-		default:
-			preprocessOutputOptions.Data = constructERC20TransferData(checkTo, value)
-			preprocessOutputOptions.Value = big.NewInt(0) // value is 0 when sending ERC20
-		}
-	}
-
-	if v, ok := request.Metadata["method_signature"]; ok {
-		methodSigStringObj := v.(string)
+	// Opt into simulation
+	if v, ok := metadata["simulate"]; ok {
+		simulate, ok := v.(bool)
 		if !ok {
-			return nil, wrapErr(
-				ErrInvalidSignature,
-				fmt.Errorf("%s is not a valid signature string", v),
+			return wrapErr(
+				ErrSimulationUnavailable,
+				fmt.Errorf("expected simulate value to be bool, instead got: %T", v),
 			)
 		}
-		data, err := constructContractCallData(methodSigStringObj, request.Metadata["method_args"])
-		if err != nil {
-			return nil, wrapErr(ErrFetchFunctionSignatureMethodID, err)
-		}
-		preprocessOutputOptions.ContractAddress = checkTo
-		preprocessOutputOptions.Data = data
-		preprocessOutputOptions.MethodSignature = methodSigStringObj
-		preprocessOutputOptions.MethodArgs = request.Metadata["method_args"]
+		opts.Simulate = simulate
 	}
 
-	marshaled, err := marshalJSONMap(preprocessOutputOptions)
-	if err != nil {
-		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
+	if err := parseTxTypeOverrides(metadata, opts); err != nil {
+		return err
 	}
 
-	return &types.ConstructionPreprocessResponse{
-		Options: marshaled,
-	}, nil
+	if err := parseChainIDOverride(metadata, opts); err != nil {
+		return err
+	}
+
+	return parseReplacementOverrides(metadata, opts)
 }
 
 // ConstructionMetadata implements the /construction/metadata endpoint.
@@ -275,10 +332,6 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 	ctx context.Context,
 	request *types.ConstructionMetadataRequest,
 ) (*types.ConstructionMetadataResponse, *types.Error) {
-	if s.config.Mode != configuration.Online {
-		return nil, ErrUnavailableOffline
-	}
-
 	var input options
 	if err := unmarshalJSONMap(request.Options, &input); err != nil {
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
@@ -288,7 +341,7 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrInvalidAddress, errors.New("source address is not provided"))
 	}
 
-	if len(input.To) == 0 {
+	if len(input.To) == 0 && !input.Create {
 		return nil, wrapErr(ErrInvalidAddress, errors.New("destination address is not provided"))
 	}
 
@@ -297,9 +350,33 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", input.From))
 	}
 
-	checkTo, ok := optimism.ChecksumAddress(input.To)
-	if !ok {
-		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", input.To))
+	// Contract deployments have no destination address
+	var checkTo string
+	if !input.Create {
+		checkTo, ok = optimism.ChecksumAddress(input.To)
+		if !ok {
+			return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", input.To))
+		}
+	}
+
+	if err := s.validateChainID(input.ChainID); err != nil {
+		return nil, err
+	}
+
+	if s.config.Mode != configuration.Online {
+		return s.offlineMetadata(&input, checkTo)
+	}
+
+	replaced, rErr := s.pendingReplacement(ctx, &input, checkFrom)
+	if rErr != nil {
+		return nil, rErr
+	}
+	if replaced != nil {
+		input.Nonce = new(big.Int).SetUint64(replaced.Nonce())
+		// A speed-up keeps the gas limit of the replaced transaction
+		if !input.Cancel && input.GasLimit == nil {
+			input.GasLimit = new(big.Int).SetUint64(replaced.Gas())
+		}
 	}
 
 	nonce, err := s.calculateNonce(ctx, input.Nonce, checkFrom)
@@ -321,7 +398,17 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 
 	to := checkTo
 
+	// The transfer gas limit never suffices to deploy a contract
+	if input.Create && input.GasLimit == nil {
+		var err *types.Error
+		gasLimit, err = s.calculateGasLimit(ctx, checkFrom, "", input.Data, input.Value, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// For tokens only
+	var allowance *big.Int
 	if len(input.TokenAddress) > 0 {
 		checkTokenContractAddress, ok := optimism.ChecksumAddress(input.TokenAddress)
 		if !ok {
@@ -333,9 +420,30 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		// Override the destination address to be the contract address
 		to = checkTokenContractAddress
 
+		// Allowance intents report the allowance of the spender, which must
+		// cover a transferFrom for its gas to be estimated
+		if len(input.Owner) > 0 && len(input.Spender) > 0 {
+			allowance, err = s.calculateAllowance(ctx, checkTokenContractAddress, input.Owner, input.Spender)
+			if err != nil {
+				return nil, wrapErr(ErrGeth, err)
+			}
+			if dataHasFunc(input.Data, erc20TransferFromMethodID) {
+				_, _, amount, err := erc20TransferFromArgs(input.Data)
+				if err != nil {
+					return nil, wrapErr(ErrBadRequest, err)
+				}
+				if allowance.Cmp(amount) < 0 {
+					return nil, wrapErr(
+						ErrInsufficientAllowance,
+						fmt.Errorf("allowance %s is less than transfer value %s", allowance, amount),
+					)
+				}
+			}
+		}
+
 		if input.GasLimit == nil {
 			var err *types.Error
-			gasLimit, err = s.calculateGasLimit(ctx, checkFrom, checkTokenContractAddress, input.Data, nil)
+			gasLimit, err = s.calculateGasLimit(ctx, checkFrom, checkTokenContractAddress, input.Data, nil, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -356,21 +464,65 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 
 		if input.GasLimit == nil {
 			var err *types.Error
-			gasLimit, err = s.calculateGasLimit(ctx, checkFrom, checkContractAddress, input.Data, input.Value)
+			gasLimit, err = s.calculateGasLimit(ctx, checkFrom, checkContractAddress, input.Data, input.Value, nil)
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
 
+	accessList := input.AccessList
+	if input.CreateAccessList {
+		var err *types.Error
+		accessList, err = s.calculateAccessList(ctx, checkFrom, to, input.Data, input.Value)
+		if err != nil {
+			return nil, err
+		}
+		// The gas used by eth_createAccessList is not a safe gas limit, so the
+		// gas is estimated again with the generated access list applied
+		if input.GasLimit == nil {
+			gasLimit, err = s.calculateGasLimit(ctx, checkFrom, to, input.Data, input.Value, accessList)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else if accessList != nil && input.GasLimit == nil {
+		gasLimit += accessListGas(accessList)
+	}
+
 	// For backwards compatibility, the gasPrice is always provided in the response
 	gasPrice, err := s.calculateGasPrice(ctx, input.GasPrice)
 	if err != nil {
 		return nil, wrapErr(ErrGeth, err)
 	}
-	gasTipCap, gasFeeCap, err := s.calculateFeeCaps(ctx, input.GasTipCap, input.GasFeeCap)
-	if err != nil {
-		return nil, wrapErr(ErrGeth, err)
+
+	// Legacy and access list transactions are priced by the gasPrice alone
+	var gasTipCap, gasFeeCap *big.Int
+	if input.TxType != LegacyTxType && input.TxType != AccessListTxType {
+		gasTipCap, gasFeeCap, err = s.calculateFeeCaps(ctx, input.GasTipCap, input.GasFeeCap)
+		if err != nil {
+			return nil, wrapErr(ErrGeth, err)
+		}
+		if input.TxType == DynamicFeeTxType && (gasTipCap == nil || gasFeeCap == nil) {
+			return nil, wrapErr(
+				ErrInvalidTxType,
+				errors.New("gas tip and fee caps are unavailable for a dynamic_fee transaction"),
+			)
+		}
+	}
+
+	// The fees of a replacement must be bumped for the pending transaction to be replaced
+	if replaced != nil {
+		if !input.Cancel {
+			if err := validateSpeedUp(replaced, to, input.Data, input.Value); err != nil {
+				return nil, wrapErr(ErrInvalidReplacement, err)
+			}
+		}
+		gasPrice = replacementFee(gasPrice, replaced.GasPrice())
+		if gasFeeCap != nil {
+			gasTipCap = replacementFee(gasTipCap, replaced.GasTipCap())
+			gasFeeCap = bigIntMax(replacementFee(gasFeeCap, replaced.GasFeeCap()), gasTipCap)
+		}
 	}
 
 	// Build eth transaction for L1 fee calculation
@@ -383,6 +535,9 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		GasTipCap: gasTipCap,
 		GasFeeCap: gasFeeCap,
 		GasLimit:  gasLimit,
+
+		TxType:     input.TxType,
+		AccessList: accessList,
 	}
 	ethTx := AsEthTransaction(unsignedTx)
 	ethTxBytes, err := ethTx.MarshalBinary()
@@ -403,6 +558,23 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		}
 	}
 
+	// Simulations fail closed, so that a transaction is never reported as safe without being executed
+	var simulation *optimism.SimulationResult
+	if input.Simulate {
+		simulation, err = s.client.SimulateTransaction(ctx, simulationCallMsg(checkFrom, unsignedTx))
+		if err != nil {
+			return nil, wrapErr(ErrSimulationUnavailable, err)
+		}
+	}
+
+	// The L2 execution fee is bounded by the fee cap for EIP-1559 transactions
+	l2ExecutionFee := new(big.Int).SetUint64(gasLimit)
+	if gasFeeCap != nil {
+		l2ExecutionFee.Mul(l2ExecutionFee, gasFeeCap)
+	} else {
+		l2ExecutionFee.Mul(l2ExecutionFee, gasPrice)
+	}
+
 	metadata := &metadata{
 		Nonce:           nonce,
 		GasPrice:        gasPrice,
@@ -415,6 +587,12 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		MethodSignature: input.MethodSignature,
 		MethodArgs:      input.MethodArgs,
 		L1DataFee:       l1DataFee,
+		L2ExecutionFee:  l2ExecutionFee,
+		Allowance:       allowance,
+		Cancel:          input.Cancel,
+		Simulation:      simulation,
+		TxType:          input.TxType,
+		AccessList:      accessList,
 	}
 
 	metadataMap, err := marshalJSONMap(metadata)
@@ -422,19 +600,17 @@ func (s *ConstructionAPIService) ConstructionMetadata(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	// Find suggested gas usage
-	suggestedFee := metadata.GasPrice.Int64() * int64(gasLimit)
-	if metadata.GasFeeCap != nil {
-		suggestedFee = metadata.GasFeeCap.Int64() * int64(gasLimit)
+	// The sender pays for both the L2 execution and the L1 data of the transaction,
+	// the latter often being the larger part of the fee
+	suggestedFee := new(big.Int).Set(l2ExecutionFee)
+	if l1DataFee != nil {
+		suggestedFee.Add(suggestedFee, l1DataFee)
 	}
 
 	return &types.ConstructionMetadataResponse{
 		Metadata: metadataMap,
 		SuggestedFee: []*types.Amount{
-			{
-				Value:    strconv.FormatInt(suggestedFee, 10),
-				Currency: optimism.Currency,
-			},
+			optimism.Amount(suggestedFee, optimism.Currency),
 		},
 	}, nil
 }
@@ -450,15 +626,42 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	fromOp, toOp, err := matchOperations(request.Operations)
+	create := isCreateIntent(request.Operations) && !metadata.Cancel
+	var fromOp, toOp *types.Operation
+	var err error
+	if isCreateIntent(request.Operations) {
+		fromOp, err = matchCreateOperation(request.Operations)
+	} else {
+		fromOp, toOp, err = matchOperations(request.Operations)
+	}
 	if err != nil {
 		return nil, wrapErr(ErrUnclearIntent, err)
 	}
-	if err := validateRequest(fromOp, toOp, metadata); err != nil {
-		return nil, wrapErr(ErrBadRequest, err)
-	}
 
 	fromAdd := fromOp.Account.Address
+	// The spender signs transfers out of the allowance of the owner
+	if fromOp.Type == optimism.ERC20TransferFromOpType {
+		spender, err := getSpender(fromOp)
+		if err != nil {
+			return nil, wrapErr(ErrInvalidAddress, err)
+		}
+		fromAdd = spender
+	}
+
+	switch {
+	case metadata.Cancel:
+		err = validateCancel(fromAdd, metadata)
+	case create:
+		err = validateCreateRequest(fromOp, metadata)
+	default:
+		err = validateRequest(fromOp, toOp, metadata, s.abiRegistry())
+	}
+	if err != nil {
+		return nil, wrapErr(ErrBadRequest, err)
+	}
+	if err := s.validateChainID(metadata.ChainID); err != nil {
+		return nil, err
+	}
 	amount := metadata.Value
 	toAdd := metadata.To
 	nonce := metadata.Nonce
@@ -474,10 +677,13 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 	if !ok {
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", fromAdd))
 	}
-	// Ensure valid to address
-	checkTo, ok := optimism.ChecksumAddress(toAdd)
-	if !ok {
-		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
+	// Ensure valid to address, unless a contract is deployed
+	var checkTo string
+	if !create {
+		checkTo, ok = optimism.ChecksumAddress(toAdd)
+		if !ok {
+			return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", toAdd))
+		}
 	}
 
 	unsignedTx := &transaction{
@@ -491,6 +697,12 @@ func (s *ConstructionAPIService) ConstructionPayloads(
 		GasFeeCap: gasFeeCap,
 		GasLimit:  transferGasLimit,
 		ChainID:   chainID,
+
+		TxType:     metadata.TxType,
+		AccessList: metadata.AccessList,
+	}
+	if fromOp.Type == optimism.ERC20ApproveOpType || fromOp.Type == optimism.ERC20TransferFromOpType {
+		unsignedTx.Currency = fromOp.Amount.Currency
 	}
 	signer := ethTypes.NewLondonSigner(chainID)
 	sighash := signer.Hash(AsEthTransaction(unsignedTx))
@@ -584,7 +796,9 @@ func (s *ConstructionAPIService) ConstructionParse(
 			return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 		}
 
-		tx.To = t.To().String()
+		if t.To() != nil {
+			tx.To = t.To().String()
+		}
 		tx.Value = t.Value()
 		tx.Data = t.Data()
 		tx.Nonce = t.Nonce()
@@ -593,6 +807,8 @@ func (s *ConstructionAPIService) ConstructionParse(
 		tx.GasTipCap = t.GasTipCap()
 		tx.GasLimit = t.Gas()
 		tx.ChainID = t.ChainId()
+		tx.TxType = txTypeName(t.Type())
+		tx.AccessList = t.AccessList()
 
 		msg, err := t.AsMessage(ethTypes.NewLondonSigner(t.ChainId()), nil)
 		if err != nil {
@@ -602,8 +818,20 @@ func (s *ConstructionAPIService) ConstructionParse(
 		tx.From = msg.From().Hex()
 	}
 
+	// Unsigned transactions built without a tx_type have the type implied by their gas parameters
+	if len(tx.TxType) == 0 {
+		tx.TxType = txTypeName(AsEthTransaction(&tx).Type())
+	}
+
+	if len(tx.To) == 0 {
+		return parseCreate(&tx, request.Signed)
+	}
+
 	currency := optimism.Currency
 	opType := optimism.CallOpType
+	opFrom := tx.From
+	var spender string
+	var callMetadata map[string]interface{}
 
 	//TODO: add logic for contract call parsing ERC20 currency
 	if hasData(tx.Data) && dataHasFunc(tx.Data, erc20TransferMethodID) {
@@ -641,6 +869,50 @@ func (s *ConstructionAPIService) ConstructionParse(
 		// Update destination address to be the actual recipient
 		tx.To = delegatee.String()
 		opType = optimism.DelegateVotesOpType
+	} else if hasData(tx.Data) && dataHasFunc(tx.Data, erc20ApproveMethodID) {
+		spender, amount, err := erc20ApproveArgs(tx.Data)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseTransaction, err)
+		}
+
+		var cErr *types.Error
+		currency, cErr = s.allowanceCurrency(ctx, &tx)
+		if cErr != nil {
+			return nil, cErr
+		}
+		// Update destination address to be the approved spender
+		tx.To = spender.String()
+		tx.Value = amount
+		opType = optimism.ERC20ApproveOpType
+	} else if hasData(tx.Data) && dataHasFunc(tx.Data, erc20TransferFromMethodID) {
+		owner, toAdd, amount, err := erc20TransferFromArgs(tx.Data)
+		if err != nil {
+			return nil, wrapErr(ErrUnableToParseTransaction, err)
+		}
+
+		var cErr *types.Error
+		currency, cErr = s.allowanceCurrency(ctx, &tx)
+		if cErr != nil {
+			return nil, cErr
+		}
+		// The owner is debited, while the sender of the transaction is the spender
+		spender = tx.From
+		opFrom = owner.String()
+		tx.To = toAdd.String()
+		tx.Value = amount
+		opType = optimism.ERC20TransferFromOpType
+	} else if hasData(tx.Data) {
+		// Other contract calls are decoded if their method is in the ABI registry
+		if method, ok := s.abiRegistry().Method(tx.To, tx.Data); ok {
+			args, err := decodeABIArgs(method, tx.Data)
+			if err != nil {
+				return nil, wrapErr(ErrUnableToParseTransaction, err)
+			}
+			callMetadata = map[string]interface{}{
+				MethodSignatureKey: method.Sig,
+				MethodArgsKey:      args,
+			}
+		}
 	}
 
 	// Ensure valid from address
@@ -655,7 +927,18 @@ func (s *ConstructionAPIService) ConstructionParse(
 		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", tx.To))
 	}
 
-	ops := rosettaOperations(checkFrom, checkTo, tx.Value, currency, opType)
+	checkOpFrom, ok := optimism.ChecksumAddress(opFrom)
+	if !ok {
+		return nil, wrapErr(ErrInvalidAddress, fmt.Errorf("%s is not a valid address", opFrom))
+	}
+
+	ops := rosettaOperations(checkOpFrom, checkTo, tx.Value, currency, opType)
+	if len(spender) > 0 {
+		ops[0].Metadata = map[string]interface{}{SpenderKey: checkFrom}
+	}
+	if callMetadata != nil {
+		ops[1].Metadata = callMetadata
+	}
 
 	metadata := &parseMetadata{
 		Nonce:     tx.Nonce,
@@ -664,6 +947,9 @@ func (s *ConstructionAPIService) ConstructionParse(
 		GasFeeCap: tx.GasFeeCap,
 		GasLimit:  tx.GasLimit,
 		ChainID:   tx.ChainID,
+
+		TxType:     tx.TxType,
+		AccessList: tx.AccessList,
 	}
 	metaMap, err := marshalJSONMap(metadata)
 	if err != nil {
@@ -709,38 +995,63 @@ func (s *ConstructionAPIService) ConstructionSubmit(
 		return nil, wrapErr(ErrUnableToParseIntermediateResult, err)
 	}
 
-	if err := s.client.SendTransaction(ctx, &signedTx); err != nil {
-		return nil, wrapErr(ErrBroadcastFailed, err)
+	submitMeta, err := parseSubmitMetadata(request.SignedTransaction)
+	if err != nil {
+		return nil, wrapErr(ErrBadRequest, err)
+	}
+
+	submissions, err := s.client.SubmitTransaction(ctx, &signedTx, submitMeta.Conditional)
+	if err != nil {
+		rErr := broadcastError(err)
+		rErr.Details[submissionsKey] = submissions
+		return nil, rErr
 	}
 
 	txIdentifier := &types.TransactionIdentifier{
 		Hash: signedTx.Hash().Hex(),
 	}
+	metadata := map[string]interface{}{
+		submissionsKey: submissions,
+	}
+	if submitMeta.WaitForInclusion {
+		inclusion := s.waitForInclusion(ctx, signedTx.Hash(), submitMeta.InclusionLevel)
+		for k, v := range inclusionMetadata(inclusion) {
+			metadata[k] = v
+		}
+	}
+
 	return &types.TransactionIdentifierResponse{
 		TransactionIdentifier: txIdentifier,
+		Metadata:              metadata,
 	}, nil
 }
 
 // calculatesGasLimit calculates the gasLimit for an ERC20 transfer
-// if gas limit is not provided
+// if gas limit is not provided. An empty to estimates a contract deployment.
 func (s *ConstructionAPIService) calculateGasLimit(
 	ctx context.Context,
 	from string,
 	to string,
 	data []byte,
 	value *big.Int,
+	accessList ethTypes.AccessList,
 ) (uint64, *types.Error) {
 	fromAddress := common.HexToAddress(from)
-	toAddress := common.HexToAddress(to)
+	var toAddress *common.Address
+	if len(to) > 0 {
+		addr := common.HexToAddress(to)
+		toAddress = &addr
+	}
 	var v *big.Int
 	if value != nil && value.Cmp(big.NewInt(0)) != 0 {
 		v = value
 	}
 	gasLimit, err := s.client.EstimateGas(ctx, ethereum.CallMsg{
-		From:  fromAddress,
-		To:    &toAddress,
-		Data:  data,
-		Value: v,
+		From:       fromAddress,
+		To:         toAddress,
+		Data:       data,
+		Value:      v,
+		AccessList: accessList,
 	})
 
 	if err != nil {
@@ -750,6 +1061,22 @@ func (s *ConstructionAPIService) calculateGasLimit(
 	return gasLimit, nil
 }
 
+// simulationCallMsg returns the call simulating tx sent by from
+func simulationCallMsg(from string, tx *transaction) ethereum.CallMsg {
+	msg := ethereum.CallMsg{
+		From:       common.HexToAddress(from),
+		Gas:        tx.GasLimit,
+		Value:      tx.Value,
+		Data:       tx.Data,
+		AccessList: tx.AccessList,
+	}
+	if len(tx.To) > 0 {
+		to := common.HexToAddress(tx.To)
+		msg.To = &to
+	}
+	return msg
+}
+
 // calculateNonce will calculate the nonce for the from address if
 // nonce is not provided
 func (s *ConstructionAPIService) calculateNonce(
@@ -864,6 +1191,20 @@ func matchOperationDescriptions(operations []*types.Operation) ([]*parser.Operat
 		if opType == "" { // default to PaymentOpType for backwards compatibility
 			opType = optimism.PaymentOpType
 		}
+	} else if operations[0].Type == optimism.ERC20ApproveOpType ||
+		operations[0].Type == optimism.ERC20TransferFromOpType {
+		return nil, fmt.Errorf("%s requires a token currency", operations[0].Type)
+	}
+
+	// The owner debited by a transferFrom is not the sender of the transaction
+	var fromMetadata []*parser.MetadataDescription
+	if opType == optimism.ERC20TransferFromOpType {
+		fromMetadata = []*parser.MetadataDescription{
+			{
+				Key:       SpenderKey,
+				ValueKind: reflect.String,
+			},
+		}
 	}
 
 	return []*parser.OperationDescription{
@@ -877,6 +1218,7 @@ func matchOperationDescriptions(operations []*types.Operation) ([]*parser.Operat
 				Sign:     parser.NegativeOrZeroAmountSign,
 				Currency: firstCurrency,
 			},
+			Metadata: fromMetadata,
 		},
 		{
 			Type: opType,
@@ -954,10 +1296,32 @@ func constructERC20VotesDelegateData(to string) []byte {
 	return data
 }
 
-// constructContractCallData constructs the data field of an Optimism transaction
-func constructContractCallData(methodSig string, methodArgsGeneric interface{}) ([]byte, error) {
-	data := contractCallMethodID(methodSig)
+// constructContractCallData constructs the data field of an Optimism transaction.
+// Methods in the ABI registry accept nested tuple and array arguments, unless the
+// arguments are already ABI encoded.
+func constructContractCallData(
+	registry *configuration.ABIRegistry,
+	contract string,
+	methodSig string,
+	methodArgsGeneric interface{},
+) ([]byte, error) {
+	methodID := contractCallMethodID(methodSig)
+	if method, ok := registry.Method(contract, methodID); ok {
+		if _, encoded := methodArgsGeneric.(string); !encoded {
+			args, err := encodeABIArgs(method, methodArgsGeneric)
+			if err != nil {
+				return nil, err
+			}
+			return append(methodID, args...), nil
+		}
+	}
 
+	return encodeMethodArgs(methodID, methodSig, methodArgsGeneric)
+}
+
+// encodeMethodArgs appends the ABI encoded method args to data, which is either
+// a method selector or the init code of a contract (see [constructCreationData])
+func encodeMethodArgs(data []byte, methodSig string, methodArgsGeneric interface{}) ([]byte, error) {
 	// switch on the type of the method args. method args can come in from json as either a string or list of strings
 	switch methodArgs := methodArgsGeneric.(type) {
 	// case 0: no method arguments, return the selector
@@ -1118,6 +1482,7 @@ func validateRequest(
 	fromOp *types.Operation,
 	toOp *types.Operation,
 	metadata metadata,
+	registry *configuration.ABIRegistry,
 ) error {
 	if !hasData(metadata.Data) {
 		// Native currency
@@ -1162,9 +1527,42 @@ func validateRequest(
 		if metadata.Value.String() != "0" {
 			return errors.New("invalid metadata value for delegation")
 		}
+	} else if dataHasFunc(metadata.Data, erc20ApproveMethodID) {
+		// ERC20 approve
+		spender, amount, err := erc20ApproveArgs(metadata.Data)
+		if err != nil {
+			return err
+		}
+		if spender != common.HexToAddress(toOp.Account.Address) {
+			return errors.New("mismatch spender address")
+		}
+		if amount.String() != toOp.Amount.Value {
+			return errors.New("mismatch allowance value")
+		}
+		if metadata.Value.String() != "0" {
+			return errors.New("invalid metadata value")
+		}
+	} else if dataHasFunc(metadata.Data, erc20TransferFromMethodID) {
+		// ERC20 transferFrom
+		owner, toAdd, amount, err := erc20TransferFromArgs(metadata.Data)
+		if err != nil {
+			return err
+		}
+		if owner != common.HexToAddress(fromOp.Account.Address) {
+			return errors.New("mismatch owner address")
+		}
+		if toAdd != common.HexToAddress(toOp.Account.Address) {
+			return errors.New("mismatch destination address")
+		}
+		if amount.String() != toOp.Amount.Value {
+			return errors.New("mismatch transfer value")
+		}
+		if metadata.Value.String() != "0" {
+			return errors.New("invalid metadata value")
+		}
 	} else {
 		// other contract calls
-		data, err := constructContractCallData(metadata.MethodSignature, metadata.MethodArgs)
+		data, err := constructContractCallData(registry, metadata.To, metadata.MethodSignature, metadata.MethodArgs)
 		if err != nil {
 			return err
 		}
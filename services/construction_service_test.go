@@ -30,6 +30,7 @@ import (
 
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/ethereum-optimism/optimism/l2geth/params"
+	"github.com/ethereum-optimism/optimism/op-bindings/predeploys"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
@@ -151,11 +152,12 @@ func TestConstructionService(t *testing.T) {
 
 	// Test Metadata
 	metadata := &metadata{
-		GasLimit: 21000,
-		GasPrice: big.NewInt(1000000000),
-		Nonce:    0,
-		To:       "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d",
-		Value:    big.NewInt(42894881044106498),
+		GasLimit:       21000,
+		GasPrice:       big.NewInt(1000000000),
+		Nonce:          0,
+		To:             "0x57B414a0332B5CaB885a451c2a28a07d1e9b8a8d",
+		Value:          big.NewInt(42894881044106498),
+		L2ExecutionFee: big.NewInt(21000000000000),
 	}
 
 	mockClient.On(
@@ -238,6 +240,7 @@ func TestConstructionService(t *testing.T) {
 		GasPrice: metadata.GasPrice,
 		GasLimit: metadata.GasLimit,
 		ChainID:  big.NewInt(3),
+		TxType:   LegacyTxType,
 	}
 	assert.Equal(t, &types.ConstructionParseResponse{
 		Operations:               parseOps,
@@ -276,6 +279,7 @@ func TestConstructionService(t *testing.T) {
 		GasFeeCap: metadata.GasPrice, // defaults to GasPrice for Signed Legacy transactions
 		GasLimit:  metadata.GasLimit,
 		ChainID:   big.NewInt(3),
+		TxType:    LegacyTxType,
 	}
 	assert.Equal(t, &types.ConstructionParseResponse{
 		Operations: parseOps,
@@ -299,11 +303,14 @@ func TestConstructionService(t *testing.T) {
 	}, hashResponse)
 
 	// Test Submit
+	submissions := []*optimism.SubmitResult{{Endpoint: "default"}}
 	mockClient.On(
-		"SendTransaction",
+		"SubmitTransaction",
 		ctx,
 		mock.Anything, // can't test ethTx here because it contains "time"
+		(*optimism.TransactionConditional)(nil),
 	).Return(
+		submissions,
 		nil,
 	)
 	submitResponse, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
@@ -313,6 +320,7 @@ func TestConstructionService(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, &types.TransactionIdentifierResponse{
 		TransactionIdentifier: transactionIdentifier,
+		Metadata:              map[string]interface{}{"submissions": submissions},
 	}, submitResponse)
 
 	mockClient.AssertExpectations(t)
@@ -364,17 +372,146 @@ func TestPayloads_EIP1559Transaction(t *testing.T) {
 }
 
 func TestMetadata_Offline(t *testing.T) {
-	t.Run("unavailable in offline mode", func(t *testing.T) {
+	t.Run("refused in offline mode without a nonce", func(t *testing.T) {
 		service := ConstructionAPIService{
 			config: &configuration.Configuration{Mode: configuration.Offline},
 		}
 
 		resp, err := service.ConstructionMetadata(
 			context.Background(),
-			&types.ConstructionMetadataRequest{},
+			&types.ConstructionMetadataRequest{
+				Options: map[string]interface{}{"from": fromAddress, "to": toAddress},
+			},
+		)
+		assert.Nil(t, resp)
+		assert.Equal(t, ErrMissingOfflineMetadata.Code, err.Code)
+	})
+}
+
+func TestMetadata_L1DataFee(t *testing.T) {
+	ctx := context.Background()
+	l1DataFee := big.NewInt(123456789000)
+	options := map[string]interface{}{
+		"from":      fromAddress,
+		"to":        toAddress,
+		"value":     transferValueHex,
+		"nonce":     transferNonceHex2,
+		"gas_price": transferGasPriceHex,
+	}
+	isGetL1Fee := mock.MatchedBy(func(msg ethereum.CallMsg) bool {
+		return msg.To != nil && *msg.To == predeploys.GasPriceOracleAddr
+	})
+
+	t.Run("suggested fee includes the L1 data fee", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		service := NewConstructionAPIService(
+			&configuration.Configuration{Mode: configuration.Online, GethURL: "http://localhost:8545"},
+			mockClient,
+		)
+		mockClient.On("BaseFee", ctx).Return(nil, nil)
+		mockClient.On("CallContract", ctx, isGetL1Fee, (*big.Int)(nil)).
+			Return(common.BigToHash(l1DataFee).Bytes(), nil).Once()
+
+		resp, err := service.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           options,
+		})
+		assert.Nil(t, err)
+		l2ExecutionFee := new(big.Int).SetUint64(transferGasPrice * transferGasLimit)
+		assert.Equal(t, &types.ConstructionMetadataResponse{
+			Metadata: map[string]interface{}{
+				"to":               toAddress,
+				"value":            transferValueHex,
+				"nonce":            transferNonceHex2,
+				"gas_price":        transferGasPriceHex,
+				"gas_limit":        transferGasLimitHex,
+				"l1_data_fee":      hexutil.EncodeBig(l1DataFee),
+				"l2_execution_fee": hexutil.EncodeBig(l2ExecutionFee),
+			},
+			SuggestedFee: []*types.Amount{
+				{
+					Value:    new(big.Int).Add(l2ExecutionFee, l1DataFee).String(),
+					Currency: optimism.Currency,
+				},
+			},
+		}, resp)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("gas price oracle failure", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		service := NewConstructionAPIService(
+			&configuration.Configuration{Mode: configuration.Online, GethURL: "http://localhost:8545"},
+			mockClient,
 		)
+		mockClient.On("BaseFee", ctx).Return(nil, nil)
+		mockClient.On("CallContract", ctx, isGetL1Fee, (*big.Int)(nil)).
+			Return(nil, fmt.Errorf("connection refused")).Once()
+
+		resp, err := service.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           options,
+		})
 		assert.Nil(t, resp)
-		assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+		assert.Equal(t, ErrL1DataFee.Code, err.Code)
+	})
+}
+
+func TestMetadata_Simulate(t *testing.T) {
+	ctx := context.Background()
+	options := map[string]interface{}{
+		"from":      fromAddress,
+		"to":        toAddress,
+		"value":     transferValueHex,
+		"nonce":     transferNonceHex2,
+		"gas_price": transferGasPriceHex,
+		"simulate":  true,
+	}
+	to := common.HexToAddress(toAddress)
+	msg := ethereum.CallMsg{
+		From:  common.HexToAddress(fromAddress),
+		To:    &to,
+		Gas:   transferGasLimit,
+		Value: new(big.Int).SetUint64(transferValue),
+	}
+
+	t.Run("predicted operations", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		service := NewConstructionAPIService(&configuration.Configuration{Mode: configuration.Online}, mockClient)
+		simulation := &optimism.SimulationResult{
+			Operations:   templateOperations(transferValue, optimism.Currency, false),
+			GasUsed:      transferGasLimit,
+			Reverted:     true,
+			RevertReason: "execution reverted",
+		}
+		mockClient.On("BaseFee", ctx).Return(nil, nil)
+		mockClient.On("SimulateTransaction", ctx, msg).Return(simulation, nil).Once()
+
+		resp, err := service.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           options,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, forceMarshalMap(t, simulation), resp.Metadata["simulation"])
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("debug namespace unavailable", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		service := NewConstructionAPIService(&configuration.Configuration{Mode: configuration.Online}, mockClient)
+		mockClient.On("BaseFee", ctx).Return(nil, nil)
+		mockClient.On("SimulateTransaction", ctx, msg).
+			Return(nil, fmt.Errorf("the method debug_traceCall does not exist/is not available")).Once()
+
+		resp, err := service.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           options,
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, templateError(
+			ErrSimulationUnavailable, "the method debug_traceCall does not exist/is not available",
+		), err)
+		mockClient.AssertExpectations(t)
 	})
 }
 
@@ -401,11 +538,12 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":        metadataTo,
-					"value":     transferValueHex,
-					"nonce":     transferNonceHex2,
-					"gas_price": transferGasPriceHex,
-					"gas_limit": transferGasLimitHex,
+					"to":               metadataTo,
+					"value":            transferValueHex,
+					"nonce":            transferNonceHex2,
+					"gas_price":        transferGasPriceHex,
+					"gas_limit":        transferGasLimitHex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasPrice * transferGasLimit),
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -432,13 +570,14 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":          metadataTo,
-					"value":       transferValueHex,
-					"nonce":       transferNonceHex2,
-					"gas_price":   hexutil.EncodeUint64(2 * transferGasPrice),
-					"gas_tip_cap": hexutil.EncodeUint64(transferGasTipCap),
-					"gas_fee_cap": hexutil.EncodeUint64(transferGasFeeCap),
-					"gas_limit":   transferGasLimitHex,
+					"to":               metadataTo,
+					"value":            transferValueHex,
+					"nonce":            transferNonceHex2,
+					"gas_price":        hexutil.EncodeUint64(2 * transferGasPrice),
+					"gas_tip_cap":      hexutil.EncodeUint64(transferGasTipCap),
+					"gas_fee_cap":      hexutil.EncodeUint64(transferGasFeeCap),
+					"gas_limit":        transferGasLimitHex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasFeeCap * transferGasLimit),
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -458,11 +597,12 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":        metadataTo,
-					"value":     transferValueHex,
-					"nonce":     transferNonceHex2,
-					"gas_price": hexutil.EncodeUint64(2 * transferGasPrice),
-					"gas_limit": transferGasLimitHex,
+					"to":               metadataTo,
+					"value":            transferValueHex,
+					"nonce":            transferNonceHex2,
+					"gas_price":        hexutil.EncodeUint64(2 * transferGasPrice),
+					"gas_limit":        transferGasLimitHex,
+					"l2_execution_fee": hexutil.EncodeUint64(2 * transferGasPrice * transferGasLimit),
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -491,11 +631,12 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":        metadataTo,
-					"value":     transferValueHex,
-					"nonce":     transferNonceHex,
-					"gas_price": transferGasPriceHex,
-					"gas_limit": transferGasLimitHex,
+					"to":               metadataTo,
+					"value":            transferValueHex,
+					"nonce":            transferNonceHex,
+					"gas_price":        transferGasPriceHex,
+					"gas_limit":        transferGasLimitHex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasPrice * transferGasLimit),
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -514,13 +655,14 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":          metadataTo,
-					"value":       transferValueHex,
-					"nonce":       transferNonceHex2,
-					"gas_price":   transferGasPriceHex,
-					"gas_tip_cap": transferGasTipCapHex,
-					"gas_fee_cap": transferGasFeeCapHex,
-					"gas_limit":   transferGasLimitHex,
+					"to":               metadataTo,
+					"value":            transferValueHex,
+					"nonce":            transferNonceHex2,
+					"gas_price":        transferGasPriceHex,
+					"gas_tip_cap":      transferGasTipCapHex,
+					"gas_fee_cap":      transferGasFeeCapHex,
+					"gas_limit":        transferGasLimitHex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasFeeCap * transferGasLimit),
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -562,12 +704,13 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":        tokenContractAddress,
-					"value":     "0x0",
-					"nonce":     transferNonceHex2,
-					"gas_price": transferGasPriceHex,
-					"gas_limit": transferGasLimitERC20Hex,
-					"data":      metadataData,
+					"to":               tokenContractAddress,
+					"value":            "0x0",
+					"nonce":            transferNonceHex2,
+					"gas_price":        transferGasPriceHex,
+					"gas_limit":        transferGasLimitERC20Hex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasPrice * transferGasLimitERC20),
+					"data":             metadataData,
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -604,14 +747,15 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":          tokenContractAddress,
-					"value":       "0x0",
-					"nonce":       transferNonceHex2,
-					"gas_price":   transferGasPriceHex,
-					"gas_tip_cap": transferGasTipCapHex,
-					"gas_fee_cap": transferGasFeeCapHex,
-					"gas_limit":   transferGasLimitERC20Hex,
-					"data":        metadataData,
+					"to":               tokenContractAddress,
+					"value":            "0x0",
+					"nonce":            transferNonceHex2,
+					"gas_price":        transferGasPriceHex,
+					"gas_tip_cap":      transferGasTipCapHex,
+					"gas_fee_cap":      transferGasFeeCapHex,
+					"gas_limit":        transferGasLimitERC20Hex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasFeeCap * transferGasLimitERC20),
+					"data":             metadataData,
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -646,12 +790,13 @@ func TestMetadata(t *testing.T) {
 			},
 			expectedResponse: &types.ConstructionMetadataResponse{
 				Metadata: map[string]interface{}{
-					"to":        tokenContractAddress,
-					"value":     "0x0",
-					"nonce":     delegateNonceHex,
-					"gas_price": delegateGasPriceHex,
-					"gas_limit": delegateGasLimitHex,
-					"data":      delegateData,
+					"to":               tokenContractAddress,
+					"value":            "0x0",
+					"nonce":            delegateNonceHex,
+					"gas_price":        delegateGasPriceHex,
+					"gas_limit":        delegateGasLimitHex,
+					"l2_execution_fee": hexutil.EncodeUint64(delegateGasPrice * delegateGasLimit),
+					"data":             delegateData,
 				},
 				SuggestedFee: []*types.Amount{
 					{
@@ -692,6 +837,7 @@ func TestMetadata(t *testing.T) {
 					"nonce":            transferNonceHex2,
 					"gas_price":        transferGasPriceHex,
 					"gas_limit":        transferGasLimitERC20Hex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasPrice * transferGasLimitERC20),
 					"data":             metadataGenericData,
 					"method_signature": "approve(address,uint256)",
 					"method_args":      []interface{}{"0xD10a72Cf054650931365Cc44D912a4FD75257058", "1000"},
@@ -736,6 +882,7 @@ func TestMetadata(t *testing.T) {
 					"nonce":            transferNonceHex2,
 					"gas_price":        transferGasPriceHex,
 					"gas_limit":        transferGasLimitERC20Hex,
+					"l2_execution_fee": hexutil.EncodeUint64(transferGasPrice * transferGasLimitERC20),
 					"data":             metadataGenericData,
 					"method_signature": "approve(address,uint256)",
 					"method_args":      []interface{}{"0xD10a72Cf054650931365Cc44D912a4FD75257058", "1000"},
@@ -856,6 +1003,7 @@ func TestParse(t *testing.T) {
 					"gas_price": transferGasPriceHex,
 					"gas_limit": transferGasLimitHex,
 					"chain_id":  chainIDHex,
+					"tx_type":   "legacy",
 				},
 			},
 		},
@@ -875,6 +1023,7 @@ func TestParse(t *testing.T) {
 					"gas_fee_cap": "0x4b0",
 					"gas_limit":   transferGasLimitHex,
 					"chain_id":    chainIDHex,
+					"tx_type":     "dynamic_fee",
 				},
 			},
 		},
@@ -898,6 +1047,7 @@ func TestParse(t *testing.T) {
 					"gas_tip_cap": transferGasPriceHex,
 					"gas_limit":   transferGasLimitHex,
 					"chain_id":    chainIDHex,
+					"tx_type":     "legacy",
 				},
 			},
 		},
@@ -921,6 +1071,7 @@ func TestParse(t *testing.T) {
 					"gas_tip_cap": "0x3e8",
 					"gas_limit":   transferGasLimitHex,
 					"chain_id":    chainIDHex,
+					"tx_type":     "dynamic_fee",
 				},
 			},
 		},
@@ -944,6 +1095,7 @@ func TestParse(t *testing.T) {
 					"gas_price": transferGasPriceHex,
 					"gas_limit": transferGasLimitERC20Hex,
 					"chain_id":  chainIDHex,
+					"tx_type":   "legacy",
 				},
 			},
 		},
@@ -973,6 +1125,7 @@ func TestParse(t *testing.T) {
 					"gas_fee_cap": transferGasPriceHex,
 					"gas_limit":   transferGasLimitERC20Hex,
 					"chain_id":    chainIDHex,
+					"tx_type":     "legacy",
 				},
 			},
 		},
@@ -996,6 +1149,7 @@ func TestParse(t *testing.T) {
 					"gas_price": delegateGasPriceHex,
 					"gas_limit": delegateGasLimitHex,
 					"chain_id":  chainIDHex,
+					"tx_type":   "legacy",
 				},
 			},
 		},
@@ -1021,6 +1175,7 @@ func TestParse(t *testing.T) {
 					"gas_tip_cap": delegateGasPriceHex,
 					"gas_limit":   delegateGasLimitHex,
 					"chain_id":    chainIDHex,
+					"tx_type":     "legacy",
 				},
 			},
 		},
@@ -1115,6 +1270,7 @@ func TestPreprocess(t *testing.T) {
 		"gas_limit":   "21000",
 		"gas_tip_cap": "10",
 		"gas_fee_cap": "20",
+		"simulate":    true,
 	}
 
 	var ops []*types.Operation
@@ -1138,7 +1294,8 @@ func TestPreprocess(t *testing.T) {
 		"gas_price": "0xa",
 		"gas_limit": "0x5208",
 		"gas_tip_cap": "0xa",
-		"gas_fee_cap": "0x14"
+		"gas_fee_cap": "0x14",
+		"simulate": true
 	}`
 	var options options
 	assert.NoError(t, json.Unmarshal([]byte(optionsRaw), &options))
@@ -1366,7 +1523,7 @@ func TestConstructContractCallData(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			data, err := constructContractCallData(test.methodSig, test.methodArgs)
+			data, err := constructContractCallData(nil, "", test.methodSig, test.methodArgs)
 
 			if test.shouldError {
 				assert.Error(t, err)
@@ -0,0 +1,151 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/inphi/optimism-rosetta/optimism"
+)
+
+const (
+	// defaultSubmitTimeout is how long /construction/submit waits for inclusion
+	// when SUBMIT_TIMEOUT is not configured.
+	defaultSubmitTimeout = time.Minute
+
+	// submissionsKey holds the result of sending the transaction to each endpoint.
+	submissionsKey = "submissions"
+
+	// includedKey tells whether a transaction that was waited for has been included.
+	includedKey = "included"
+)
+
+// inclusionPollInterval is how often the receipt of a submitted transaction is polled.
+var inclusionPollInterval = 2 * time.Second
+
+// submitMetadata is read from the "metadata" field of a signed transaction.
+// The field is ignored by geth when the transaction itself is decoded.
+type submitMetadata struct {
+	WaitForInclusion bool   `json:"wait_for_inclusion"`
+	InclusionLevel   string `json:"inclusion_level,omitempty"`
+
+	// Conditional sends the transaction with eth_sendRawTransactionConditional
+	Conditional *optimism.TransactionConditional `json:"conditional,omitempty"`
+}
+
+// parseSubmitMetadata returns the submission options of a signed transaction.
+// Waiting for inclusion defaults to the latest block.
+func parseSubmitMetadata(signedTx string) (*submitMetadata, error) {
+	var wire struct {
+		Metadata *submitMetadata `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(signedTx), &wire); err != nil {
+		return nil, err
+	}
+	if wire.Metadata == nil {
+		return &submitMetadata{}, nil
+	}
+
+	switch wire.Metadata.InclusionLevel {
+	case "":
+		wire.Metadata.InclusionLevel = optimism.LatestInclusion
+	case optimism.LatestInclusion, optimism.SafeInclusion, optimism.FinalizedInclusion:
+	default:
+		return nil, fmt.Errorf("%s is not a valid inclusion level", wire.Metadata.InclusionLevel)
+	}
+
+	return wire.Metadata, nil
+}
+
+// broadcastError maps the rejection reasons of the geth transaction pool
+// to distinct errors, falling back to ErrBroadcastFailed.
+func broadcastError(err error) *types.Error {
+	reason := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(reason, "nonce too low"):
+		return wrapErr(ErrNonceTooLow, err)
+	case strings.Contains(reason, "underpriced"):
+		// Also covers "replacement transaction underpriced"
+		return wrapErr(ErrTransactionUnderpriced, err)
+	case strings.Contains(reason, "insufficient funds"):
+		return wrapErr(ErrInsufficientFunds, err)
+	default:
+		return wrapErr(ErrBroadcastFailed, err)
+	}
+}
+
+// waitForInclusion polls for the receipt of a submitted transaction until its block reaches
+// the given safety level or the submit timeout expires. Failed polls are retried until then,
+// since the transaction has already been broadcast. A nil inclusion means the transaction
+// was not included in time.
+func (s *ConstructionAPIService) waitForInclusion(
+	ctx context.Context,
+	hash common.Hash,
+	level string,
+) *optimism.Inclusion {
+	timeout := s.config.SubmitTimeout
+	if timeout == 0 {
+		timeout = defaultSubmitTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(inclusionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		inclusion, err := s.client.TransactionInclusion(ctx, hash, level)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("unable to poll the inclusion of transaction %s: %v", hash.Hex(), err)
+		}
+		if inclusion != nil {
+			return inclusion
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// inclusionMetadata is the /construction/submit response metadata of a transaction that
+// was waited for. Only "included" is set if it was not included before the submit timeout.
+func inclusionMetadata(inclusion *optimism.Inclusion) map[string]interface{} {
+	if inclusion == nil {
+		return map[string]interface{}{includedKey: false}
+	}
+	metadata := map[string]interface{}{
+		includedKey:        true,
+		"block_identifier": inclusion.BlockIdentifier,
+		"status":           inclusion.Status,
+		"gas_used":         hexutil.EncodeUint64(inclusion.GasUsed),
+		"fee":              hexutil.EncodeBig(inclusion.Fee),
+	}
+	if inclusion.EffectiveGasPrice != nil {
+		metadata["effective_gas_price"] = hexutil.EncodeBig(inclusion.EffectiveGasPrice)
+	}
+
+	return metadata
+}
@@ -0,0 +1,265 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// signedTransaction returns a signed transaction as returned by /construction/combine,
+// with the given submit metadata added when it is not nil.
+func signedTransaction(t *testing.T, metadata map[string]interface{}) (string, common.Hash) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	to := common.HexToAddress(toAddress)
+	tx, err := ethTypes.SignNewTx(key, ethTypes.LatestSignerForChainID(big.NewInt(int64(chainID))), &ethTypes.DynamicFeeTx{
+		ChainID:   big.NewInt(int64(chainID)),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1000),
+		GasFeeCap: big.NewInt(2000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	assert.NoError(t, err)
+
+	raw, err := tx.MarshalJSON()
+	assert.NoError(t, err)
+	if metadata == nil {
+		return string(raw), tx.Hash()
+	}
+
+	var signed map[string]interface{}
+	assert.NoError(t, json.Unmarshal(raw, &signed))
+	signed["metadata"] = metadata
+	raw, err = json.Marshal(signed)
+	assert.NoError(t, err)
+	return string(raw), tx.Hash()
+}
+
+func TestBroadcastError(t *testing.T) {
+	tests := map[string]*types.Error{
+		"nonce too low: address 0x9670d6977d0b10130E5d4916c9134363281B6B0e, tx: 7 state: 8": ErrNonceTooLow,
+		"transaction underpriced":                              ErrTransactionUnderpriced,
+		"replacement transaction underpriced":                  ErrTransactionUnderpriced,
+		"insufficient funds for gas * price + value":           ErrInsufficientFunds,
+		"already known":                                        ErrBroadcastFailed,
+		"max fee per gas less than block base fee: address 0x": ErrBroadcastFailed,
+	}
+
+	for reason, expected := range tests {
+		t.Run(reason, func(t *testing.T) {
+			assert.Equal(t, templateError(expected, reason), broadcastError(errors.New(reason)))
+		})
+	}
+}
+
+func TestParseSubmitMetadata(t *testing.T) {
+	signed, _ := signedTransaction(t, nil)
+	submitMeta, err := parseSubmitMetadata(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, &submitMetadata{}, submitMeta)
+
+	signed, _ = signedTransaction(t, map[string]interface{}{"wait_for_inclusion": true})
+	submitMeta, err = parseSubmitMetadata(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, &submitMetadata{WaitForInclusion: true, InclusionLevel: optimism.LatestInclusion}, submitMeta)
+
+	signed, _ = signedTransaction(t, map[string]interface{}{"wait_for_inclusion": true, "inclusion_level": "finalized"})
+	submitMeta, err = parseSubmitMetadata(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, &submitMetadata{WaitForInclusion: true, InclusionLevel: optimism.FinalizedInclusion}, submitMeta)
+
+	signed, _ = signedTransaction(t, map[string]interface{}{"wait_for_inclusion": true, "inclusion_level": "unsafe"})
+	submitMeta, err = parseSubmitMetadata(signed)
+	assert.EqualError(t, err, "unsafe is not a valid inclusion level")
+	assert.Nil(t, submitMeta)
+}
+
+func TestConstructionSubmit_BroadcastError(t *testing.T) {
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(&configuration.Configuration{Mode: configuration.Online}, mockClient)
+	ctx := context.Background()
+
+	signed, _ := signedTransaction(t, nil)
+	submissions := []*optimism.SubmitResult{
+		{Endpoint: "https://sequencer.example.com", Error: "nonce too low"},
+		{Endpoint: "http://localhost:8545", Error: "already known"},
+	}
+	mockClient.On(
+		"SubmitTransaction", ctx, mock.Anything, (*optimism.TransactionConditional)(nil),
+	).Return(submissions, errors.New("nonce too low")).Once()
+
+	resp, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signed,
+	})
+	assert.Nil(t, resp)
+	expected := templateError(ErrNonceTooLow, "nonce too low")
+	expected.Details["submissions"] = submissions
+	assert.Equal(t, expected, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionSubmit_Conditional(t *testing.T) {
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(&configuration.Configuration{Mode: configuration.Online}, mockClient)
+	ctx := context.Background()
+
+	signed, hash := signedTransaction(t, map[string]interface{}{
+		"conditional": map[string]interface{}{
+			"knownAccounts": map[string]interface{}{
+				fromAddress: "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+			},
+			"blockNumberMax": "0x64",
+		},
+	})
+	root := common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	submissions := []*optimism.SubmitResult{{Endpoint: "https://sequencer.example.com"}}
+	mockClient.On(
+		"SubmitTransaction", ctx, mock.Anything, &optimism.TransactionConditional{
+			KnownAccounts: map[common.Address]optimism.KnownAccount{
+				common.HexToAddress(fromAddress): {StorageRoot: &root},
+			},
+			BlockNumberMax: (*hexutil.Big)(big.NewInt(100)),
+		},
+	).Return(submissions, nil).Once()
+
+	resp, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signed,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash.Hex()},
+		Metadata:              map[string]interface{}{"submissions": submissions},
+	}, resp)
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionSubmit_WaitForInclusion(t *testing.T) {
+	defer func(interval time.Duration) { inclusionPollInterval = interval }(inclusionPollInterval)
+	inclusionPollInterval = time.Millisecond
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(&configuration.Configuration{Mode: configuration.Online}, mockClient)
+	ctx := context.Background()
+
+	signed, hash := signedTransaction(t, map[string]interface{}{"wait_for_inclusion": true, "inclusion_level": "safe"})
+	submissions := []*optimism.SubmitResult{{Endpoint: "default"}}
+	mockClient.On("SubmitTransaction", ctx, mock.Anything, mock.Anything).Return(submissions, nil).Once()
+	mockClient.On(
+		"TransactionInclusion", mock.Anything, hash, optimism.SafeInclusion,
+	).Return(nil, nil).Once()
+	// A failed poll is retried rather than failing the submission
+	mockClient.On(
+		"TransactionInclusion", mock.Anything, hash, optimism.SafeInclusion,
+	).Return(nil, errors.New("connection refused")).Once()
+	mockClient.On(
+		"TransactionInclusion", mock.Anything, hash, optimism.SafeInclusion,
+	).Return(&optimism.Inclusion{
+		BlockIdentifier: &types.BlockIdentifier{
+			Hash:  "0x0b9e0e2d8b9b8b1aa0ff2a14ac8a4bcb0f1dca2a9ab4bf12c3c45c8e8a9e8e1c",
+			Index: 100,
+		},
+		Status:            optimism.SuccessStatus,
+		GasUsed:           21000,
+		EffectiveGasPrice: big.NewInt(1500),
+		Fee:               big.NewInt(31510000),
+	}, nil).Once()
+
+	resp, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signed,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash.Hex()},
+		Metadata: map[string]interface{}{
+			"block_identifier": &types.BlockIdentifier{
+				Hash:  "0x0b9e0e2d8b9b8b1aa0ff2a14ac8a4bcb0f1dca2a9ab4bf12c3c45c8e8a9e8e1c",
+				Index: 100,
+			},
+			"submissions":         submissions,
+			"included":            true,
+			"status":              optimism.SuccessStatus,
+			"gas_used":            "0x5208",
+			"effective_gas_price": "0x5dc",
+			"fee":                 "0x1e0cdf0",
+		},
+	}, resp)
+	mockClient.AssertExpectations(t)
+}
+
+func TestConstructionSubmit_InclusionTimeout(t *testing.T) {
+	defer func(interval time.Duration) { inclusionPollInterval = interval }(inclusionPollInterval)
+	inclusionPollInterval = time.Millisecond
+
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(&configuration.Configuration{
+		Mode:          configuration.Online,
+		SubmitTimeout: 20 * time.Millisecond,
+	}, mockClient)
+	ctx := context.Background()
+
+	signed, hash := signedTransaction(t, map[string]interface{}{"wait_for_inclusion": true})
+	submissions := []*optimism.SubmitResult{{Endpoint: "default"}}
+	mockClient.On("SubmitTransaction", ctx, mock.Anything, mock.Anything).Return(submissions, nil).Once()
+	mockClient.On("TransactionInclusion", mock.Anything, hash, optimism.LatestInclusion).Return(nil, nil)
+
+	// The broadcast transaction is still identified when it is not included in time
+	resp, err := servicer.ConstructionSubmit(ctx, &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signed,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash.Hex()},
+		Metadata: map[string]interface{}{
+			"submissions": submissions,
+			"included":    false,
+		},
+	}, resp)
+}
+
+func TestConstructionSubmit_InvalidInclusionLevel(t *testing.T) {
+	servicer := NewConstructionAPIService(&configuration.Configuration{Mode: configuration.Online}, &mocks.Client{})
+
+	signed, _ := signedTransaction(t, map[string]interface{}{"wait_for_inclusion": true, "inclusion_level": "unsafe"})
+	resp, err := servicer.ConstructionSubmit(context.Background(), &types.ConstructionSubmitRequest{
+		NetworkIdentifier: networkIdentifier,
+		SignedTransaction: signed,
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, templateError(ErrBadRequest, "unsafe is not a valid inclusion level"), err)
+}
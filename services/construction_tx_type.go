@@ -0,0 +1,161 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	gethParams "github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// LegacyTxType is the tx_type of pre-EIP-2718 transactions
+	LegacyTxType = "legacy"
+
+	// AccessListTxType is the tx_type of EIP-2930 transactions
+	AccessListTxType = "access_list"
+
+	// DynamicFeeTxType is the tx_type of EIP-1559 transactions
+	DynamicFeeTxType = "dynamic_fee"
+)
+
+// txTypes maps each supported tx_type to its EIP-2718 transaction type
+var txTypes = map[string]uint8{
+	LegacyTxType:     ethTypes.LegacyTxType,
+	AccessListTxType: ethTypes.AccessListTxType,
+	DynamicFeeTxType: ethTypes.DynamicFeeTxType,
+}
+
+// txTypeName returns the tx_type of an EIP-2718 transaction type
+func txTypeName(txType uint8) string {
+	for name, t := range txTypes {
+		if t == txType {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseTxTypeOverrides sets the tx_type and access list of opts that are
+// provided in the metadata of a /construction/preprocess request, and
+// validates them against the gas parameters of opts
+func parseTxTypeOverrides(metadata map[string]interface{}, opts *options) *types.Error {
+	if v, ok := metadata["tx_type"]; ok {
+		txType, ok := v.(string)
+		if !ok {
+			return wrapErr(ErrInvalidTxType, fmt.Errorf("%v is not a valid tx_type string", v))
+		}
+		if _, ok := txTypes[txType]; !ok {
+			return wrapErr(ErrInvalidTxType, fmt.Errorf("%s is not a supported tx_type", txType))
+		}
+		opts.TxType = txType
+	}
+
+	if v, ok := metadata["access_list"]; ok {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return wrapErr(ErrInvalidAccessList, err)
+		}
+		var accessList ethTypes.AccessList
+		if err := json.Unmarshal(raw, &accessList); err != nil {
+			return wrapErr(ErrInvalidAccessList, err)
+		}
+		opts.AccessList = accessList
+	}
+
+	if v, ok := metadata["create_access_list"]; ok {
+		createAccessList, ok := v.(bool)
+		if !ok {
+			return wrapErr(ErrInvalidAccessList, fmt.Errorf("%v is not a valid create_access_list bool", v))
+		}
+		opts.CreateAccessList = createAccessList
+	}
+
+	if err := validateTxType(opts); err != nil {
+		return wrapErr(ErrInvalidTxType, err)
+	}
+	return nil
+}
+
+// validateTxType checks that the gas parameters and access list of opts
+// can be set on a transaction of its tx_type
+//
+//nolint:goerr113
+func validateTxType(opts *options) error {
+	hasAccessList := opts.AccessList != nil || opts.CreateAccessList
+	if opts.AccessList != nil && opts.CreateAccessList {
+		return fmt.Errorf("access_list and create_access_list are mutually exclusive")
+	}
+
+	switch opts.TxType {
+	case "":
+		if hasAccessList {
+			return fmt.Errorf("tx_type must be provided along with an access list")
+		}
+	case LegacyTxType:
+		if hasAccessList {
+			return fmt.Errorf("%s transactions do not support access lists", opts.TxType)
+		}
+		fallthrough
+	case AccessListTxType:
+		if opts.GasTipCap != nil || opts.GasFeeCap != nil {
+			return fmt.Errorf("%s transactions do not support gas tip and fee caps", opts.TxType)
+		}
+	}
+
+	return nil
+}
+
+// accessListGas returns the intrinsic gas charged for an access list
+func accessListGas(accessList ethTypes.AccessList) uint64 {
+	return uint64(len(accessList))*gethParams.TxAccessListAddressGas +
+		uint64(accessList.StorageKeys())*gethParams.TxAccessListStorageKeyGas
+}
+
+// calculateAccessList generates the access list of a transaction. An empty to
+// generates the access list of a contract deployment.
+func (s *ConstructionAPIService) calculateAccessList(
+	ctx context.Context,
+	from string,
+	to string,
+	data []byte,
+	value *big.Int,
+) (ethTypes.AccessList, *types.Error) {
+	msg := ethereum.CallMsg{
+		From:  common.HexToAddress(from),
+		Data:  data,
+		Value: value,
+	}
+	if len(to) > 0 {
+		toAddress := common.HexToAddress(to)
+		msg.To = &toAddress
+	}
+
+	accessList, _, err := s.client.CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, wrapErr(ErrInvalidAccessList, err)
+	}
+	if accessList == nil {
+		return ethTypes.AccessList{}, nil
+	}
+	return *accessList, nil
+}
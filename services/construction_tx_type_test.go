@@ -0,0 +1,329 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum-optimism/optimism/l2geth/params"
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	accessListAddress = common.HexToAddress("0x2d7882beDcbfDDce29Ba99965dd3cdF7fcB10A1e")
+	accessListKey     = common.HexToHash("0x01")
+	accessList        = ethTypes.AccessList{
+		{Address: accessListAddress, StorageKeys: []common.Hash{accessListKey}},
+	}
+	accessListRaw = []interface{}{
+		map[string]interface{}{
+			"address":     strings.ToLower(accessListAddress.Hex()),
+			"storageKeys": []interface{}{accessListKey.Hex()},
+		},
+	}
+)
+
+func transferOperations(from string, to string, value string) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                optimism.CallOpType,
+			Account:             &types.AccountIdentifier{Address: from},
+			Amount:              &types.Amount{Value: "-" + value, Currency: optimism.Currency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                optimism.CallOpType,
+			Account:             &types.AccountIdentifier{Address: to},
+			Amount:              &types.Amount{Value: value, Currency: optimism.Currency},
+		},
+	}
+}
+
+func TestValidateTxType(t *testing.T) {
+	tests := map[string]struct {
+		opts *options
+		err  string
+	}{
+		"implied type": {
+			opts: &options{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2)},
+		},
+		"legacy": {
+			opts: &options{TxType: LegacyTxType, GasPrice: big.NewInt(1)},
+		},
+		"access list": {
+			opts: &options{TxType: AccessListTxType, AccessList: accessList},
+		},
+		"dynamic fee with generated access list": {
+			opts: &options{TxType: DynamicFeeTxType, CreateAccessList: true},
+		},
+		"access list without tx_type": {
+			opts: &options{AccessList: accessList},
+			err:  "tx_type must be provided along with an access list",
+		},
+		"legacy with access list": {
+			opts: &options{TxType: LegacyTxType, CreateAccessList: true},
+			err:  "legacy transactions do not support access lists",
+		},
+		"legacy with fee caps": {
+			opts: &options{TxType: LegacyTxType, GasFeeCap: big.NewInt(2)},
+			err:  "legacy transactions do not support gas tip and fee caps",
+		},
+		"access list with fee caps": {
+			opts: &options{TxType: AccessListTxType, GasTipCap: big.NewInt(1)},
+			err:  "access_list transactions do not support gas tip and fee caps",
+		},
+		"provided and generated access list": {
+			opts: &options{TxType: AccessListTxType, AccessList: accessList, CreateAccessList: true},
+			err:  "access_list and create_access_list are mutually exclusive",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateTxType(test.opts)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestPreprocessTxTypeOverrides(t *testing.T) {
+	servicer := NewConstructionAPIService(&configuration.Configuration{}, &mocks.Client{})
+	ops := transferOperations(fromAddress, toAddress, "1000")
+
+	tests := map[string]struct {
+		metadata map[string]interface{}
+		err      *types.Error
+	}{
+		"unsupported tx_type": {
+			metadata: map[string]interface{}{"tx_type": "blob"},
+			err:      ErrInvalidTxType,
+		},
+		"malformed access list": {
+			metadata: map[string]interface{}{"tx_type": AccessListTxType, "access_list": "0x1"},
+			err:      ErrInvalidAccessList,
+		},
+		"malformed create_access_list": {
+			metadata: map[string]interface{}{"tx_type": AccessListTxType, "create_access_list": "true"},
+			err:      ErrInvalidAccessList,
+		},
+		"legacy with fee caps": {
+			metadata: map[string]interface{}{"tx_type": LegacyTxType, "gas_fee_cap": "100"},
+			err:      ErrInvalidTxType,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := servicer.ConstructionPreprocess(context.Background(), &types.ConstructionPreprocessRequest{
+				NetworkIdentifier: networkIdentifier,
+				Operations:        ops,
+				Metadata:          test.metadata,
+			})
+			assert.Nil(t, resp)
+			assert.Equal(t, test.err.Code, err.Code)
+		})
+	}
+}
+
+func TestConstructionAccessList(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewConstructionAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	ops := transferOperations(from.Hex(), toAddress, "1000")
+
+	// Test Preprocess
+	preprocessResponse, rErr := servicer.ConstructionPreprocess(ctx, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata: map[string]interface{}{
+			"tx_type":     AccessListTxType,
+			"access_list": accessListRaw,
+		},
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, AccessListTxType, preprocessResponse.Options["tx_type"])
+	assert.Equal(t, accessListRaw, preprocessResponse.Options["access_list"])
+
+	// Test Metadata
+	mockClient.On("PendingNonceAt", ctx, from).Return(uint64(3), nil).Once()
+	mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+	metadataResponse, rErr := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           preprocessResponse.Options,
+	})
+	assert.Nil(t, rErr)
+	// The transfer gas limit is raised by the intrinsic gas of the access list
+	assert.Equal(t, "0x62d4", metadataResponse.Metadata["gas_limit"])
+	assert.Equal(t, AccessListTxType, metadataResponse.Metadata["tx_type"])
+	assert.Equal(t, accessListRaw, metadataResponse.Metadata["access_list"])
+	assert.NotContains(t, metadataResponse.Metadata, "gas_fee_cap")
+
+	// Test Payloads
+	payloadsResponse, rErr := servicer.ConstructionPayloads(ctx, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: networkIdentifier,
+		Operations:        ops,
+		Metadata:          metadataResponse.Metadata,
+	})
+	assert.Nil(t, rErr)
+	assert.Len(t, payloadsResponse.Payloads, 1)
+
+	// Test Parse Unsigned
+	parseResponse, rErr := servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            false,
+		Transaction:       payloadsResponse.UnsignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, AccessListTxType, parseResponse.Metadata["tx_type"])
+	assert.Equal(t, accessListRaw, parseResponse.Metadata["access_list"])
+
+	// Test Combine
+	signature, err := crypto.Sign(payloadsResponse.Payloads[0].Bytes, key)
+	assert.NoError(t, err)
+	combineResponse, rErr := servicer.ConstructionCombine(ctx, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   networkIdentifier,
+		UnsignedTransaction: payloadsResponse.UnsignedTransaction,
+		Signatures: []*types.Signature{
+			{
+				SigningPayload: payloadsResponse.Payloads[0],
+				SignatureType:  types.EcdsaRecovery,
+				Bytes:          signature,
+			},
+		},
+	})
+	assert.Nil(t, rErr)
+
+	// Test Parse Signed
+	parseResponse, rErr = servicer.ConstructionParse(ctx, &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            true,
+		Transaction:       combineResponse.SignedTransaction,
+	})
+	assert.Nil(t, rErr)
+	assert.Equal(t, []*types.AccountIdentifier{{Address: from.Hex()}}, parseResponse.AccountIdentifierSigners)
+	assert.Equal(t, AccessListTxType, parseResponse.Metadata["tx_type"])
+	assert.Equal(t, accessListRaw, parseResponse.Metadata["access_list"])
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestMetadata_CreateAccessList(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:    configuration.Online,
+		Network: networkIdentifier,
+		Params:  params.TestnetChainConfig,
+	}
+	ctx := context.Background()
+	from := common.HexToAddress(fromAddress)
+	to := common.HexToAddress(toAddress)
+	opts := map[string]interface{}{
+		"from":               fromAddress,
+		"to":                 toAddress,
+		"value":              "0x3e8",
+		"tx_type":            DynamicFeeTxType,
+		"create_access_list": true,
+	}
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: big.NewInt(1000),
+	}
+	estimateMsg := msg
+	estimateMsg.AccessList = accessList
+
+	t.Run("generated access list", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingNonceAt", ctx, from).Return(uint64(0), nil).Once()
+		mockClient.On("CreateAccessList", ctx, msg).Return(&accessList, uint64(30000), nil).Once()
+		mockClient.On("EstimateGas", ctx, estimateMsg).Return(uint64(33000), nil).Once()
+		mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+		mockClient.On("BaseFee", ctx).Return(big.NewInt(200), nil).Once()
+		mockClient.On("SuggestGasTipCap", ctx).Return(big.NewInt(10), nil).Once()
+
+		resp, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           opts,
+		})
+		assert.Nil(t, err)
+		// The gas estimated with the access list applied replaces the transfer gas limit,
+		// rather than the gas used reported by eth_createAccessList
+		assert.Equal(t, "0x80e8", resp.Metadata["gas_limit"])
+		assert.Equal(t, "0x19a", resp.Metadata["gas_fee_cap"])
+		assert.Equal(t, DynamicFeeTxType, resp.Metadata["tx_type"])
+		assert.Equal(t, accessListRaw, resp.Metadata["access_list"])
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("access list generation failure", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingNonceAt", ctx, from).Return(uint64(0), nil).Once()
+		mockClient.On("CreateAccessList", ctx, msg).Return(nil, uint64(0), errors.New("execution reverted")).Once()
+
+		resp, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           opts,
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, templateError(ErrInvalidAccessList, "execution reverted"), err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("dynamic fee without fee caps", func(t *testing.T) {
+		mockClient := &mocks.Client{}
+		servicer := NewConstructionAPIService(cfg, mockClient)
+		mockClient.On("PendingNonceAt", ctx, from).Return(uint64(0), nil).Once()
+		mockClient.On("CreateAccessList", ctx, msg).Return(&accessList, uint64(30000), nil).Once()
+		mockClient.On("EstimateGas", ctx, estimateMsg).Return(uint64(33000), nil).Once()
+		mockClient.On("SuggestGasPrice", ctx).Return(big.NewInt(1000000000), nil).Once()
+		mockClient.On("BaseFee", ctx).Return(nil, nil).Once()
+
+		resp, err := servicer.ConstructionMetadata(ctx, &types.ConstructionMetadataRequest{
+			NetworkIdentifier: networkIdentifier,
+			Options:           opts,
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, ErrInvalidTxType.Code, err.Code)
+		mockClient.AssertExpectations(t)
+	})
+}
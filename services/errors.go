@@ -48,6 +48,18 @@ var (
 		ErrInvalidGasTipCap,
 		ErrInvalidGasFeeCap,
 		ErrL1DataFee,
+		ErrTransactionNotFound,
+		ErrInvalidTxType,
+		ErrInvalidAccessList,
+		ErrInsufficientAllowance,
+		ErrInvalidReplacement,
+		ErrSimulationUnavailable,
+		ErrNonceTooLow,
+		ErrTransactionUnderpriced,
+		ErrInsufficientFunds,
+		ErrMissingOfflineMetadata,
+		ErrInvalidChainID,
+		ErrStateUnavailable,
 	}
 
 	// ErrUnimplemented is returned when an endpoint
@@ -232,6 +244,91 @@ var (
 		Code:    25, //nolint
 		Message: "Failed to get L1 data fee",
 	}
+
+	// ErrTransactionNotFound is returned when a requested
+	// transaction could not be found.
+	ErrTransactionNotFound = &types.Error{
+		Code:    26, //nolint
+		Message: "Transaction not found",
+	}
+
+	// ErrInvalidTxType is returned when the tx_type of a transaction
+	// is unsupported or conflicts with its gas parameters.
+	ErrInvalidTxType = &types.Error{
+		Code:    27, //nolint
+		Message: "Transaction type invalid",
+	}
+
+	// ErrInvalidAccessList is returned when the access list
+	// of a transaction cannot be parsed or generated.
+	ErrInvalidAccessList = &types.Error{
+		Code:    28, //nolint
+		Message: "Access list invalid",
+	}
+
+	// ErrInsufficientAllowance is returned when an ERC20 transferFrom
+	// exceeds the allowance granted by the token owner to the spender.
+	ErrInsufficientAllowance = &types.Error{
+		Code:    29, //nolint
+		Message: "Insufficient allowance",
+	}
+
+	// ErrInvalidReplacement is returned when the transaction to replace
+	// is not pending, was sent by another account or has another intent.
+	ErrInvalidReplacement = &types.Error{
+		Code:    30, //nolint
+		Message: "Transaction replacement invalid",
+	}
+
+	// ErrSimulationUnavailable is returned when a transaction cannot be
+	// simulated, such as when the node does not expose the debug namespace.
+	ErrSimulationUnavailable = &types.Error{
+		Code:    31, //nolint
+		Message: "Unable to simulate transaction",
+	}
+
+	// ErrNonceTooLow is returned when geth rejects a transaction
+	// because its nonce has already been used by the sender.
+	ErrNonceTooLow = &types.Error{
+		Code:    32, //nolint
+		Message: "Nonce too low",
+	}
+
+	// ErrTransactionUnderpriced is returned when geth rejects a transaction
+	// whose fees are too low to enter the pool or to replace a pending transaction.
+	ErrTransactionUnderpriced = &types.Error{
+		Code:    33, //nolint
+		Message: "Transaction underpriced",
+	}
+
+	// ErrInsufficientFunds is returned when geth rejects a transaction
+	// because the sender cannot pay for its value and fees.
+	ErrInsufficientFunds = &types.Error{
+		Code:    34, //nolint
+		Message: "Insufficient funds",
+	}
+
+	// ErrMissingOfflineMetadata is returned when /construction/metadata is
+	// called offline without a value it would otherwise fetch from geth.
+	ErrMissingOfflineMetadata = &types.Error{
+		Code:    36, //nolint
+		Message: "Metadata required in offline mode is missing",
+	}
+
+	// ErrInvalidChainID is returned when the chain ID of a transaction
+	// is malformed or does not match the configured network.
+	ErrInvalidChainID = &types.Error{
+		Code:    37, //nolint
+		Message: "Chain ID invalid",
+	}
+
+	// ErrStateUnavailable is returned when the node no longer has the
+	// state of the requested block, such as a non-archive node that has
+	// pruned it.
+	ErrStateUnavailable = &types.Error{
+		Code:    38, //nolint
+		Message: "State unavailable",
+	}
 )
 
 // wrapErr adds details to the types.Error provided. We use a function
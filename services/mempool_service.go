@@ -16,18 +16,30 @@ package services
 
 import (
 	"context"
+	"errors"
 
 	"github.com/coinbase/rosetta-sdk-go/server"
 	"github.com/coinbase/rosetta-sdk-go/types"
+
+	"github.com/inphi/optimism-rosetta/configuration"
+	"github.com/inphi/optimism-rosetta/optimism"
 )
 
 // MempoolAPIService implements the server.MempoolAPIServicer interface.
 type MempoolAPIService struct {
+	config *configuration.Configuration
+	client Client
 }
 
 // NewMempoolAPIService creates a new instance of a MempoolAPIService.
-func NewMempoolAPIService() server.MempoolAPIServicer {
-	return &MempoolAPIService{}
+func NewMempoolAPIService(
+	cfg *configuration.Configuration,
+	client Client,
+) server.MempoolAPIServicer {
+	return &MempoolAPIService{
+		config: cfg,
+		client: client,
+	}
 }
 
 // Mempool implements the /mempool endpoint.
@@ -35,7 +47,21 @@ func (s *MempoolAPIService) Mempool(
 	ctx context.Context,
 	request *types.NetworkRequest,
 ) (*types.MempoolResponse, *types.Error) {
-	return nil, wrapErr(ErrUnimplemented, nil)
+	if !s.config.EnableMempool {
+		return nil, wrapErr(ErrUnimplemented, nil)
+	}
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	identifiers, err := s.client.Mempool(ctx)
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	return &types.MempoolResponse{
+		TransactionIdentifiers: identifiers,
+	}, nil
 }
 
 // MempoolTransaction implements the /mempool/transaction endpoint.
@@ -43,5 +69,22 @@ func (s *MempoolAPIService) MempoolTransaction(
 	ctx context.Context,
 	request *types.MempoolTransactionRequest,
 ) (*types.MempoolTransactionResponse, *types.Error) {
-	return nil, wrapErr(ErrUnimplemented, nil)
+	if !s.config.EnableMempool {
+		return nil, wrapErr(ErrUnimplemented, nil)
+	}
+	if s.config.Mode != configuration.Online {
+		return nil, ErrUnavailableOffline
+	}
+
+	tx, err := s.client.MempoolTransaction(ctx, request.TransactionIdentifier.Hash)
+	if errors.Is(err, optimism.ErrTransactionNotFound) {
+		return nil, wrapErr(ErrTransactionNotFound, err)
+	}
+	if err != nil {
+		return nil, wrapErr(ErrGeth, err)
+	}
+
+	return &types.MempoolTransactionResponse{
+		Transaction: tx,
+	}, nil
 }
@@ -16,13 +16,23 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"github.com/inphi/optimism-rosetta/configuration"
+	mocks "github.com/inphi/optimism-rosetta/mocks/services"
+	"github.com/inphi/optimism-rosetta/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMempoolEndpoints(t *testing.T) {
-	servicer := NewMempoolAPIService()
+	cfg := &configuration.Configuration{
+		Mode: configuration.Online,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewMempoolAPIService(cfg, mockClient)
 	ctx := context.Background()
 
 	mem, err := servicer.Mempool(ctx, nil)
@@ -34,4 +44,81 @@ func TestMempoolEndpoints(t *testing.T) {
 	assert.Nil(t, memTransaction)
 	assert.Equal(t, ErrUnimplemented.Code, err.Code)
 	assert.Equal(t, ErrUnimplemented.Message, err.Message)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestMempoolService_Offline(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:          configuration.Offline,
+		EnableMempool: true,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewMempoolAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	mem, err := servicer.Mempool(ctx, &types.NetworkRequest{})
+	assert.Nil(t, mem)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{})
+	assert.Nil(t, memTransaction)
+	assert.Equal(t, ErrUnavailableOffline.Code, err.Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestMempoolService_Online(t *testing.T) {
+	cfg := &configuration.Configuration{
+		Mode:          configuration.Online,
+		EnableMempool: true,
+	}
+	mockClient := &mocks.Client{}
+	servicer := NewMempoolAPIService(cfg, mockClient)
+	ctx := context.Background()
+
+	t.Run("mempool", func(t *testing.T) {
+		identifiers := []*types.TransactionIdentifier{
+			{Hash: "0x1"},
+			{Hash: "0x2"},
+		}
+		mockClient.On("Mempool", ctx).Return(identifiers, nil).Once()
+
+		mem, err := servicer.Mempool(ctx, &types.NetworkRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.MempoolResponse{TransactionIdentifiers: identifiers}, mem)
+	})
+
+	t.Run("mempool geth error", func(t *testing.T) {
+		mockClient.On("Mempool", ctx).Return(nil, fmt.Errorf("connection refused")).Once()
+
+		mem, err := servicer.Mempool(ctx, &types.NetworkRequest{})
+		assert.Nil(t, mem)
+		assert.Equal(t, ErrGeth.Code, err.Code)
+	})
+
+	t.Run("mempool transaction", func(t *testing.T) {
+		tx := &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "0x1"},
+		}
+		mockClient.On("MempoolTransaction", ctx, "0x1").Return(tx, nil).Once()
+
+		memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "0x1"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, &types.MempoolTransactionResponse{Transaction: tx}, memTransaction)
+	})
+
+	t.Run("mempool transaction not found", func(t *testing.T) {
+		mockClient.On("MempoolTransaction", ctx, "0x2").Return(nil, optimism.ErrTransactionNotFound).Once()
+
+		memTransaction, err := servicer.MempoolTransaction(ctx, &types.MempoolTransactionRequest{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "0x2"},
+		})
+		assert.Nil(t, memTransaction)
+		assert.Equal(t, ErrTransactionNotFound.Code, err.Code)
+	})
+
+	mockClient.AssertExpectations(t)
 }
@@ -30,6 +30,16 @@ func NewBlockchainRouter(
 	client Client,
 	asserter *asserter.Asserter,
 ) http.Handler {
+	return server.NewRouter(NewBlockchainControllers(config, client, asserter)...)
+}
+
+// NewBlockchainControllers creates the server controllers of every
+// Rosetta endpoint.
+func NewBlockchainControllers(
+	config *configuration.Configuration,
+	client Client,
+	asserter *asserter.Asserter,
+) []server.Router {
 	networkAPIService := NewNetworkAPIService(config, client)
 	networkAPIController := server.NewNetworkAPIController(
 		networkAPIService,
@@ -54,7 +64,7 @@ func NewBlockchainRouter(
 		asserter,
 	)
 
-	mempoolAPIService := NewMempoolAPIService()
+	mempoolAPIService := NewMempoolAPIService(config, client)
 	mempoolAPIController := server.NewMempoolAPIController(
 		mempoolAPIService,
 		asserter,
@@ -66,12 +76,12 @@ func NewBlockchainRouter(
 		asserter,
 	)
 
-	return server.NewRouter(
+	return []server.Router{
 		networkAPIController,
 		accountAPIController,
 		blockAPIController,
 		constructionAPIController,
 		mempoolAPIController,
 		callAPIController,
-	)
+	}
 }
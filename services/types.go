@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"math/big"
 
+	"github.com/inphi/optimism-rosetta/optimism"
+
 	"github.com/coinbase/rosetta-sdk-go/types"
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -42,6 +44,17 @@ type Client interface {
 		*types.PartialBlockIdentifier,
 	) (*types.Block, error)
 
+	BlockWithOtherTransactions(
+		context.Context,
+		*types.PartialBlockIdentifier,
+	) (*types.Block, []*types.TransactionIdentifier, error)
+
+	BlockTransaction(
+		context.Context,
+		*types.BlockIdentifier,
+		*types.TransactionIdentifier,
+	) (*types.Transaction, error)
+
 	Balance(
 		context.Context,
 		*types.AccountIdentifier,
@@ -53,6 +66,8 @@ type Client interface {
 
 	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
 
+	CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (*ethTypes.AccessList, uint64, error)
+
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
@@ -61,6 +76,12 @@ type Client interface {
 
 	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
 
+	SubmitTransaction(
+		ctx context.Context,
+		tx *ethTypes.Transaction,
+		conditional *optimism.TransactionConditional,
+	) ([]*optimism.SubmitResult, error)
+
 	Call(
 		ctx context.Context,
 		request *types.CallRequest,
@@ -77,6 +98,18 @@ type Client interface {
 	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethTypes.Log, error)
 
 	SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- ethTypes.Log) (ethereum.Subscription, error)
+
+	Mempool(ctx context.Context) ([]*types.TransactionIdentifier, error)
+
+	MempoolTransaction(ctx context.Context, hash string) (*types.Transaction, error)
+
+	PendingTransaction(ctx context.Context, hash string) (*ethTypes.Transaction, common.Address, error)
+
+	SimulateTransaction(ctx context.Context, msg ethereum.CallMsg) (*optimism.SimulationResult, error)
+
+	TransactionInclusion(ctx context.Context, hash common.Hash, level string) (*optimism.Inclusion, error)
+
+	TokenCurrency(ctx context.Context, contractAddress string) (*types.Currency, error)
 }
 
 // Nonce is a *big.Int so that its value can be checked against nil
@@ -84,7 +117,8 @@ type Client interface {
 // its nil value will be 0 which is a valid nonce. This will cause
 // ConstructionMetadata to make an extra call to eth_getTransactionCount
 //
-// Value will always be 0 for ERC20 tokens
+// Value will always be 0 for ERC20 tokens. Create is set for contract
+// deployments, which have no To address.
 type options struct {
 	From            string      `json:"from"`
 	Nonce           *big.Int    `json:"nonce,omitempty"`
@@ -99,6 +133,27 @@ type options struct {
 	GasLimit        *big.Int    `json:"gas_limit,omitempty"`
 	MethodSignature string      `json:"method_signature,omitempty"`
 	MethodArgs      interface{} `json:"method_args,omitempty"`
+	Create          bool        `json:"create,omitempty"`
+
+	// Owner and Spender are set for ERC20 allowance intents, whose
+	// current allowance is reported by /construction/metadata
+	Owner   string `json:"owner,omitempty"`
+	Spender string `json:"spender,omitempty"`
+
+	// ReplaceTxHash is the hash of the pending transaction that is sped up or,
+	// if Cancel is set, cancelled by the transaction being constructed
+	ReplaceTxHash string `json:"replace_tx_hash,omitempty"`
+	Cancel        bool   `json:"cancel,omitempty"`
+
+	// Simulate predicts the outcome of the transaction in /construction/metadata
+	Simulate bool `json:"simulate,omitempty"`
+
+	TxType           string              `json:"tx_type,omitempty"`
+	AccessList       ethTypes.AccessList `json:"access_list,omitempty"`
+	CreateAccessList bool                `json:"create_access_list,omitempty"`
+
+	// ChainID is supplied by callers constructing transactions offline
+	ChainID *big.Int `json:"chain_id,omitempty"`
 }
 
 type optionsWire struct {
@@ -115,6 +170,21 @@ type optionsWire struct {
 	GasLimit        string      `json:"gas_limit,omitempty"`
 	MethodSignature string      `json:"method_signature,omitempty"`
 	MethodArgs      interface{} `json:"method_args,omitempty"`
+	Create          bool        `json:"create,omitempty"`
+
+	Owner   string `json:"owner,omitempty"`
+	Spender string `json:"spender,omitempty"`
+
+	ReplaceTxHash string `json:"replace_tx_hash,omitempty"`
+	Cancel        bool   `json:"cancel,omitempty"`
+
+	Simulate bool `json:"simulate,omitempty"`
+
+	TxType           string              `json:"tx_type,omitempty"`
+	AccessList       ethTypes.AccessList `json:"access_list,omitempty"`
+	CreateAccessList bool                `json:"create_access_list,omitempty"`
+
+	ChainID string `json:"chain_id,omitempty"`
 }
 
 func (o *options) MarshalJSON() ([]byte, error) {
@@ -125,6 +195,16 @@ func (o *options) MarshalJSON() ([]byte, error) {
 		MethodSignature: o.MethodSignature,
 		MethodArgs:      o.MethodArgs,
 		TokenAddress:    o.TokenAddress,
+		Create:          o.Create,
+		Owner:           o.Owner,
+		Spender:         o.Spender,
+		ReplaceTxHash:   o.ReplaceTxHash,
+		Cancel:          o.Cancel,
+		Simulate:        o.Simulate,
+
+		TxType:           o.TxType,
+		AccessList:       o.AccessList,
+		CreateAccessList: o.CreateAccessList,
 	}
 
 	if o.Nonce != nil {
@@ -155,6 +235,10 @@ func (o *options) MarshalJSON() ([]byte, error) {
 		ow.GasLimit = hexutil.EncodeBig(o.GasLimit)
 	}
 
+	if o.ChainID != nil {
+		ow.ChainID = hexutil.EncodeBig(o.ChainID)
+	}
+
 	return json.Marshal(ow)
 }
 
@@ -169,6 +253,15 @@ func (o *options) UnmarshalJSON(data []byte) error {
 	o.ContractAddress = ow.ContractAddress
 	o.MethodSignature = ow.MethodSignature
 	o.MethodArgs = ow.MethodArgs
+	o.Create = ow.Create
+	o.Owner = ow.Owner
+	o.Spender = ow.Spender
+	o.ReplaceTxHash = ow.ReplaceTxHash
+	o.Cancel = ow.Cancel
+	o.Simulate = ow.Simulate
+	o.TxType = ow.TxType
+	o.AccessList = ow.AccessList
+	o.CreateAccessList = ow.CreateAccessList
 
 	if len(ow.Nonce) > 0 {
 		nonce, err := hexutil.DecodeBig(ow.Nonce)
@@ -226,6 +319,14 @@ func (o *options) UnmarshalJSON(data []byte) error {
 		o.GasLimit = gasLimit
 	}
 
+	if len(ow.ChainID) > 0 {
+		chainID, err := hexutil.DecodeBig(ow.ChainID)
+		if err != nil {
+			return err
+		}
+		o.ChainID = chainID
+	}
+
 	return nil
 }
 
@@ -241,6 +342,17 @@ type metadata struct {
 	MethodSignature string      `json:"method_signature,omitempty"`
 	MethodArgs      interface{} `json:"method_args,omitempty"`
 	L1DataFee       *big.Int    `json:"l1_data_fee,omitempty"`
+	L2ExecutionFee  *big.Int    `json:"l2_execution_fee,omitempty"`
+	Allowance       *big.Int    `json:"allowance,omitempty"`
+	Cancel          bool        `json:"cancel,omitempty"`
+
+	TxType     string              `json:"tx_type,omitempty"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
+
+	Simulation *optimism.SimulationResult `json:"simulation,omitempty"`
+
+	// ChainID is only set on metadata constructed offline
+	ChainID *big.Int `json:"chain_id,omitempty"`
 }
 
 type metadataWire struct {
@@ -255,6 +367,16 @@ type metadataWire struct {
 	MethodSignature string      `json:"method_signature,omitempty"`
 	MethodArgs      interface{} `json:"method_args,omitempty"`
 	L1DataFee       string      `json:"l1_data_fee,omitempty"`
+	L2ExecutionFee  string      `json:"l2_execution_fee,omitempty"`
+	Allowance       string      `json:"allowance,omitempty"`
+	Cancel          bool        `json:"cancel,omitempty"`
+
+	TxType     string              `json:"tx_type,omitempty"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
+
+	Simulation *optimism.SimulationResult `json:"simulation,omitempty"`
+
+	ChainID string `json:"chain_id,omitempty"`
 }
 
 func (m *metadata) MarshalJSON() ([]byte, error) {
@@ -264,6 +386,10 @@ func (m *metadata) MarshalJSON() ([]byte, error) {
 		To:              m.To,
 		MethodSignature: m.MethodSignature,
 		MethodArgs:      m.MethodArgs,
+		Cancel:          m.Cancel,
+		Simulation:      m.Simulation,
+		TxType:          m.TxType,
+		AccessList:      m.AccessList,
 	}
 	if m.GasLimit > 0 {
 		mw.GasLimit = hexutil.Uint64(m.GasLimit).String()
@@ -283,6 +409,15 @@ func (m *metadata) MarshalJSON() ([]byte, error) {
 	if m.L1DataFee != nil {
 		mw.L1DataFee = hexutil.EncodeBig(m.L1DataFee)
 	}
+	if m.L2ExecutionFee != nil {
+		mw.L2ExecutionFee = hexutil.EncodeBig(m.L2ExecutionFee)
+	}
+	if m.Allowance != nil {
+		mw.Allowance = hexutil.EncodeBig(m.Allowance)
+	}
+	if m.ChainID != nil {
+		mw.ChainID = hexutil.EncodeBig(m.ChainID)
+	}
 
 	return json.Marshal(mw)
 }
@@ -308,6 +443,10 @@ func (m *metadata) UnmarshalJSON(data []byte) error {
 	m.To = mw.To
 	m.MethodSignature = mw.MethodSignature
 	m.MethodArgs = mw.MethodArgs
+	m.Cancel = mw.Cancel
+	m.Simulation = mw.Simulation
+	m.TxType = mw.TxType
+	m.AccessList = mw.AccessList
 
 	if len(mw.GasLimit) > 0 {
 		gasLimit, err := hexutil.DecodeUint64(mw.GasLimit)
@@ -357,6 +496,30 @@ func (m *metadata) UnmarshalJSON(data []byte) error {
 		m.L1DataFee = l1DataFee
 	}
 
+	if len(mw.L2ExecutionFee) > 0 {
+		l2ExecutionFee, err := hexutil.DecodeBig(mw.L2ExecutionFee)
+		if err != nil {
+			return err
+		}
+		m.L2ExecutionFee = l2ExecutionFee
+	}
+
+	if len(mw.Allowance) > 0 {
+		allowance, err := hexutil.DecodeBig(mw.Allowance)
+		if err != nil {
+			return err
+		}
+		m.Allowance = allowance
+	}
+
+	if len(mw.ChainID) > 0 {
+		chainID, err := hexutil.DecodeBig(mw.ChainID)
+		if err != nil {
+			return err
+		}
+		m.ChainID = chainID
+	}
+
 	return nil
 }
 
@@ -367,6 +530,12 @@ type parseMetadata struct {
 	GasFeeCap *big.Int `json:"gas_fee_cap,omitempty"`
 	GasLimit  uint64   `json:"gas_limit"`
 	ChainID   *big.Int `json:"chain_id"`
+
+	// ContractAddress is the address of the contract deployed by a contract creation
+	ContractAddress string `json:"contract_address,omitempty"`
+
+	TxType     string              `json:"tx_type"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
 }
 
 type parseMetadataWire struct {
@@ -376,6 +545,11 @@ type parseMetadataWire struct {
 	GasFeeCap string `json:"gas_fee_cap,omitempty"`
 	GasLimit  string `json:"gas_limit"`
 	ChainID   string `json:"chain_id"`
+
+	ContractAddress string `json:"contract_address,omitempty"`
+
+	TxType     string              `json:"tx_type"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
 }
 
 func (p *parseMetadata) MarshalJSON() ([]byte, error) {
@@ -384,6 +558,11 @@ func (p *parseMetadata) MarshalJSON() ([]byte, error) {
 		GasPrice: hexutil.EncodeBig(p.GasPrice),
 		GasLimit: hexutil.Uint64(p.GasLimit).String(),
 		ChainID:  hexutil.EncodeBig(p.ChainID),
+
+		ContractAddress: p.ContractAddress,
+
+		TxType:     p.TxType,
+		AccessList: p.AccessList,
 	}
 	if p.GasTipCap != nil {
 		pmw.GasTipCap = hexutil.EncodeBig(p.GasTipCap)
@@ -406,6 +585,13 @@ type transaction struct {
 	GasFeeCap *big.Int `json:"gas_fee_cap,omitempty"`
 	GasLimit  uint64   `json:"gas"`
 	ChainID   *big.Int `json:"chain_id"`
+
+	TxType     string              `json:"tx_type,omitempty"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
+
+	// Currency is the token of an ERC20 allowance intent, so that
+	// /construction/parse reports it without looking it up
+	Currency *types.Currency `json:"currency,omitempty"`
 }
 
 type transactionWire struct {
@@ -419,6 +605,11 @@ type transactionWire struct {
 	GasFeeCap string `json:"gas_fee_cap,omitempty"`
 	GasLimit  string `json:"gas"`
 	ChainID   string `json:"chain_id"`
+
+	TxType     string              `json:"tx_type,omitempty"`
+	AccessList ethTypes.AccessList `json:"access_list,omitempty"`
+
+	Currency *types.Currency `json:"currency,omitempty"`
 }
 
 func (t *transaction) MarshalJSON() ([]byte, error) {
@@ -431,6 +622,11 @@ func (t *transaction) MarshalJSON() ([]byte, error) {
 		GasPrice: hexutil.EncodeBig(t.GasPrice),
 		GasLimit: hexutil.EncodeUint64(t.GasLimit),
 		ChainID:  hexutil.EncodeBig(t.ChainID),
+
+		TxType:     t.TxType,
+		AccessList: t.AccessList,
+
+		Currency: t.Currency,
 	}
 	if t.GasFeeCap != nil {
 		tw.GasFeeCap = hexutil.EncodeBig(t.GasFeeCap)
@@ -502,5 +698,8 @@ func (t *transaction) UnmarshalJSON(data []byte) error {
 	t.GasTipCap = gasTipCap
 	t.GasLimit = gasLimit
 	t.ChainID = chainID
+	t.TxType = tw.TxType
+	t.AccessList = tw.AccessList
+	t.Currency = tw.Currency
 	return nil
 }
@@ -49,23 +49,49 @@ func unmarshalJSONMap(m map[string]interface{}, i interface{}) error {
 	return json.Unmarshal(b, i)
 }
 
+// AsEthTransaction converts tx to an unsigned Ethereum transaction of its tx_type.
+// Without a tx_type, a dynamic fee transaction is built if both gas caps are set,
+// and a legacy transaction otherwise.
 func AsEthTransaction(tx *transaction) *types.Transaction {
 	var to *common.Address
 	if tx.To != "" {
 		x := common.HexToAddress(tx.To)
 		to = &x
 	}
-	if eip1559Tx := tx.GasTipCap != nil && tx.GasFeeCap != nil; eip1559Tx {
+
+	txType := tx.TxType
+	if txType == "" {
+		txType = LegacyTxType
+		if tx.GasTipCap != nil && tx.GasFeeCap != nil {
+			txType = DynamicFeeTxType
+		}
+	}
+
+	switch txType {
+	case DynamicFeeTxType:
 		return types.NewTx(&types.DynamicFeeTx{
-			Nonce:     tx.Nonce,
-			GasTipCap: tx.GasTipCap,
-			GasFeeCap: tx.GasFeeCap,
-			Gas:       tx.GasLimit,
-			To:        to,
-			Value:     tx.Value,
-			Data:      tx.Data,
+			ChainID:    tx.ChainID,
+			Nonce:      tx.Nonce,
+			GasTipCap:  tx.GasTipCap,
+			GasFeeCap:  tx.GasFeeCap,
+			Gas:        tx.GasLimit,
+			To:         to,
+			Value:      tx.Value,
+			Data:       tx.Data,
+			AccessList: tx.AccessList,
+		})
+	case AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainID,
+			Nonce:      tx.Nonce,
+			GasPrice:   tx.GasPrice,
+			Gas:        tx.GasLimit,
+			To:         to,
+			Value:      tx.Value,
+			Data:       tx.Data,
+			AccessList: tx.AccessList,
 		})
-	} else {
+	default:
 		return types.NewTx(&types.LegacyTx{
 			Nonce:    tx.Nonce,
 			GasPrice: tx.GasPrice,